@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/vectors"
+	"os"
+)
+
+// runAlign выравнивает модель по базовой модели и сохраняет выровненные векторы
+func runAlign(args []string) error {
+	fs := flag.NewFlagSet("align", flag.ExitOnError)
+	baseFile := fs.String("base", "data/vectors.txt.txt", "Базовая модель (в её пространство переносятся векторы)")
+	otherFile := fs.String("other", "", "Выравниваемая модель")
+	anchorsFile := fs.String("anchors", "", "Файл со словами-якорями (по слову на строку); по умолчанию — все общие слова")
+	dictFile := fs.String("dict", "", "Словарь пар 'слово_модели слово_базы' (например, двуязычный)")
+	holdout := fs.Float64("holdout", 0.1, "Доля пар, отложенных для оценки ошибки")
+	center := fs.Bool("center", false, "Центрировать векторы по среднему якорей перед поворотом")
+	output := fs.String("output", "data/aligned_vectors.txt", "Файл для выровненных векторов")
+	reportFile := fs.String("report", "", "Файл для отчёта в формате JSON")
+	fs.Parse(args)
+
+	if *otherFile == "" {
+		return fmt.Errorf("не указана выравниваемая модель (-other)")
+	}
+
+	base, err := vectors.Load(*baseFile)
+	if err != nil {
+		return err
+	}
+	other, err := vectors.Load(*otherFile)
+	if err != nil {
+		return err
+	}
+
+	opts := vectors.AlignOptions{Holdout: *holdout, Center: *center}
+	if *anchorsFile != "" {
+		if opts.Anchors, err = vectors.LoadWords(*anchorsFile); err != nil {
+			return err
+		}
+	}
+	if *dictFile != "" {
+		if opts.Dictionary, err = vectors.LoadDictionary(*dictFile); err != nil {
+			return err
+		}
+	}
+
+	aligned, report, err := vectors.Align(base, other, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Пар для обучения: %d, отложено: %d, не найдено: %d\n", report.Pairs, report.HeldOut, report.Missing)
+	fmt.Printf("Среднее сходство пар: до %.4f, после %.4f (RMSE %.4f)\n", report.CosineBefore, report.CosineAfter, report.RMSE)
+	if report.HeldOut > 0 {
+		fmt.Printf("На отложенных парах: сходство %.4f, RMSE %.4f\n", report.HeldOutCos, report.HeldOutRMSE)
+	}
+
+	if err := aligned.Save(*output); err != nil {
+		return err
+	}
+	fmt.Printf("Выровненные векторы сохранены в %s\n", *output)
+
+	if *reportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*reportFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("ошибка при записи отчёта: %v", err)
+		}
+	}
+	return nil
+}
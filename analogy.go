@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/vectors"
+	"strings"
+)
+
+// runAnalogy решает аналогию вида "москва - россия + франция"
+func runAnalogy(args []string) error {
+	fs := flag.NewFlagSet("analogy", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов")
+	methodFlag := fs.String("method", string(vectors.CosAdd), "Метод оценки: 3cosadd или 3cosmul")
+	topN := fs.Int("top", 10, "Число ответов")
+	fs.Parse(args)
+
+	expr := strings.ToLower(strings.Join(fs.Args(), " "))
+	if expr == "" {
+		return fmt.Errorf("не задано выражение, например: москва - россия + франция")
+	}
+	method, err := vectors.ParseAnalogyMethod(*methodFlag)
+	if err != nil {
+		return err
+	}
+	positive, negative, err := vectors.ParseAnalogy(expr)
+	if err != nil {
+		return err
+	}
+
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+	answers, err := model.Analogy(positive, negative, method, *topN)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (%s):\n", expr, method)
+	for i, answer := range answers {
+		fmt.Printf("%d. %s (%.4f)\n", i+1, answer.Word, answer.Similarity)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/classify"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/Jeffail/tunny"
+)
+
+// classifyBatch — сколько документов читается и классифицируется за раз;
+// результаты пакета записываются в порядке входного файла
+const classifyBatch = 4096
+
+// classified — результат классификации одного документа
+type classified struct {
+	prediction classify.Prediction
+	stats      docvec.Stats
+	ok         bool
+}
+
+// runClassify размечает все документы CSV или JSONL обученным классификатором
+func runClassify(args []string) error {
+	fs := flag.NewFlagSet("classify", flag.ExitOnError)
+	input := fs.String("input", "data/input.csv", "Документы: CSV (текст очищается) или корпус JSONL/текст")
+	classifierFile := fs.String("classifier", "data/classifier.json", "Файл классификатора (команда train)")
+	vectorsFile := fs.String("vectors", "", "Файл векторов (по умолчанию — тот, на котором обучен классификатор)")
+	output := fs.String("output", "data/predictions.csv", "CSV с предсказаниями")
+	minCoverage := fs.Float64("min-coverage", 0.5, "Минимальная доля слов документа, найденных в векторах")
+	probabilities := fs.Bool("probabilities", false, "Добавить столбцы с вероятностями всех классов")
+	workers := fs.Int("workers", runtime.NumCPU(), "Число параллельных обработчиков")
+	textColumn := fs.String("text-column", "", "Столбец CSV с текстом (по умолчанию определяется по заголовку)")
+	idColumn := fs.String("id-column", "", "Столбец CSV с идентификатором (по умолчанию определяется по заголовку)")
+	fs.Parse(args)
+
+	var model *vectors.Model
+	if *vectorsFile != "" {
+		var err error
+		if model, err = vectors.Load(*vectorsFile); err != nil {
+			return err
+		}
+	}
+	clf, err := classify.Load(*classifierFile, model)
+	if err != nil {
+		return err
+	}
+	reader, err := textprocessor.OpenDocuments(*input, textprocessor.Columns{Text: *textColumn, ID: *idColumn})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	header := []string{"id", "label", "probability", "coverage", "oov"}
+	if *probabilities {
+		for _, label := range clf.Labels {
+			header = append(header, "p_"+label)
+		}
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+
+	pool := tunny.NewFunc(max(*workers, 1), func(payload interface{}) interface{} {
+		prediction, stats, ok := clf.Classify(payload.(string))
+		return classified{prediction: prediction, stats: stats, ok: ok}
+	})
+	defer pool.Close()
+
+	var total, written int
+	var coverage docvec.Summary
+	counts := make(map[string]int)
+	batch := make([]corpus.Document, 0, classifyBatch)
+	results := make([]classified, classifyBatch)
+
+	// flush классифицирует накопленный пакет в пуле и записывает его по порядку.
+	// Документы раздаются по каналу стольким горутинам, сколько обработчиков в пуле.
+	flush := func() error {
+		jobs := make(chan int, len(batch))
+		for i := range batch {
+			jobs <- i
+		}
+		close(jobs)
+		var wg sync.WaitGroup
+		for w := 0; w < pool.GetSize(); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					results[i] = pool.Process(batch[i].Text).(classified)
+				}
+			}()
+		}
+		wg.Wait()
+
+		for i, doc := range batch {
+			r := results[i]
+			coverage.Add(r.stats)
+			// Документ без слов из векторов или с малым покрытием не классифицируется:
+			// предсказание по нулевому или случайному вектору ничего не значит
+			if !r.ok || r.stats.Coverage() < *minCoverage {
+				continue
+			}
+			row := []string{
+				doc.ID,
+				r.prediction.Label,
+				strconv.FormatFloat(r.prediction.Probability, 'f', 4, 64),
+				strconv.FormatFloat(r.stats.Coverage(), 'f', 4, 64),
+				strconv.Itoa(r.stats.OOV()),
+			}
+			if *probabilities {
+				for _, label := range clf.Labels {
+					row = append(row, strconv.FormatFloat(r.prediction.Probabilities[label], 'f', 4, 64))
+				}
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("ошибка при записи в файл: %v", err)
+			}
+			counts[r.prediction.Label]++
+			written++
+		}
+		total += len(batch)
+		batch = batch[:0]
+		log.Printf("Обработано документов: %d", total)
+		return nil
+	}
+
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		batch = append(batch, doc)
+		if len(batch) == classifyBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+
+	fmt.Printf("Документов: %s\n", coverage.String())
+	fmt.Printf("Размечено: %d, пропущено (покрытие ниже %.0f%% или нет слов из векторов): %d\n",
+		written, 100**minCoverage, total-written)
+	for _, label := range clf.Labels {
+		fmt.Printf("  %s: %d\n", label, counts[label])
+	}
+	fmt.Printf("Предсказания сохранены в %s\n", *output)
+	return nil
+}
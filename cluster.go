@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/cluster"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// clusterItems — кластеризуемые объекты (слова или документы) и их векторы
+type clusterItems struct {
+	IDs     []string
+	Texts   []string
+	Vectors [][]float64
+}
+
+// clusterLevel — одно разбиение объектов (уровень разреза дерева)
+type clusterLevel struct {
+	Name   string         `json:"name"`
+	Scores cluster.Scores `json:"scores"`
+	Sizes  []int          `json:"sizes"`
+	Noise  int            `json:"noise,omitempty"`
+	labels []int
+}
+
+// runCluster кластеризует слова или документы корпуса (k-means, иерархическая
+// кластеризация или HDBSCAN) либо подбирает число кластеров
+func runCluster(args []string) error {
+	fs := flag.NewFlagSet("cluster", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов")
+	corpusFile := fs.String("corpus", "", "Корпус (текст или JSONL): кластеризуются документы; без него — слова модели")
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов для взвешивания векторов документов")
+	weightingName := fs.String("weighting", "sif", "Взвешивание слов в векторах документов: mean, tfidf или sif")
+	removePC := fs.Bool("remove-pc", true, "Вычитать из векторов документов первую главную компоненту корпуса")
+	limit := fs.Int("limit", 20000, "Сколько самых частых слов кластеризовать (без -corpus; для agglomerative по умолчанию 5000)")
+	method := fs.String("method", "kmeans", "Метод: kmeans, agglomerative или hdbscan")
+	k := fs.Int("k", 10, "Число кластеров")
+	sweep := fs.String("sweep", "", "Диапазон k для подбора числа кластеров, например 2:20 (только kmeans)")
+	metricName := fs.String("metric", "cosine", "Мера расстояния для kmeans: cosine или euclidean")
+	nInit := fs.Int("n-init", 3, "Число перезапусков k-means")
+	seed := fs.Int64("seed", 1, "Зерно генератора случайных чисел")
+	batch := fs.Int("batch", 0, "Размер мини-пакета (0 — обычный k-means)")
+	linkageName := fs.String("linkage", "average", "Способ связи для agglomerative: average, complete или ward")
+	cuts := fs.String("cuts", "", "Числа кластеров для разрезов дерева через запятую, например 5,10,20 (по умолчанию -k)")
+	cutDistance := fs.Float64("cut-distance", 0, "Дополнительный разрез дерева на заданной высоте (0 — нет)")
+	minClusterSize := fs.Int("min-cluster-size", 5, "Минимальный размер кластера HDBSCAN")
+	minSamples := fs.Int("min-samples", 0, "Число соседей для оценки плотности в HDBSCAN, включая сам объект (0 — равно -min-cluster-size)")
+	sample := fs.Int("sample", 5000, "Размер подвыборки для силуэта (0 — все точки)")
+	output := fs.String("output", "data/clusters", "Префикс выходных файлов")
+	topWords := fs.Int("top-words", 10, "Число ближайших к центроиду слов в описании кластера")
+	topTerms := fs.Int("top-terms", 10, "Число характерных терминов (c-TF-IDF) в описании кластера")
+	topDocs := fs.Int("top-docs", 5, "Число документов, ближайших к центроиду, в описании кластера")
+	stopwordsFile := fs.String("stopwords", "", "Файл стоп-слов, которые не считаются характерными терминами")
+	maxMemory := fs.Int64("max-memory", 2048, "Предельный объём матрицы расстояний agglomerative в МБ")
+	fs.Parse(args)
+
+	// Матрица расстояний agglomerative растёт как n², поэтому без явного -limit берётся меньше слов
+	limitSet := false
+	fs.Visit(func(f *flag.Flag) { limitSet = limitSet || f.Name == "limit" })
+	if *method == "agglomerative" && !limitSet {
+		*limit = 5000
+	}
+
+	metric, err := cluster.ParseMetric(*metricName)
+	if err != nil {
+		return err
+	}
+	if *method != "kmeans" {
+		// Иерархические методы работают с косинусным расстоянием
+		metric = cluster.Cosine
+	}
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+	var embedder *docvec.Embedder
+	if *corpusFile != "" {
+		if embedder, err = newEmbedder(model, *vocabFile, *weightingName); err != nil {
+			return err
+		}
+	}
+	items, err := loadClusterItems(model, embedder, *corpusFile, *limit, *removePC)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Объектов для кластеризации: %d\n", len(items.Vectors))
+	if need := cluster.AgglomerativeMemory(len(items.Vectors)); *method == "agglomerative" && need > *maxMemory<<20 {
+		return fmt.Errorf("матрица расстояний для %d объектов займёт %d МБ (предел -max-memory %d МБ): уменьшите -limit или используйте kmeans/hdbscan",
+			len(items.Vectors), need>>20, *maxMemory)
+	}
+
+	var levels []*clusterLevel
+	var centroids [][]float64
+	var inertia float64
+	switch *method {
+	case "kmeans":
+		opts := cluster.DefaultKMeansOptions(*k)
+		opts.Metric = metric
+		opts.NInit = *nInit
+		opts.Seed = *seed
+		opts.BatchSize = *batch
+
+		if *sweep != "" {
+			kMin, kMax, err := parseRange(*sweep)
+			if err != nil {
+				return err
+			}
+			result, err := cluster.SweepK(items.Vectors, kMin, kMax, opts, *sample)
+			if err != nil {
+				return err
+			}
+			result.Print(os.Stdout)
+			filename := *output + "_sweep.json"
+			if err := result.SaveJSON(filename); err != nil {
+				return err
+			}
+			fmt.Printf("Результаты подбора сохранены в %s\n", filename)
+			return nil
+		}
+
+		result, err := cluster.KMeans(items.Vectors, opts)
+		if err != nil {
+			return err
+		}
+		levels = append(levels, &clusterLevel{Name: "k=" + strconv.Itoa(*k), labels: result.Labels})
+		centroids, inertia = result.Centroids, result.Inertia
+
+	case "agglomerative":
+		linkage, err := cluster.ParseLinkage(*linkageName)
+		if err != nil {
+			return err
+		}
+		tree, err := cluster.Agglomerative(items.Vectors, linkage)
+		if err != nil {
+			return err
+		}
+		ks := []int{*k}
+		if *cuts != "" {
+			ks = nil
+			for _, value := range parseList(*cuts) {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return fmt.Errorf("некорректное число кластеров в -cuts: %q", value)
+				}
+				ks = append(ks, n)
+			}
+		}
+		for _, n := range ks {
+			levels = append(levels, &clusterLevel{Name: "k=" + strconv.Itoa(n), labels: tree.CutK(n)})
+		}
+		if *cutDistance > 0 {
+			levels = append(levels, &clusterLevel{
+				Name:   "h=" + strconv.FormatFloat(*cutDistance, 'g', -1, 64),
+				labels: tree.CutDistance(*cutDistance),
+			})
+		}
+		if err := saveTree(*output, tree.Newick(items.IDs), tree.Tree(items.IDs)); err != nil {
+			return err
+		}
+
+	case "hdbscan":
+		result, err := cluster.HDBSCAN(items.Vectors, cluster.HDBSCANOptions{MinClusterSize: *minClusterSize, MinSamples: *minSamples})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("HDBSCAN: кластеров %d, шум %d\n", result.Count(), result.Noise)
+		levels = append(levels, &clusterLevel{Name: "hdbscan", labels: result.Labels})
+		tree := result.CondensedTree(items.IDs)
+		if err := saveTree(*output, tree.Newick(), tree); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("неизвестный метод кластеризации: %s (kmeans, agglomerative, hdbscan)", *method)
+	}
+
+	for _, level := range levels {
+		level.Scores = cluster.Evaluate(items.Vectors, level.labels, metric, *sample, *seed)
+		level.Sizes = make([]int, level.Scores.K)
+		for _, l := range level.labels {
+			if l == cluster.Noise {
+				level.Noise++
+			} else {
+				level.Sizes[l]++
+			}
+		}
+	}
+	primary := levels[0]
+	if inertia > 0 {
+		primary.Scores.Inertia = inertia
+	}
+	for _, level := range levels {
+		fmt.Printf("%s: инерция %.4f, силуэт %.4f, Дэвис-Болдин %.4f, Калински-Харабаз %.4f\n", level.Name,
+			level.Scores.Inertia, level.Scores.Silhouette, level.Scores.DaviesBouldin, level.Scores.CalinskiHarabasz)
+	}
+
+	summary := struct {
+		Method string          `json:"method"`
+		Metric cluster.Metric  `json:"metric"`
+		Scores cluster.Scores  `json:"scores"`
+		Sizes  []int           `json:"sizes"`
+		Noise  int             `json:"noise,omitempty"`
+		Levels []*clusterLevel `json:"levels,omitempty"`
+	}{Method: *method, Metric: metric, Scores: primary.Scores, Sizes: primary.Sizes, Noise: primary.Noise}
+	if len(levels) > 1 {
+		summary.Levels = levels
+	}
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output+".json", append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи результатов: %v", err)
+	}
+	columns := make([][]int, len(levels))
+	for i, level := range levels {
+		columns[i] = level.labels
+	}
+	if err := saveAssignments(*output+".tsv", items.IDs, columns); err != nil {
+		return err
+	}
+	fmt.Printf("Оценки сохранены в %s.json, кластеры объектов — в %s.tsv\n", *output, *output)
+
+	if centroids == nil {
+		centroids = cluster.Centroids(items.Vectors, primary.labels)
+	}
+	describeOpts := cluster.DefaultDescribeOptions()
+	describeOpts.TopWords = *topWords
+	describeOpts.TopTerms = *topTerms
+	describeOpts.TopDocs = *topDocs
+	if *stopwordsFile != "" {
+		if describeOpts.StopWords, err = ngrams.LoadStopwords(*stopwordsFile); err != nil {
+			return err
+		}
+	}
+	descriptions := cluster.Describe(model, items.IDs, items.Texts, items.Vectors, primary.labels, centroids, describeOpts)
+	for _, d := range descriptions {
+		words := make([]string, 0, 5)
+		for _, n := range d.Words[:min(5, len(d.Words))] {
+			words = append(words, n.Word)
+		}
+		fmt.Printf("Кластер %d (%d): %s\n", d.Cluster, d.Size, strings.Join(words, ", "))
+	}
+	for _, filename := range []string{*output + "_labels.md", *output + "_labels.json"} {
+		if err := cluster.SaveDescriptions(filename, descriptions); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Описания кластеров сохранены в %s_labels.md и %s_labels.json\n", *output, *output)
+	return nil
+}
+
+// newEmbedder создаёт построитель векторов документов; без словаря частот
+// векторы документов — простые средние векторов слов
+func newEmbedder(model *vectors.Model, vocabFile, weightingName string) (*docvec.Embedder, error) {
+	opts := docvec.DefaultOptions()
+	weighting, err := docvec.ParseWeighting(weightingName)
+	if err != nil {
+		return nil, err
+	}
+	opts.Weighting = weighting
+	embedder, err := docvec.Load(model, vocabFile, opts)
+	if err != nil {
+		log.Printf("Частоты слов не загружены (%v), используется простое среднее", err)
+		return docvec.New(model, nil, opts), nil
+	}
+	return embedder, nil
+}
+
+// saveTree сохраняет дерево кластеров в форматах Newick (_tree.nwk) и JSON (_tree.json)
+func saveTree(prefix, newick string, tree *cluster.TreeNode) error {
+	if err := os.WriteFile(prefix+"_tree.nwk", []byte(newick), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи дерева: %v", err)
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(prefix+"_tree.json", append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи дерева: %v", err)
+	}
+	fmt.Printf("Дерево кластеров сохранено в %s_tree.nwk и %s_tree.json\n", prefix, prefix)
+	return nil
+}
+
+// loadClusterItems возвращает документы корпуса (взвешенные средние векторы их слов)
+// или самые частые слова модели
+func loadClusterItems(model *vectors.Model, embedder *docvec.Embedder, corpusFile string, limit int, removePC bool) (*clusterItems, error) {
+	items := &clusterItems{}
+	if corpusFile == "" {
+		for i, word := range model.Words {
+			if limit > 0 && i >= limit {
+				break
+			}
+			items.IDs = append(items.IDs, word)
+			items.Texts = append(items.Texts, word)
+			items.Vectors = append(items.Vectors, model.Vectors[i])
+		}
+		return items, nil
+	}
+
+	docs, err := corpus.ReadAll(corpusFile)
+	if err != nil {
+		return nil, err
+	}
+	if removePC {
+		texts := make([][]string, len(docs))
+		for i, doc := range docs {
+			texts[i] = strings.Fields(doc.Text)
+		}
+		if err := embedder.Fit(texts); err != nil {
+			return nil, err
+		}
+	}
+	var summary docvec.Summary
+	for i, doc := range docs {
+		vec, stats := embedder.EmbedText(doc.Text)
+		summary.Add(stats)
+		if vec == nil {
+			continue
+		}
+		id := doc.ID
+		if id == "" {
+			id = strconv.Itoa(i + 1)
+		}
+		items.IDs = append(items.IDs, id)
+		items.Texts = append(items.Texts, doc.Text)
+		items.Vectors = append(items.Vectors, vec)
+	}
+	fmt.Printf("Документы: %s\n", summary.String())
+	if len(items.Vectors) == 0 {
+		return nil, fmt.Errorf("в корпусе нет документов со словами из векторов")
+	}
+	return items, nil
+}
+
+// saveAssignments сохраняет кластеры каждого объекта: "объект<TAB>кластер[<TAB>кластер...]",
+// по столбцу на разбиение; шум обозначается −1
+func saveAssignments(filename string, ids []string, columns [][]int) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i, id := range ids {
+		writer.WriteString(id)
+		for _, labels := range columns {
+			fmt.Fprintf(writer, "\t%d", labels[i])
+		}
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// parseRange разбирает диапазон вида "2:20"
+func parseRange(value string) (int, int, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("диапазон должен иметь вид от:до, получено %q", value)
+	}
+	from, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	to, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil || from > to {
+		return 0, 0, fmt.Errorf("некорректный диапазон %q", value)
+	}
+	return from, to, nil
+}
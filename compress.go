@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/evaluate"
+	"glove-pipeline/pkg/reduce"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// compressionLevel — одна степень сжатия: размерность, точность и оценки качества
+type compressionLevel struct {
+	Dim        int              `json:"dim"`
+	Precision  string           `json:"precision"`
+	Bytes      int64            `json:"bytes"`                        // Размер файла
+	Memory     int64            `json:"memory"`                       // Память под векторы float64 после загрузки
+	Ratio      float64          `json:"ratio"`                        // Во сколько раз файл меньше исходного
+	Explained  *float64         `json:"explained_variance,omitempty"` // Доля сохранённой дисперсии (PCA)
+	Evaluation *evaluate.Report `json:"evaluation"`
+}
+
+// compressionReport — влияние каждой степени сжатия на оценки векторов
+type compressionReport struct {
+	Vectors string             `json:"vectors"`
+	Reduce  string             `json:"reduce"`
+	ABTT    int                `json:"abtt"`
+	Levels  []compressionLevel `json:"levels"` // Первый уровень — исходные векторы
+}
+
+// runCompress понижает размерность векторов и сохраняет их в компактном двоичном формате
+func runCompress(args []string) error {
+	fs := flag.NewFlagSet("compress", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Исходный файл векторов")
+	output := fs.String("output", "data/vectors.bin", "Файл сжатых векторов")
+	reduceName := fs.String("reduce", "pca", "Понижение размерности: pca, random или none")
+	dim := fs.Int("dim", 50, "Размерность после понижения (0 — исходная)")
+	abtt := fs.Int("abtt", 0, "Сколько доминирующих направлений удалить (all-but-the-top; 0 — не удалять)")
+	fitLimit := fs.Int("fit-limit", 100000, "По скольким самым частым словам искать главные компоненты (0 — по всем)")
+	precisionName := fs.String("precision", string(vectors.Float16), "Точность хранения: float32, float16, int8 или text (текстовый формат GloVe)")
+	seed := fs.Int64("seed", 1, "Зерно генератора для случайной проекции")
+	similarity := fs.String("similarity", "", "Наборы пар слов для отчёта о потерях качества через запятую")
+	analogies := fs.String("analogies", "", "Файлы вопросов-аналогий для отчёта о потерях качества через запятую")
+	methodFlag := fs.String("method", string(vectors.CosAdd), "Метод аналогий: 3cosadd или 3cosmul")
+	restrict := fs.Int("restrict", 30000, "Искать ответы на аналогии среди первых N слов модели (0 — среди всех)")
+	levelsFlag := fs.String("levels", "", "Степени сжатия для отчёта в виде размерность/точность через запятую, например 100/float32,50/float16,25/int8")
+	reportFile := fs.String("report", "data/compression.json", "Файл отчёта о потерях качества")
+	fs.Parse(args)
+
+	if *reduceName != "pca" && *reduceName != "random" && *reduceName != "none" {
+		return fmt.Errorf("неизвестный способ понижения размерности: %q (pca, random, none)", *reduceName)
+	}
+	method, err := vectors.ParseAnalogyMethod(*methodFlag)
+	if err != nil {
+		return err
+	}
+
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+	log.Printf("Загружено %d слов, размерность %d", model.Len(), model.Dim())
+
+	// Понижение размерности выполняется один раз для каждой размерности
+	reduced := make(map[int]*vectors.Model)
+	explained := make(map[int]float64)
+	reduceTo := func(k int) (*vectors.Model, error) {
+		if k <= 0 || k > model.Dim() {
+			k = model.Dim()
+		}
+		if m, ok := reduced[k]; ok {
+			return m, nil
+		}
+		m := model
+		switch {
+		case *reduceName == "pca":
+			var pca *reduce.PCA
+			if m, pca, err = reduce.ReducePCA(model, k, *abtt, *fitLimit); err != nil {
+				return nil, err
+			}
+			explained[k] = pca.Explained(k)
+		case *abtt > 0:
+			if m, err = reduce.AllButTheTop(model, *abtt, *fitLimit); err != nil {
+				return nil, err
+			}
+		}
+		if *reduceName == "random" && k < model.Dim() {
+			m = reduce.RandomProjection(m, k, *seed)
+		}
+		reduced[k] = m
+		return m, nil
+	}
+
+	compressed, err := reduceTo(*dim)
+	if err != nil {
+		return err
+	}
+	if *precisionName == "text" {
+		err = compressed.Save(*output)
+	} else {
+		var precision vectors.Precision
+		if precision, err = vectors.ParsePrecision(*precisionName); err != nil {
+			return err
+		}
+		err = compressed.SaveBinary(*output, precision)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Векторы размерности %d (%s) сохранены в %s\n", compressed.Dim(), *precisionName, *output)
+	fmt.Printf("После загрузки векторы занимают %.1f МБ: значения раскодируются в float64, сжатие уменьшает размер файла, а память — только за счёт размерности\n",
+		float64(int64(compressed.Len())*int64(compressed.Dim())*8)/(1<<20))
+	if share, ok := explained[compressed.Dim()]; ok {
+		fmt.Printf("Сохранено дисперсии: %.1f%%\n", 100*share)
+	}
+
+	if *similarity == "" && *analogies == "" {
+		return nil
+	}
+	levels := parseList(*levelsFlag)
+	if len(levels) == 0 {
+		precision := *precisionName
+		if precision == "text" {
+			precision = string(vectors.Float32)
+		}
+		levels = []string{fmt.Sprintf("%d/%s", compressed.Dim(), precision)}
+	}
+	sourceSize := int64(0)
+	if info, err := os.Stat(*vectorsFile); err == nil {
+		sourceSize = info.Size()
+	}
+
+	evaluateLevel := func(m *vectors.Model, name string) (*evaluate.Report, error) {
+		return evaluateModel(m, name, parseList(*similarity), parseList(*analogies), method, *restrict)
+	}
+	original, err := evaluateLevel(model, *vectorsFile)
+	if err != nil {
+		return err
+	}
+	report := compressionReport{Vectors: *vectorsFile, Reduce: *reduceName, ABTT: *abtt}
+	report.Levels = append(report.Levels, compressionLevel{Dim: model.Dim(), Precision: "original", Bytes: sourceSize,
+		Memory: int64(model.Len()) * int64(model.Dim()) * 8, Ratio: 1, Evaluation: original})
+
+	for _, level := range levels {
+		k, precision, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		m, err := reduceTo(k)
+		if err != nil {
+			return err
+		}
+		entry := compressionLevel{Dim: m.Dim(), Precision: string(precision), Bytes: m.BinarySize(precision),
+			Memory: int64(m.Len()) * int64(m.Dim()) * 8}
+		if sourceSize > 0 {
+			entry.Ratio = float64(sourceSize) / float64(entry.Bytes)
+		}
+		if share, ok := explained[m.Dim()]; ok {
+			entry.Explained = &share
+		}
+		log.Printf("Оценка уровня %d/%s...", m.Dim(), precision)
+		if entry.Evaluation, err = evaluateLevel(m.Quantized(precision), fmt.Sprintf("%d/%s", m.Dim(), precision)); err != nil {
+			return err
+		}
+		report.Levels = append(report.Levels, entry)
+	}
+
+	fmt.Println()
+	printCompression(os.Stdout, report)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*reportFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи отчёта: %v", err)
+	}
+	fmt.Printf("\nОтчёт сохранен в %s\n", *reportFile)
+	return nil
+}
+
+// parseLevel разбирает степень сжатия вида «50/int8»
+func parseLevel(level string) (int, vectors.Precision, error) {
+	dimText, precisionText, _ := strings.Cut(level, "/")
+	k, err := strconv.Atoi(strings.TrimSpace(dimText))
+	if err != nil || k < 0 {
+		return 0, "", fmt.Errorf("неверная степень сжатия %q: ожидается размерность/точность, например 50/int8", level)
+	}
+	precision, err := vectors.ParsePrecision(precisionText)
+	if err != nil {
+		return 0, "", err
+	}
+	return k, precision, nil
+}
+
+// printCompression выводит таблицу: размер каждой степени сжатия и её оценки
+// с изменением относительно исходных векторов
+func printCompression(w io.Writer, r compressionReport) {
+	original := r.Levels[0].Evaluation
+	fmt.Fprintf(w, "%-18s %10s %8s %10s", "Уровень", "Размер, МБ", "Сжатие", "Дисперсия")
+	for _, s := range original.Similarity {
+		fmt.Fprintf(w, " %20.20s", s.Dataset)
+	}
+	for _, a := range original.Analogies {
+		fmt.Fprintf(w, " %20.20s", a.Dataset)
+	}
+	fmt.Fprintln(w)
+
+	for _, level := range r.Levels {
+		explained := "—"
+		if level.Explained != nil {
+			explained = fmt.Sprintf("%.1f%%", 100*(*level.Explained))
+		}
+		fmt.Fprintf(w, "%-18s %10.1f %7.1f× %10s", fmt.Sprintf("%d/%s", level.Dim, level.Precision),
+			float64(level.Bytes)/(1<<20), level.Ratio, explained)
+		for i, s := range level.Evaluation.Similarity {
+			fmt.Fprintf(w, " %20s", fmt.Sprintf("%.4f (%+.4f)", s.Spearman, s.Spearman-original.Similarity[i].Spearman))
+		}
+		for i, a := range level.Evaluation.Analogies {
+			fmt.Fprintf(w, " %20s", fmt.Sprintf("%.4f (%+.4f)", a.Total.Accuracy, a.Total.Accuracy-original.Analogies[i].Total.Accuracy))
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "\nДля пар слов — коэффициент Спирмена, для аналогий — точность; в скобках — изменение относительно исходных векторов.")
+}
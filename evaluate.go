@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/evaluate"
+	"glove-pipeline/pkg/vectors"
+	"os"
+	"time"
+)
+
+// runEvaluate оценивает векторы на наборах пар слов и вопросах-аналогиях
+func runEvaluate(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов")
+	similarity := fs.String("similarity", "", "Наборы пар слов (SimLex/WordSim TSV) через запятую")
+	analogies := fs.String("analogies", "", "Файлы вопросов-аналогий (формат word2vec) через запятую")
+	methodFlag := fs.String("method", string(vectors.CosAdd), "Метод аналогий: 3cosadd или 3cosmul")
+	restrict := fs.Int("restrict", 30000, "Искать ответы на аналогии среди первых N слов модели (0 — среди всех)")
+	output := fs.String("output", "data/evaluation.json", "Файл отчёта в формате JSON")
+	compare := fs.String("compare", "", "Предыдущий отчёт для сравнения метрик")
+	fs.Parse(args)
+
+	if *similarity == "" && *analogies == "" {
+		return fmt.Errorf("не заданы наборы данных (-similarity и/или -analogies)")
+	}
+	method, err := vectors.ParseAnalogyMethod(*methodFlag)
+	if err != nil {
+		return err
+	}
+
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+	report, err := evaluateModel(model, *vectorsFile, parseList(*similarity), parseList(*analogies), method, *restrict)
+	if err != nil {
+		return err
+	}
+
+	var previous *evaluate.Report
+	if *compare != "" {
+		if previous, err = evaluate.LoadReport(*compare); err != nil {
+			return err
+		}
+	}
+	report.Print(os.Stdout, previous)
+
+	if err := report.SaveJSON(*output); err != nil {
+		return err
+	}
+	fmt.Printf("\nОтчёт сохранен в %s\n", *output)
+	return nil
+}
+
+// evaluateModel оценивает модель на всех заданных наборах данных
+func evaluateModel(model *vectors.Model, name string, similarityFiles, analogyFiles []string, method vectors.AnalogyMethod, restrict int) (*evaluate.Report, error) {
+	report := &evaluate.Report{
+		Vectors:   name,
+		Words:     model.Len(),
+		Dim:       model.Dim(),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	}
+	for _, file := range similarityFiles {
+		pairs, err := evaluate.LoadSimilarityDataset(file)
+		if err != nil {
+			return nil, err
+		}
+		report.Similarity = append(report.Similarity, evaluate.EvaluateSimilarity(model, evaluate.DatasetName(file), pairs))
+	}
+	for _, file := range analogyFiles {
+		sections, err := evaluate.LoadAnalogyQuestions(file)
+		if err != nil {
+			return nil, err
+		}
+		report.Analogies = append(report.Analogies, evaluate.EvaluateAnalogies(model, evaluate.DatasetName(file), sections, method, restrict))
+	}
+	return report, nil
+}
@@ -3,203 +3,54 @@ package main
 import (
 	"bufio"
 	"fmt"
-	"math"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/spell"
+	"glove-pipeline/pkg/subword"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
 	"os"
-	"sort"
-	"strconv"
 	"strings"
-	"unicode"
 )
 
-// WordVector представляет вектор слова.
-type WordVector struct {
-	Word   string
-	Vector []float64
-}
-
-// LoadVectors загружает векторы из файла.
-func LoadVectors(filename string) (map[string][]float64, error) {
-	file, err := os.Open(filename)
+func main() {
+	// Загрузка векторов
+	model, err := vectors.Load("../../data/vectors.txt.txt")
 	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	vectors := make(map[string][]float64)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		parts := strings.Fields(scanner.Text())
-		if len(parts) < 2 {
-			continue
-		}
-		word := parts[0]
-		vector := make([]float64, len(parts)-1)
-		for i := 1; i < len(parts); i++ {
-			val, err := strconv.ParseFloat(parts[i], 64)
-			if err != nil {
-				return nil, err
-			}
-			vector[i-1] = val
-		}
-		vectors[word] = vector
+		fmt.Println("Ошибка загрузки векторов:", err)
+		return
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if model.Len() == 0 {
+		fmt.Println("Векторы не загружены или файл пуст.")
+		return
 	}
 
-	return vectors, nil
-}
-
-// LoadStopWords загружает стоп-слова из файла.
-func LoadStopWords(filename string) (map[string]struct{}, error) {
-	file, err := os.Open(filename)
+	// Словарь исправлений: опечатки, пропущенная «ё» и текст, набранный не в той раскладке
+	checker, err := spell.Load("../../data/vocab.txt", spell.DefaultOptions())
 	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	stopWords := make(map[string]struct{})
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		word := strings.TrimSpace(scanner.Text())
-		if word != "" {
-			stopWords[word] = struct{}{}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		fmt.Println("Словарь исправлений не загружен:", err)
+	} else {
+		checker.Attach(model)
 	}
 
-	return stopWords, nil
-}
-
-// RemovePunctuation удаляет знаки пунктуации из текста.
-func RemovePunctuation(text string) string {
-	return strings.Map(func(r rune) rune {
-		if unicode.IsPunct(r) {
-			return -1
+	// Модель n-грамм (go run . subword) строит векторы для слов вне словаря: опечаток и редких словоформ
+	if sw, err := subword.Load("../../data/subword.bin"); err == nil {
+		if err := sw.Attach(model); err != nil {
+			fmt.Println("Модель n-грамм не подключена:", err)
 		}
-		return r
-	}, text)
-}
-
-// RemoveStopWords удаляет стоп-слова из текста.
-func RemoveStopWords(text string, stopWords map[string]struct{}) string {
-	words := strings.Fields(text)
-	var filteredWords []string
-	for _, word := range words {
-		if _, isStopWord := stopWords[word]; !isStopWord {
-			filteredWords = append(filteredWords, word)
-		}
-	}
-	return strings.Join(filteredWords, " ")
-}
-
-// TextToVector преобразует текст в средний вектор слов.
-func TextToVector(text string, vectors map[string][]float64) []float64 {
-	words := strings.Fields(text)
-	if len(words) == 0 || len(vectors) == 0 {
-		return nil // Возвращаем nil, если текст пуст или векторы не загружены
-	}
-
-	// Определяем длину вектора на основе первого слова в vectors
-	var vectorLength int
-	for _, vec := range vectors {
-		vectorLength = len(vec)
-		break
-	}
-
-	sumVector := make([]float64, vectorLength)
-	count := 0
-
-	for _, word := range words {
-		if vec, ok := vectors[word]; ok {
-			for i := range vec {
-				sumVector[i] += vec[i]
-			}
-			count++
-		}
-	}
-
-	if count > 0 {
-		for i := range sumVector {
-			sumVector[i] /= float64(count)
-		}
-	}
-
-	return sumVector
-}
-
-// CosineSimilarity вычисляет косинусное сходство между двумя векторами.
-func CosineSimilarity(vec1, vec2 []float64) (float64, error) {
-	if len(vec1) != len(vec2) {
-		return 0, fmt.Errorf("векторы должны быть одинаковой длины")
-	}
-
-	var dotProduct, magnitude1, magnitude2 float64
-	for i := 0; i < len(vec1); i++ {
-		dotProduct += vec1[i] * vec2[i]
-		magnitude1 += vec1[i] * vec1[i]
-		magnitude2 += vec2[i] * vec2[i]
-	}
-
-	magnitude1 = math.Sqrt(magnitude1)
-	magnitude2 = math.Sqrt(magnitude2)
-
-	if magnitude1 == 0 || magnitude2 == 0 {
-		return 0, fmt.Errorf("один из векторов имеет нулевую длину")
 	}
 
-	return dotProduct / (magnitude1 * magnitude2), nil
-}
-
-// FindSynonyms находит синонимы для заданного вектора.
-func FindSynonyms(targetVector []float64, vectors map[string][]float64, topN int) ([]string, error) {
-	type Similarity struct {
-		Word       string
-		Similarity float64
-	}
-
-	var similarities []Similarity
-	for word, vec := range vectors {
-		sim, err := CosineSimilarity(targetVector, vec)
-		if err != nil {
-			return nil, err
-		}
-		similarities = append(similarities, Similarity{Word: word, Similarity: sim})
-	}
-
-	// Сортировка по убыванию сходства
-	sort.Slice(similarities, func(i, j int) bool {
-		return similarities[i].Similarity > similarities[j].Similarity
-	})
-
-	// Выбор topN синонимов
-	var synonyms []string
-	for i := 0; i < topN && i < len(similarities); i++ {
-		synonyms = append(synonyms, similarities[i].Word)
-	}
-
-	return synonyms, nil
-}
-
-func main() {
-	// Загрузка векторов
-	vectors, err := LoadVectors("../../data/vectors.txt.txt")
+	// Векторы фраз — средние векторов слов, взвешенные по SIF: частые слова весят меньше
+	counts, err := glove.VocabCounts("../../data/vocab.txt")
 	if err != nil {
-		fmt.Println("Ошибка загрузки векторов:", err)
-		return
-	}
-
-	if len(vectors) == 0 {
-		fmt.Println("Векторы не загружены или файл пуст.")
-		return
+		fmt.Println("Частоты слов не загружены, используется простое среднее:", err)
 	}
+	embedder := docvec.New(model, counts, docvec.DefaultOptions())
 
 	// Загрузка стоп-слов
-	stopWords, err := LoadStopWords("../../data/stopwords.txt")
+	stopWords, err := ngrams.LoadStopwords("../../data/stopwords.txt")
 	if err != nil {
 		fmt.Println("Ошибка загрузки стоп-слов:", err)
 		return
@@ -207,24 +58,37 @@ func main() {
 
 	// Проверка аргументов командной строки
 	if len(os.Args) < 2 {
-		fmt.Println("Использование: программа <режим>")
-		fmt.Println("Режимы: word (поиск по слову), phrase (поиск по фразе)")
+		fmt.Println("Использование: программа <режим> [метод аналогий]")
+		fmt.Println("Режимы: word (поиск по слову), phrase (поиск по фразе), analogy (аналогии: москва - россия + франция)")
+		fmt.Println("Методы аналогий: 3cosadd (по умолчанию), 3cosmul")
 		return
 	}
 
 	mode := os.Args[1]
-	if mode != "word" && mode != "phrase" {
-		fmt.Println("Неправильный режим. Используйте 'word' или 'phrase'.")
+	if mode != "word" && mode != "phrase" && mode != "analogy" {
+		fmt.Println("Неправильный режим. Используйте 'word', 'phrase' или 'analogy'.")
 		return
 	}
 
+	method := vectors.CosAdd
+	if mode == "analogy" && len(os.Args) > 2 {
+		method, err = vectors.ParseAnalogyMethod(os.Args[2])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
 	// Бесконечный цикл для интерактивного диалога
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
-		if mode == "word" {
+		switch mode {
+		case "word":
 			fmt.Println("Введите слово для поиска синонимов (или 'exit' для выхода):")
-		} else {
+		case "phrase":
 			fmt.Println("Введите фразу для поиска синонимов (или 'exit' для выхода):")
+		default:
+			fmt.Println("Введите аналогию, например 'москва - россия + франция' (или 'exit' для выхода):")
 		}
 
 		scanner.Scan()
@@ -234,45 +98,86 @@ func main() {
 			break
 		}
 
+		topN := 20
+
+		if mode == "analogy" {
+			// Решение аналогии: слова со знаком минус вычитаются, остальные складываются
+			positive, negative, err := vectors.ParseAnalogy(strings.ToLower(input))
+			if err != nil {
+				fmt.Println("Ошибка разбора аналогии:", err)
+				continue
+			}
+			answers, err := model.Analogy(positive, negative, method, topN)
+			if err != nil {
+				fmt.Println("Ошибка поиска аналогии:", err)
+				continue
+			}
+			fmt.Printf("Топ-%d ответов (%s):\n", topN, method)
+			for i, answer := range answers {
+				fmt.Printf("%d. %s (%.4f)\n", i+1, answer.Word, answer.Similarity)
+			}
+			fmt.Println()
+			continue
+		}
+
 		var targetVector []float64
 		if mode == "word" {
 			// Поиск синонимов по слову
-			vec, ok := vectors[input]
+			vec, ok := model.Vector(input)
 			if !ok {
 				fmt.Printf("Слово '%s' не найдено в векторах.\n", input)
 				continue
 			}
+			if !model.Has(input) {
+				if correction, ok := correctionInModel(checker, model, input); ok {
+					fmt.Printf("Слова '%s' нет в словаре, используется '%s'.\n", input, correction)
+				} else {
+					fmt.Printf("Слова '%s' нет в словаре, вектор построен по n-граммам.\n", input)
+				}
+			}
 			targetVector = vec
 		} else {
 			// Поиск синонимов по фразе
-			// Приводим фразу к нижнему регистру
-			input = strings.ToLower(input)
-			// Удаляем знаки пунктуации
-			input = RemovePunctuation(input)
-			// Удаляем стоп-слова
-			filteredPhrase := RemoveStopWords(input, stopWords)
+			// Приводим фразу к нижнему регистру, удаляем пунктуацию и стоп-слова
+			filteredPhrase := textprocessor.CleanPhrase(input, stopWords)
 			// Выводим итоговую фразу
 			fmt.Printf("Очищенная фраза: '%s'\n", filteredPhrase)
 			// Преобразуем фразу в вектор
-			targetVector = TextToVector(filteredPhrase, vectors)
+			var stats docvec.Stats
+			targetVector, stats = embedder.EmbedText(filteredPhrase)
 			if targetVector == nil {
 				fmt.Println("Фраза не содержит слов из векторов.")
 				continue
 			}
+			if len(stats.Missing) > 0 {
+				fmt.Printf("Нет в векторах: %s (покрытие %.0f%%)\n", strings.Join(stats.Missing, ", "), 100*stats.Coverage())
+			}
 		}
 
 		// Поиск синонимов
-		topN := 20
-		synonyms, err := FindSynonyms(targetVector, vectors, topN)
-		if err != nil {
-			fmt.Println("Ошибка поиска синонимов:", err)
+		synonyms := model.Nearest(targetVector, topN, nil)
+		if len(synonyms) == 0 {
+			fmt.Println("Не удалось найти синонимы: вектор имеет нулевую длину.")
 			continue
 		}
 
 		fmt.Printf("Топ-%d синонимов:\n", topN)
 		for i, syn := range synonyms {
-			fmt.Printf("%d. %s\n", i+1, syn)
+			fmt.Printf("%d. %s\n", i+1, syn.Word)
 		}
 		fmt.Println()
 	}
 }
+
+// correctionInModel возвращает лучшее исправление слова, которое есть в векторах
+func correctionInModel(checker *spell.Checker, model *vectors.Model, word string) (string, bool) {
+	if checker == nil {
+		return "", false
+	}
+	for _, s := range checker.Suggest(word, 0) {
+		if model.Has(s.Word) {
+			return s.Word, true
+		}
+	}
+	return "", false
+}
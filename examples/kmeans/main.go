@@ -1,180 +1,45 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"math"
-	"math/rand"
+	"glove-pipeline/pkg/cluster"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/vectors"
 	"os"
-	"strconv"
 	"strings"
-	"time"
 )
 
-// WordVector представляет вектор слова.
-type WordVector struct {
-	Word   string
-	Vector []float64
-}
-
-// LoadVectors загружает векторы из файла.
-func LoadVectors(filename string) (map[string][]float64, error) {
-	file, err := os.Open(filename)
+func main() {
+	k := flag.Int("k", 4, "Количество кластеров")
+	metricName := flag.String("metric", "cosine", "Мера расстояния: cosine (сферический k-means) или euclidean")
+	nInit := flag.Int("n-init", 10, "Число перезапусков с разной инициализацией")
+	seed := flag.Int64("seed", 1, "Зерно генератора случайных чисел")
+	batchSize := flag.Int("batch", 0, "Размер мини-пакета (0 — обычный k-means)")
+	sweep := flag.Bool("sweep", false, "Подобрать k от 2 до числа текстов − 1 по силуэту вместо -k")
+	weightingName := flag.String("weighting", "sif", "Взвешивание слов в векторах текстов: mean, tfidf или sif")
+	flag.Parse()
+
+	metric, err := cluster.ParseMetric(*metricName)
 	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	vectors := make(map[string][]float64)
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		parts := strings.Fields(scanner.Text())
-		if len(parts) < 2 {
-			continue
-		}
-		word := parts[0]
-		vector := make([]float64, len(parts)-1)
-		for i := 1; i < len(parts); i++ {
-			val, err := strconv.ParseFloat(parts[i], 64)
-			if err != nil {
-				return nil, err
-			}
-			vector[i-1] = val
-		}
-		vectors[word] = vector
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return vectors, nil
-}
-
-// TextToVector преобразует текст в средний вектор слов.
-func TextToVector(text string, vectors map[string][]float64) []float64 {
-	words := strings.Fields(text)
-	if len(words) == 0 || len(vectors) == 0 {
-		return nil // Возвращаем nil, если текст пуст или векторы не загружены
-	}
-
-	// Определяем длину вектора на основе первого слова в vectors
-	var vectorLength int
-	for _, vec := range vectors {
-		vectorLength = len(vec)
-		break
-	}
-
-	sumVector := make([]float64, vectorLength)
-	count := 0
-
-	for _, word := range words {
-		if vec, ok := vectors[word]; ok {
-			for i := range vec {
-				sumVector[i] += vec[i]
-			}
-			count++
-		}
-	}
-
-	if count > 0 {
-		for i := range sumVector {
-			sumVector[i] /= float64(count)
-		}
-	}
-
-	return sumVector
-}
-
-// EuclideanDistance вычисляет евклидово расстояние между двумя векторами.
-func EuclideanDistance(vec1, vec2 []float64) float64 {
-	if len(vec1) != len(vec2) {
-		return math.Inf(1) // Возвращаем +Inf, если векторы разной длины
-	}
-
-	var sum float64
-	for i := range vec1 {
-		sum += math.Pow(vec1[i]-vec2[i], 2)
-	}
-	return math.Sqrt(sum)
-}
-
-// KMeans реализует алгоритм k-means.
-func KMeans(data [][]float64, k int, maxIterations int) ([]int, [][]float64) {
-	// Инициализация центроидов случайным образом
-	rand.Seed(time.Now().UnixNano())
-	centroids := make([][]float64, k)
-	for i := range centroids {
-		centroids[i] = data[rand.Intn(len(data))]
+		fmt.Println(err)
+		return
 	}
-
-	// Массив для хранения меток кластеров
-	labels := make([]int, len(data))
-
-	for iter := 0; iter < maxIterations; iter++ {
-		// Шаг 1: Назначение кластеров
-		for i, point := range data {
-			minDist := math.Inf(1)
-			for j, centroid := range centroids {
-				dist := EuclideanDistance(point, centroid)
-				if dist < minDist {
-					minDist = dist
-					labels[i] = j
-				}
-			}
-		}
-
-		// Шаг 2: Пересчет центроидов
-		newCentroids := make([][]float64, k)
-		counts := make([]int, k)
-		for i := range newCentroids {
-			newCentroids[i] = make([]float64, len(data[0]))
-		}
-
-		for i, point := range data {
-			cluster := labels[i]
-			for j := range point {
-				newCentroids[cluster][j] += point[j]
-			}
-			counts[cluster]++
-		}
-
-		for i := range newCentroids {
-			if counts[i] > 0 {
-				for j := range newCentroids[i] {
-					newCentroids[i][j] /= float64(counts[i])
-				}
-			}
-		}
-
-		// Проверка на сходимость
-		converged := true
-		for i := range centroids {
-			if EuclideanDistance(centroids[i], newCentroids[i]) > 1e-6 {
-				converged = false
-				break
-			}
-		}
-
-		if converged {
-			break
-		}
-
-		centroids = newCentroids
+	weighting, err := docvec.ParseWeighting(*weightingName)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	return labels, centroids
-}
-
-func main() {
 	// Загрузка векторов
-	vectors, err := LoadVectors("../../data/vectors.txt.txt")
+	model, err := vectors.Load("../../data/vectors.txt.txt")
 	if err != nil {
 		fmt.Println("Ошибка загрузки векторов:", err)
 		return
 	}
 
-	if len(vectors) == 0 {
+	if model.Len() == 0 {
 		fmt.Println("Векторы не загружены или файл пуст.")
 		return
 	}
@@ -189,14 +54,37 @@ func main() {
 		"это у них есть такая иллюзия что это в принципе возможно окопаться и закрепиться за суток оно возможно только если у тебя есть готовое выстроенное логистическое плечо отуда оно у франции или германии с британией это смехотворно в ес сегодня даже нет так называемого военного шенгена то есть пока грузы едут из одной директории в другую буквально каждые километров они останавливаются на сутки не меньше просто потому что так устроено в европейской бюрократии и изменить это положение дел невозможно абсолютно для этого потребуется много бюрократической возни на годы вперд нет тридцати дней не хватит на окопаться но перегруппироваться укомплектовать более боеспособные подразделения в целом можно если есть человеческий ресурс и основной вопрос путин уже спросил притом он сразу же ответил на него чтобы у запада не было иллюзий кто определит где и кто нарушил возможную договорнность о прекращении огня на протяжении двух тысяч километров и потом кто на кого будет сваливать нарушение этой договорнности если ктото хочет чемто воспользоваться и прямо так обмануть россию то пусть не удивляются почему это поначалу оказалось так просто сделать а потом тысяч натовских трупов и приказ конечно берите в плен если возможно и соответствует боевой обстановке",
 	}
 
+	// Частоты слов для взвешивания; без словаря векторы текстов — простые средние
+	counts, err := glove.VocabCounts("../../data/vocab.txt")
+	if err != nil {
+		fmt.Println("Частоты слов не загружены, используется простое среднее:", err)
+	}
+	embedOpts := docvec.DefaultOptions()
+	embedOpts.Weighting = weighting
+	embedder := docvec.New(model, counts, embedOpts)
+
+	// Общая для всех текстов главная компонента вычитается из их векторов
+	words := make([][]string, len(texts))
+	for i, text := range texts {
+		words[i] = strings.Fields(text)
+	}
+	if err := embedder.Fit(words); err != nil {
+		fmt.Println("Главная компонента не вычтена:", err)
+	}
+
 	// Преобразуем тексты в векторы
 	var data [][]float64
-	for _, text := range texts {
-		vector := TextToVector(text, vectors)
+	var clustered []string
+	var summary docvec.Summary
+	for i, text := range texts {
+		vector, stats := embedder.Embed(words[i])
+		summary.Add(stats)
 		if vector != nil {
 			data = append(data, vector)
+			clustered = append(clustered, text)
 		}
 	}
+	fmt.Printf("Покрытие: %s\n", summary.String())
 
 	if len(data) == 0 {
 		fmt.Println("Нет данных для кластеризации.")
@@ -204,19 +92,38 @@ func main() {
 	}
 
 	// Кластеризация с использованием k-means
-	k := 4 // Количество кластеров
-	labels, centroids := KMeans(data, k, 1000)
-
-	// Вывод результатов
-	fmt.Println("Центроиды:")
-	for i, centroid := range centroids {
-		fmt.Printf("Кластер %d: %v\n", i, centroid)
+	opts := cluster.DefaultKMeansOptions(*k)
+	opts.Metric = metric
+	opts.NInit = *nInit
+	opts.Seed = *seed
+	opts.BatchSize = *batchSize
+	if *sweep && len(data) > 2 {
+		sweepResult, err := cluster.SweepK(data, 2, len(data)-1, opts, 0)
+		if err != nil {
+			fmt.Println("Ошибка подбора числа кластеров:", err)
+			return
+		}
+		sweepResult.Print(os.Stdout)
+		fmt.Println()
+		opts.K = sweepResult.Recommended
+	}
+	result, err := cluster.KMeans(data, opts)
+	if err != nil {
+		fmt.Println("Ошибка кластеризации:", err)
+		return
 	}
 
-	fmt.Println("\nРезультаты кластеризации:")
-	for i, text := range texts {
-		if i < len(labels) {
-			fmt.Printf("Текст: '%s' -> Кластер %d\n", text, labels[i])
-		}
+	// Вывод результатов: ближайшие слова, характерные термины и самые близкие к центроиду тексты
+	fmt.Printf("Инерция: %.4f, итераций: %d\n\n", result.Inertia, result.Iterations)
+	ids := make([]string, len(clustered))
+	for i := range ids {
+		ids[i] = fmt.Sprintf("текст %d", i+1)
+	}
+	describeOpts := cluster.DefaultDescribeOptions()
+	describeOpts.TopDocs = 2
+	describeOpts.MaxTextLen = 120
+	descriptions := cluster.Describe(model, ids, clustered, data, result.Labels, result.Centroids, describeOpts)
+	if err := cluster.WriteMarkdown(os.Stdout, descriptions); err != nil {
+		fmt.Println("Ошибка вывода:", err)
 	}
 }
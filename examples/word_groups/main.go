@@ -2,293 +2,198 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"math"
 	"os"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/Jeffail/tunny"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/cluster"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+
 	"github.com/cheggaaa/pb/v3"
 )
 
-type WordVector struct {
-	Word   string
-	Vector []float64
+var defaultStopWords = map[string]struct{}{
+	"и": {}, "в": {}, "не": {}, "по": {}, "же": {}, "с": {}, "о": {},
 }
 
-var (
-	vectors     map[string][]float64
-	vectorsLock sync.RWMutex
-	progressBar *pb.ProgressBar
-)
-
-func loadGloveVectors(filename string) error {
-	file, err := os.Open(filename)
+// corpusVocabulary собирает уникальные слова корпуса (без стоп-слов). Корпус уже
+// очищен на шаге -clean, поэтому строки только делятся на слова
+func corpusVocabulary(filename string, stopWords map[string]struct{}) (map[string]bool, error) {
+	// Корпус может быть как текстовым, так и в формате JSONL с метаданными
+	lines, err := corpus.ReadTexts(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
-
-	tempVectors := make(map[string][]float64)
-	scanner := bufio.NewScanner(file)
 
-	for scanner.Scan() {
-		parts := strings.Fields(scanner.Text())
-		if len(parts) < 2 {
-			continue
-		}
-
-		word := parts[0]
-		vector := make([]float64, len(parts)-1)
+	vocab := make(map[string]bool)
+	var mu sync.Mutex
 
-		for i := 1; i < len(parts); i++ {
-			_, err := fmt.Sscanf(parts[i], "%f", &vector[i-1])
-			if err != nil {
-				return err
+	bar := pb.StartNew(len(lines))
+	bar.SetTemplateString(`{{counters . }} {{ bar . "[" "=" ">" " " "]" }} {{percent . }} {{etime . }}`)
+
+	// Строки обрабатываются блоками: слова блока собираются отдельно и
+	// добавляются в общий словарь под одной блокировкой
+	const chunkSize = 1024
+	parallel.For((len(lines)+chunkSize-1)/chunkSize, func(chunk int) {
+		block := lines[chunk*chunkSize : min(len(lines), (chunk+1)*chunkSize)]
+		words := make(map[string]bool)
+		for _, line := range block {
+			for _, word := range strings.Fields(textprocessor.RemoveStopWords(line, stopWords)) {
+				words[word] = true
 			}
 		}
-		tempVectors[word] = vector
-	}
-
-	vectorsLock.Lock()
-	vectors = tempVectors
-	vectorsLock.Unlock()
-
-	return scanner.Err()
+		mu.Lock()
+		for word := range words {
+			vocab[word] = true
+		}
+		mu.Unlock()
+		bar.Add(len(block))
+	})
+	bar.Finish()
+	return vocab, nil
 }
 
-func readLines(filename string) ([]string, error) {
-	file, err := os.Open(filename)
+// saveGroups сохраняет группы: слова группы через запятую, по группе на строку
+// (этот формат читает команда export-synonyms)
+func saveGroups(groups []cluster.Group, words []string, filename string) error {
+	file, err := os.Create(filename)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer file.Close()
 
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	writer := bufio.NewWriter(file)
+	for _, g := range groups {
+		line := make([]string, len(g.Members))
+		for i, m := range g.Members {
+			line[i] = words[m]
+		}
+		if _, err := writer.WriteString(strings.Join(line, ", ") + "\n"); err != nil {
+			return err
+		}
 	}
-	return lines, scanner.Err()
-}
-
-func cleanText(text string) []string {
-	reg := regexp.MustCompile(`[^а-яА-ЯёЁa-zA-Z\s]`)
-	cleaned := reg.ReplaceAllString(text, " ")
-	return strings.Fields(strings.ToLower(cleaned))
-}
-
-var stopWords = map[string]bool{
-	"и": true, "в": true, "не": true, "по": true, "же": true, "с": true, "о": true,
+	return writer.Flush()
 }
 
-func filterStopWords(words []string) []string {
-	filtered := make([]string, 0, len(words))
-	for _, word := range words {
-		if !stopWords[word] {
-			filtered = append(filtered, word)
+// saveReport сохраняет группы с оценками качества в формате JSON
+func saveReport(result *cluster.GraphResult, words []string, filename string) error {
+	type group struct {
+		Words    []string `json:"words"`
+		Cohesion float64  `json:"cohesion"`
+	}
+	report := struct {
+		Modularity float64 `json:"modularity"`
+		Cohesion   float64 `json:"cohesion"`
+		Groups     []group `json:"groups"`
+	}{Modularity: result.Modularity, Cohesion: result.Cohesion}
+	for _, g := range result.Groups {
+		entry := group{Cohesion: g.Cohesion}
+		for _, m := range g.Members {
+			entry.Words = append(entry.Words, words[m])
 		}
+		report.Groups = append(report.Groups, entry)
 	}
-	return filtered
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0644)
 }
 
-func cosineSimilarity(a, b []float64) float64 {
-	dotProduct := 0.0
-	magA := 0.0
-	magB := 0.0
+func main() {
+	vectorsFile := flag.String("vectors", "../../data/vectors.txt.txt", "Файл векторов")
+	corpusFile := flag.String("corpus", "../../data/cleaned_corpus.txt", "Корпус, слова которого группируются (пусто — словарь модели)")
+	stopwordsFile := flag.String("stopwords", "../../data/stopwords.txt", "Файл стоп-слов")
+	methodName := flag.String("method", "louvain", "Способ выделения групп: components, louvain, cliques")
+	threshold := flag.Float64("threshold", 0.7, "Минимальное косинусное сходство соседей")
+	k := flag.Int("k", 10, "Число соседей каждого слова в графе")
+	limit := flag.Int("limit", 50000, "Сколько самых частых слов рассматривать (0 — все)")
+	resolution := flag.Float64("resolution", 1, "Разрешение Louvain: больше — мельче группы")
+	output := flag.String("output", "word_groups.txt", "Файл для групп")
+	flag.Parse()
 
-	for i := range a {
-		dotProduct += a[i] * b[i]
-		magA += a[i] * a[i]
-		magB += b[i] * b[i]
+	startTime := time.Now()
+	method, err := cluster.ParseGraphMethod(*methodName)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	magA = math.Sqrt(magA)
-	magB = math.Sqrt(magB)
-
-	if magA == 0 || magB == 0 {
-		return 0.0
+	fmt.Println("Загрузка векторов GloVe...")
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		fmt.Println("Ошибка загрузки векторов:", err)
+		return
 	}
 
-	return dotProduct / (magA * magB)
-}
-
-func processChunk(chunk []string, threshold float64) [][]string {
-	localGroups := [][]string{}
-	used := make(map[string]bool)
-
-	for _, word1 := range chunk {
-		if used[word1] {
-			continue
-		}
-
-		vectorsLock.RLock()
-		vec1, exists := vectors[word1]
-		vectorsLock.RUnlock()
-
-		if !exists {
-			continue
-		}
-
-		group := []string{word1}
-		used[word1] = true
-
-		for _, word2 := range chunk {
-			if word1 == word2 || used[word2] {
-				continue
-			}
-
-			vectorsLock.RLock()
-			vec2, exists := vectors[word2]
-			vectorsLock.RUnlock()
-
-			if !exists {
-				continue
-			}
-
-			if cosineSimilarity(vec1, vec2) > threshold {
-				group = append(group, word2)
-				used[word2] = true
-			}
-		}
+	stopWords, err := ngrams.LoadStopwords(*stopwordsFile)
+	if err != nil {
+		stopWords = defaultStopWords
+	}
 
-		if len(group) > 0 {
-			localGroups = append(localGroups, group)
+	var vocab map[string]bool
+	if *corpusFile != "" {
+		fmt.Println("Сбор словаря корпуса...")
+		if vocab, err = corpusVocabulary(*corpusFile, stopWords); err != nil {
+			fmt.Println("Ошибка чтения файла:", err)
+			return
 		}
 	}
 
-	progressBar.Add(len(chunk))
-	return localGroups
-}
-
-func groupSimilarWords(words []string, threshold float64) [][]string {
-	var (
-		groups [][]string
-		mu     sync.Mutex
-	)
-
-	progressBar = pb.StartNew(len(words))
-	progressBar.SetTemplateString(`{{counters . }} {{ bar . "[" "=" ">" " " "]" }} {{percent . }} {{etime . }}`)
-
-	pool := tunny.NewFunc(32, func(payload interface{}) interface{} {
-		chunk := payload.([]string)
-		return processChunk(chunk, threshold)
-	})
-	defer pool.Close()
-
-	chunkSize := (len(words) + 31) / 32
-	var wg sync.WaitGroup
-	results := make(chan [][]string, 32)
-
-	for i := 0; i < 32; i++ {
-		start := i * chunkSize
-		end := start + chunkSize
-		if end > len(words) {
-			end = len(words)
+	// Уникальные слова в порядке модели (по убыванию частоты)
+	var words []string
+	var vecs [][]float64
+	for i, word := range model.Words {
+		if *limit > 0 && len(words) >= *limit {
+			break
 		}
-		if start >= end {
+		if _, stop := stopWords[word]; stop || (vocab != nil && !vocab[word]) {
 			continue
 		}
-
-		wg.Add(1)
-		go func(chunk []string) {
-			defer wg.Done()
-			result := pool.Process(chunk).([][]string)
-			results <- result
-		}(words[start:end])
-	}
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	for result := range results {
-		mu.Lock()
-		groups = append(groups, result...)
-		mu.Unlock()
+		words = append(words, word)
+		vecs = append(vecs, model.Vectors[i])
 	}
 
-	progressBar.Finish()
-	return groups
-}
-
-func saveGroupsToFile(groups [][]string, filename string) error {
-	file, err := os.Create(filename)
+	fmt.Printf("Построение графа соседей для %d слов...\n", len(words))
+	index, err := ann.Build(vecs, ann.DefaultConfig())
 	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	for _, group := range groups {
-		line := strings.Join(group, ", ")
-		_, err := writer.WriteString(line + "\n")
-		if err != nil {
-			return err
-		}
+		fmt.Println("Ошибка построения индекса:", err)
+		return
 	}
-	return writer.Flush()
-}
-
-func main() {
-	startTime := time.Now()
-
-	fmt.Println("Загрузка векторов GloVe...")
-	if err := loadGloveVectors("../../data/vectors.txt.txt"); err != nil {
-		fmt.Println("Ошибка загрузки векторов:", err)
+	graph, err := ann.KNN(index, *k, *threshold)
+	if err != nil {
+		fmt.Println("Ошибка построения графа соседей:", err)
 		return
 	}
 
-	fmt.Println("Чтение входного файла...")
-	lines, err := readLines("/home/audetv/go/src/github.com/terratensor/glove-pipeline/data/svodd/cleaned_corpus.txt")
+	fmt.Println("Группировка слов...")
+	result, err := cluster.ClusterGraph(graph, vecs, cluster.GraphOptions{Method: method, Resolution: *resolution})
 	if err != nil {
-		fmt.Println("Ошибка чтения файла:", err)
+		fmt.Println("Ошибка кластеризации:", err)
 		return
 	}
+	fmt.Printf("Групп: %d, модулярность %.4f, средняя связность %.4f\n", len(result.Groups), result.Modularity, result.Cohesion)
 
-	fmt.Println("Очистка текста...")
-	var allWords []string
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	cleanBar := pb.StartNew(len(lines))
-	cleanBar.SetTemplateString(`{{counters . }} {{ bar . "[" "=" ">" " " "]" }} {{percent . }} {{etime . }}`)
-
-	cleanPool := tunny.NewFunc(32, func(payload interface{}) interface{} {
-		line := payload.(string)
-		words := filterStopWords(cleanText(line))
-		mu.Lock()
-		allWords = append(allWords, words...)
-		mu.Unlock()
-		cleanBar.Increment()
-		return nil
-	})
-	defer cleanPool.Close()
-
-	for _, line := range lines {
-		wg.Add(1)
-		go func(line string) {
-			defer wg.Done()
-			cleanPool.Process(line)
-		}(line)
+	fmt.Println("Сохранение результатов...")
+	if err := saveGroups(result.Groups, words, *output); err != nil {
+		fmt.Println("Ошибка сохранения:", err)
+		return
 	}
-
-	wg.Wait()
-	cleanBar.Finish()
-
-	fmt.Println("\nГруппировка слов...")
-	groups := groupSimilarWords(allWords, 0.7)
-
-	fmt.Println("\nСохранение результатов...")
-	if err := saveGroupsToFile(groups, "word_groups.txt"); err != nil {
+	reportFile := strings.TrimSuffix(*output, ".txt") + ".json"
+	if err := saveReport(result, words, reportFile); err != nil {
 		fmt.Println("Ошибка сохранения:", err)
 		return
 	}
 
 	fmt.Printf("\nОбработка завершена за %v\n", time.Since(startTime))
-	fmt.Println("Результат сохранен в word_groups.txt")
+	fmt.Printf("Результат сохранен в %s, оценки групп — в %s\n", *output, reportFile)
 }
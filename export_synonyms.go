@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/synonyms"
+	"glove-pipeline/pkg/vectors"
+	"log"
+)
+
+// runExportSynonyms строит словари синонимов для поисковых движков по соседям в векторной модели
+func runExportSynonyms(args []string) error {
+	defaults := synonyms.DefaultOptions()
+	fs := flag.NewFlagSet("export-synonyms", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов")
+	groupsFile := fs.String("groups", "", "Готовые группы слов (вывод word_groups); без него группы строятся по соседям в модели")
+	formats := fs.String("formats", "wordforms,elasticsearch,solr", "Форматы через запятую: wordforms, exceptions, elasticsearch, solr")
+	output := fs.String("output", "data/synonyms", "Префикс выходных файлов (к нему добавляется _<формат>.txt или .json)")
+	topK := fs.Int("top", defaults.TopK, "Число соседей, рассматриваемых для каждого слова")
+	threshold := fs.Float64("threshold", defaults.Threshold, "Минимальное косинусное сходство синонима")
+	maxGroup := fs.Int("max-group", defaults.MaxGroupSize, "Максимальный размер группы вместе с главным словом (0 — без ограничения)")
+	mutual := fs.Bool("mutual", false, "Оставлять только взаимных соседей")
+	limit := fs.Int("limit", defaults.Limit, "Сколько самых частых слов модели рассматривать (0 — все)")
+	exact := fs.Bool("exact", false, "Искать соседей полным перебором вместо HNSW")
+	denyFiles := fs.String("deny", "", "Файлы запрещённых слов (по слову на строку) через запятую")
+	stopwordsFile := fs.String("stopwords", "", "Файл стоп-слов, которые также исключаются из словаря")
+	equivalent := fs.Bool("equivalent", false, "Для Elasticsearch и Solr записывать группы как равнозначные слова вместо явного расширения")
+	fs.Parse(args)
+
+	if *topK < 1 {
+		return fmt.Errorf("число соседей -top должно быть не меньше 1: %d", *topK)
+	}
+
+	opts := defaults
+	opts.TopK = *topK
+	opts.Threshold = *threshold
+	opts.MaxGroupSize = *maxGroup
+	opts.Mutual = *mutual
+	opts.Limit = *limit
+	opts.Exact = *exact
+	opts.Deny = make(map[string]bool)
+	for _, file := range parseList(*denyFiles) {
+		words, err := vectors.LoadWords(file)
+		if err != nil {
+			return err
+		}
+		for _, word := range words {
+			opts.Deny[word] = true
+		}
+	}
+	if *stopwordsFile != "" {
+		stopWords, err := ngrams.LoadStopwords(*stopwordsFile)
+		if err != nil {
+			return err
+		}
+		for word := range stopWords {
+			opts.Deny[word] = true
+		}
+	}
+
+	var selected []synonyms.Format
+	for _, name := range parseList(*formats) {
+		format, err := synonyms.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, format)
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("не указаны форматы словаря (-formats)")
+	}
+
+	// Для готовых групп модель нужна только для порога и фильтра взаимных соседей
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		if *groupsFile == "" {
+			return err
+		}
+		log.Printf("Векторы не загружены, порог и фильтр взаимных соседей не применяются: %v", err)
+		model = nil
+	}
+
+	var groups []synonyms.Group
+	if *groupsFile != "" {
+		loaded, err := synonyms.LoadGroups(*groupsFile)
+		if err != nil {
+			return err
+		}
+		groups = synonyms.Filter(loaded, model, opts)
+		fmt.Printf("Групп в %s: %d, после фильтрации: %d\n", *groupsFile, len(loaded), len(groups))
+	} else {
+		words := model.Len()
+		if opts.Limit > 0 && opts.Limit < words {
+			words = opts.Limit
+		}
+		fmt.Printf("Поиск соседей для %d слов...\n", words)
+		if groups, err = synonyms.Build(model, opts); err != nil {
+			return err
+		}
+		fmt.Printf("Построено групп: %d\n", len(groups))
+	}
+
+	for _, format := range selected {
+		filename := *output + "_" + string(format) + format.Extension()
+		if err := synonyms.Save(filename, groups, format, *equivalent); err != nil {
+			return err
+		}
+		fmt.Printf("Словарь %s сохранён в %s\n", format, filename)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/docindex"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// runIndex строит индекс похожих документов или дополняет существующий новыми строками CSV
+func runIndex(args []string) error {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	input := fs.String("input", "data/input.csv", "Документы: CSV (текст очищается) или корпус JSONL/текст")
+	output := fs.String("output", "data/index", "Каталог индекса")
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов (для нового индекса)")
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов (для нового индекса)")
+	weightingName := fs.String("weighting", "sif", "Взвешивание слов: mean, tfidf или sif (для нового индекса)")
+	removePC := fs.Bool("remove-pc", true, "Вычитать первую главную компоненту (для нового индекса)")
+	fitSample := fs.Int("fit-sample", 20000, "Сколько первых документов использовать для главной компоненты")
+	textColumn := fs.String("text-column", "", "Столбец CSV с текстом (по умолчанию определяется по заголовку)")
+	idColumn := fs.String("id-column", "", "Столбец CSV с идентификатором (по умолчанию определяется по заголовку)")
+	rebuild := fs.Bool("rebuild", false, "Построить индекс заново, даже если он уже существует")
+	fs.Parse(args)
+
+	reader, err := textprocessor.OpenDocuments(*input, textprocessor.Columns{Text: *textColumn, ID: *idColumn})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	next := reader.Next
+
+	var ix *docindex.Index
+	if docindex.Exists(*output) && !*rebuild {
+		// Дополнение: документы с уже проиндексированными идентификаторами пропускаются
+		if ix, err = docindex.Load(*output, nil); err != nil {
+			return err
+		}
+		log.Printf("Загружен индекс %s: %d документов", *output, ix.Len())
+	} else {
+		model, err := vectors.Load(*vectorsFile)
+		if err != nil {
+			return err
+		}
+		weighting, err := docvec.ParseWeighting(*weightingName)
+		if err != nil {
+			return err
+		}
+		meta := docindex.Meta{VectorsFile: *vectorsFile, Weighting: weighting, A: docvec.DefaultOptions().A, ANN: ann.DefaultConfig()}
+		if weighting != docvec.Mean {
+			meta.VocabFile = *vocabFile
+		}
+		embedder, err := docindex.NewEmbedder(model, meta)
+		if err != nil {
+			return err
+		}
+
+		// Главная компонента считается по первым документам; они же индексируются первыми
+		var head []corpus.Document
+		if *removePC {
+			for len(head) < *fitSample {
+				doc, err := next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				head = append(head, doc)
+			}
+			texts := make([][]string, len(head))
+			for i, doc := range head {
+				texts[i] = strings.Fields(doc.Text)
+			}
+			if err := embedder.Fit(texts); err != nil {
+				return err
+			}
+		}
+		ix = docindex.New(model, embedder, meta)
+		rest := next
+		next = func() (corpus.Document, error) {
+			if len(head) > 0 {
+				doc := head[0]
+				head = head[1:]
+				return doc, nil
+			}
+			return rest()
+		}
+	}
+
+	added, skipped, err := ix.AddAll(next)
+	if err != nil {
+		return err
+	}
+	if err := ix.Save(*output); err != nil {
+		return err
+	}
+	fmt.Printf("Добавлено документов: %d, пропущено (уже в индексе или без слов из модели): %d, всего в индексе: %d\n",
+		added, skipped, ix.Len())
+	fmt.Printf("Индекс сохранён в %s\n", *output)
+	return nil
+}
+
+// runSearch ищет документы, похожие на текст запроса или на документ индекса
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	indexDir := fs.String("index", "data/index", "Каталог индекса")
+	query := fs.String("q", "", "Текст запроса")
+	id := fs.String("id", "", "Идентификатор документа индекса, похожие на который нужно найти")
+	top := fs.Int("top", 10, "Число документов в ответе")
+	vectorsFile := fs.String("vectors", "", "Файл векторов (по умолчанию — тот, по которому строился индекс)")
+	asJSON := fs.Bool("json", false, "Вывести результат в формате JSON")
+	maxLen := fs.Int("max-len", 200, "Максимальная длина выводимого текста документа в символах")
+	fs.Parse(args)
+
+	if (*query == "") == (*id == "") {
+		return fmt.Errorf("нужно задать либо -q, либо -id")
+	}
+	var model *vectors.Model
+	if *vectorsFile != "" {
+		var err error
+		if model, err = vectors.Load(*vectorsFile); err != nil {
+			return err
+		}
+	}
+	ix, err := docindex.Load(*indexDir, model)
+	if err != nil {
+		return err
+	}
+
+	var hits []docindex.Hit
+	if *id != "" {
+		if hits, err = ix.Similar(*id, *top); err != nil {
+			return err
+		}
+	} else {
+		var stats docvec.Stats
+		hits, stats = ix.Search(*query, *top)
+		if stats.Known == 0 {
+			return fmt.Errorf("в запросе нет слов из векторов")
+		}
+		if len(stats.Missing) > 0 {
+			log.Printf("Нет в векторах: %s (покрытие %.0f%%)", strings.Join(stats.Missing, ", "), 100*stats.Coverage())
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(hits)
+	}
+	for i, hit := range hits {
+		meta := hit.ID
+		for _, value := range []string{hit.Timestamp, hit.Source, hit.Author} {
+			if value != "" {
+				meta += ", " + value
+			}
+		}
+		fmt.Printf("%d. [%s] %.4f\n   %s\n", i+1, meta, hit.Score, textprocessor.Truncate(hit.Text, *maxLen))
+	}
+	return nil
+}
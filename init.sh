@@ -29,13 +29,13 @@ cat << 'EOF' > scripts/glove.sh
 # Путь к исполняемым файлам GloVe
 GLOVE_PATH="./third_party/glove/build"
 
-# Входной файл с текстом
-INPUT_FILE="./data/cleaned_corpus.txt"
+# Входной файл с текстом (может быть переопределен переменной окружения)
+INPUT_FILE="${INPUT_FILE:-./data/cleaned_corpus.txt}"
 
-# Выходные файлы
-OUTPUT_VOCAB="./data/vocab.txt"
-OUTPUT_COOCCURRENCE="./data/cooccurrence.bin"
-OUTPUT_VECTORS="./data/vectors.txt"
+# Выходные файлы (могут быть переопределены переменными окружения)
+OUTPUT_VOCAB="${OUTPUT_VOCAB:-./data/vocab.txt}"
+OUTPUT_COOCCURRENCE="${OUTPUT_COOCCURRENCE:-./data/cooccurrence.bin}"
+OUTPUT_VECTORS="${OUTPUT_VECTORS:-./data/vectors.txt}"
 
 # Параметры GloVe
 VOCAB_MIN_COUNT=5  # Минимальная частота слова для включения в словарь
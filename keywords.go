@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/docindex"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/keywords"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// documentKeywords — ключевые фразы одного документа в выходном JSONL
+type documentKeywords struct {
+	ID       string             `json:"id"`
+	Keywords []keywords.Keyword `json:"keywords"`
+}
+
+// runKeywords извлекает ключевые слова и фразы каждого документа корпуса или одного текста
+func runKeywords(args []string) error {
+	defaults := keywords.DefaultOptions()
+	fs := flag.NewFlagSet("keywords", flag.ExitOnError)
+	input := fs.String("input", "data/corpus.jsonl", "Документы: корпус JSONL/текст или CSV (текст очищается)")
+	query := fs.String("q", "", "Извлечь ключевые фразы одного текста вместо корпуса")
+	output := fs.String("output", "data/keywords.jsonl", "Файл результатов в формате JSONL")
+	methodName := fs.String("method", string(defaults.Method), "Способ извлечения: textrank, rake или embedrank")
+	top := fs.Int("top", defaults.Top, "Число ключевых фраз документа")
+	maxWords := fs.Int("max-words", defaults.MaxWords, "Максимальная длина фразы в словах")
+	minLength := fs.Int("min-length", defaults.MinLength, "Минимальная длина слова фразы в символах")
+	window := fs.Int("window", defaults.Window, "Окно совместной встречаемости для TextRank")
+	diversity := fs.Float64("diversity", defaults.Diversity, "Вес разнообразия фраз для EmbedRank (0 — только сходство с документом)")
+	stopwordsFile := fs.String("stopwords", "data/stopwords.txt", "Файл стоп-слов")
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов (для embedrank)")
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов (для embedrank)")
+	weightingName := fs.String("weighting", "sif", "Взвешивание слов в векторах фраз и документов: mean, tfidf или sif")
+	limit := fs.Int("limit", 0, "Сколько первых документов обработать (0 — все)")
+	textColumn := fs.String("text-column", "", "Столбец CSV с текстом (по умолчанию определяется по заголовку)")
+	idColumn := fs.String("id-column", "", "Столбец CSV с идентификатором (по умолчанию определяется по заголовку)")
+	fs.Parse(args)
+
+	opts := defaults
+	var err error
+	if opts.Method, err = keywords.ParseMethod(*methodName); err != nil {
+		return err
+	}
+	opts.Top = *top
+	opts.MaxWords = *maxWords
+	opts.MinLength = *minLength
+	opts.Window = *window
+	opts.Diversity = *diversity
+
+	stopwords, err := ngrams.LoadStopwords(*stopwordsFile)
+	if err != nil {
+		if opts.Method == keywords.RAKE {
+			return err
+		}
+		log.Printf("Стоп-слова не загружены, фразы разделяются только короткими словами и числами: %v", err)
+	}
+
+	var embedder *docvec.Embedder
+	if opts.Method == keywords.EmbedRank {
+		model, err := vectors.Load(*vectorsFile)
+		if err != nil {
+			return err
+		}
+		if embedder, err = newEmbedder(model, *vocabFile, *weightingName); err != nil {
+			return err
+		}
+	}
+	extractor, err := keywords.New(stopwords, embedder, opts)
+	if err != nil {
+		return err
+	}
+
+	if *query != "" {
+		for i, kw := range extractor.Extract(docindex.Clean(*query)) {
+			fmt.Printf("%2d. %-40s %.4f\n", i+1, kw.Phrase, kw.Score)
+		}
+		return nil
+	}
+
+	reader, err := textprocessor.OpenDocuments(*input, textprocessor.Columns{Text: *textColumn, ID: *idColumn})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	enc := json.NewEncoder(file)
+	enc.SetEscapeHTML(false)
+
+	count := 0
+	for *limit <= 0 || count < *limit {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		kws := extractor.Extract(doc.Text)
+		if err := enc.Encode(documentKeywords{ID: doc.ID, Keywords: kws}); err != nil {
+			return fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+		if count < 5 {
+			phrases := make([]string, len(kws))
+			for i, kw := range kws {
+				phrases[i] = kw.Phrase
+			}
+			fmt.Printf("[%s] %s\n", doc.ID, strings.Join(phrases, "; "))
+		}
+		count++
+	}
+	fmt.Printf("Обработано документов: %d\nКлючевые фразы сохранены в %s\n", count, *output)
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/lexicon"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"os"
+	"strings"
+)
+
+// runLexicon строит словарь полярности (тональности, токсичности) по затравочным словам
+func runLexicon(args []string) error {
+	defaults := lexicon.DefaultOptions()
+	fs := flag.NewFlagSet("lexicon", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов")
+	positive := fs.String("positive", "", "Затравки положительного полюса: файл (по слову на строку) или слова через запятую")
+	negative := fs.String("negative", "", "Затравки отрицательного полюса: файл (по слову на строку) или слова через запятую")
+	methodName := fs.String("method", string(defaults.Method), "Способ распространения: sentprop или propagation")
+	k := fs.Int("k", defaults.K, "Число соседей слова в графе")
+	limit := fs.Int("limit", defaults.Limit, "Сколько самых частых слов модели размечать (0 — все)")
+	alpha := fs.Float64("alpha", defaults.Alpha, "Доля продолжения блуждания (меньше — полярность держится ближе к затравкам)")
+	bootstrap := fs.Int("bootstrap", defaults.Bootstrap, "Число повторов на подвыборках затравок для оценки устойчивости (0 — без оценки)")
+	sampleShare := fs.Float64("sample", defaults.Sample, "Доля затравок каждого полюса в подвыборке")
+	seed := fs.Int64("seed", defaults.Seed, "Зерно генератора подвыборок")
+	exact := fs.Bool("exact", false, "Искать соседей полным перебором вместо HNSW")
+	output := fs.String("output", "data/lexicon", "Префикс выходных файлов")
+	minScore := fs.Float64("min-score", 0.2, "Минимальная абсолютная полярность слова в словарях полюсов")
+	minConfidence := fs.Float64("min-confidence", 0.05, "Минимальная уверенность слова в словарях полюсов")
+	minStability := fs.Float64("min-stability", 0.9, "Минимальная устойчивость слова в словарях полюсов")
+	top := fs.Int("top", 500, "Максимальное число слов в словаре каждого полюса (0 — без ограничения)")
+	fs.Parse(args)
+
+	method, err := lexicon.ParseMethod(*methodName)
+	if err != nil {
+		return err
+	}
+	posWords, err := loadSeeds(*positive)
+	if err != nil {
+		return err
+	}
+	negWords, err := loadSeeds(*negative)
+	if err != nil {
+		return err
+	}
+	if len(posWords) == 0 || len(negWords) == 0 {
+		return fmt.Errorf("нужно задать затравки обоих полюсов (-positive и -negative)")
+	}
+
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+	opts := defaults
+	opts.Method = method
+	opts.K = *k
+	opts.Limit = *limit
+	opts.Alpha = *alpha
+	opts.Bootstrap = *bootstrap
+	opts.Sample = *sampleShare
+	opts.Seed = *seed
+	opts.Exact = *exact
+
+	lex, err := lexicon.Induce(model, posWords, negWords, opts)
+	if err != nil {
+		return err
+	}
+	if len(lex.Missing) > 0 {
+		log.Printf("Затравок нет в векторах: %s", strings.Join(lex.Missing, ", "))
+	}
+	fmt.Printf("Затравок: положительных %d, отрицательных %d; размечено слов: %d\n",
+		len(lex.Positive), len(lex.Negative), len(lex.Entries))
+
+	if err := lex.SaveTSV(*output+".tsv", lex.Entries); err != nil {
+		return err
+	}
+	if err := lex.SaveJSON(*output + ".json"); err != nil {
+		return err
+	}
+	for _, pole := range []struct {
+		name     string
+		positive bool
+	}{{"positive", true}, {"negative", false}} {
+		entries := lex.Filter(pole.positive, *minScore, *minConfidence, *minStability, *top)
+		filename := *output + "_" + pole.name + ".tsv"
+		if err := lex.SaveTSV(filename, entries); err != nil {
+			return err
+		}
+		fmt.Printf("\nПолюс %s: %d слов (сохранены в %s)\n", pole.name, len(entries), filename)
+		for i, e := range entries {
+			if i == 15 {
+				break
+			}
+			fmt.Printf("  %-24s %+.3f  уверенность %.2f  устойчивость %.2f\n", e.Word, e.Score, e.Confidence, e.Stability)
+		}
+	}
+	fmt.Printf("\nПолный словарь сохранён в %s.tsv и %s.json\n", *output, *output)
+	return nil
+}
+
+// loadSeeds читает затравочные слова из файла или из списка через запятую
+func loadSeeds(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(value); err == nil {
+		return vectors.LoadWords(value)
+	}
+	return parseList(value), nil
+}
@@ -3,13 +3,27 @@ package main
 import (
 	"flag"
 	"fmt"
+	"glove-pipeline/pkg/corpus"
 	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/langid"
 	"glove-pipeline/pkg/ngrams"
 	"glove-pipeline/pkg/textprocessor"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
+// languageDir — каталог, в который раскладываются корпуса и модели по языкам
+const languageDir = "data"
+
 func main() {
+	// Подкоманды (например, go run . shift ...) разбирают собственные флаги
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
 	// Определение флагов
 	cleanTextFlag := flag.Bool("clean", false, "Запустить только очистку текста")
 	runGloveFlag := flag.Bool("glove", false, "Запустить только GloVe")
@@ -17,68 +31,200 @@ func main() {
 	n := flag.Int("n", 2, "Размер n-грамм (2 для биграмм, 3 для триграмм и т.д.)")
 	topN := flag.Int("top", 10, "Количество топ-N n-грамм для вывода в консоль")
 	useStopwords := flag.Bool("stopwords", false, "Учитывать стоп-слова при формировании n-грамм")
+	langsFlag := flag.String("langs", "", "Языки, которые остаются в корпусе, через запятую (например, ru,uk)")
+	splitLangs := flag.Bool("split-langs", false, "Разложить корпус по языкам и обучать GloVe отдельно для каждого языка")
+	minConfidence := flag.Float64("lang-confidence", 0.5, "Минимальная уверенность определения языка; ниже текст считается текстом неизвестного языка")
+	writeJSONL := flag.Bool("jsonl", false, "Дополнительно сохранить корпус с метаданными в data/corpus.jsonl")
+	extractTextFlag := flag.Bool("extract-text", false, "Получить data/cleaned_corpus.txt из data/corpus.jsonl")
+	textColumn := flag.String("text-column", "", "Столбец CSV с текстом (по умолчанию — первый столбец)")
 	flag.Parse()
 
+	langs := parseList(*langsFlag)
+
 	// Если флаги не указаны, запустить полный pipeline
-	if !*cleanTextFlag && !*runGloveFlag && !*extractNGramsFlag {
-		fullPipeline(*n, *topN, *useStopwords)
+	if !*cleanTextFlag && !*runGloveFlag && !*extractNGramsFlag && !*extractTextFlag {
+		fullPipeline(*n, *topN, *useStopwords, langs, *splitLangs, *minConfidence, *writeJSONL, *textColumn)
 		return
 	}
 
 	// Запуск отдельных шагов
 	if *cleanTextFlag {
-		cleanText()
+		cleanText(langs, *splitLangs, *minConfidence, *writeJSONL, *textColumn)
+	}
+	if *extractTextFlag {
+		extractText()
 	}
 	if *runGloveFlag {
-		runGlove()
+		runGlove(langs, *splitLangs)
 	}
 	if *extractNGramsFlag {
-		extractNGrams(*n, *topN, *useStopwords)
+		extractNGrams(langs, *splitLangs, *n, *topN, *useStopwords)
+	}
+}
+
+// runCommand запускает подкоманду по имени
+func runCommand(name string, args []string) {
+	var err error
+	switch name {
+	case "shift":
+		err = runShift(args)
+	case "align":
+		err = runAlign(args)
+	case "analogy":
+		err = runAnalogy(args)
+	case "evaluate":
+		err = runEvaluate(args)
+	case "serve":
+		err = runServe(args)
+	case "export-synonyms":
+		err = runExportSynonyms(args)
+	case "cluster":
+		err = runCluster(args)
+	case "index":
+		err = runIndex(args)
+	case "search":
+		err = runSearch(args)
+	case "train":
+		err = runTrain(args)
+	case "classify":
+		err = runClassify(args)
+	case "lexicon":
+		err = runLexicon(args)
+	case "keywords":
+		err = runKeywords(args)
+	case "topics":
+		err = runTopics(args)
+	case "subword":
+		err = runSubword(args)
+	case "spell":
+		err = runSpell(args)
+	case "compress":
+		err = runCompress(args)
+	default:
+		fmt.Printf("Неизвестная команда: %s\n", name)
+		fmt.Println("Команды: shift, align, analogy, evaluate, serve, export-synonyms, cluster, index, search, train, classify, lexicon, keywords, topics, subword, spell, compress")
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Ошибка выполнения команды %s: %v", name, err)
 	}
 }
 
 // fullPipeline запускает полный pipeline
-func fullPipeline(n int, topN int, useStopwords bool) {
+func fullPipeline(n int, topN int, useStopwords bool, langs []string, splitLangs bool, minConfidence float64, writeJSONL bool, textColumn string) {
 	fmt.Println("Запуск полного pipeline...")
-	cleanText()
-	runGlove()
-	extractNGrams(n, topN, useStopwords)
+	cleanText(langs, splitLangs, minConfidence, writeJSONL, textColumn)
+	runGlove(langs, splitLangs)
+	extractNGrams(langs, splitLangs, n, topN, useStopwords)
+}
+
+// parseList разбирает список значений, перечисленных через запятую
+func parseList(value string) []string {
+	var langs []string
+	for _, lang := range strings.Split(value, ",") {
+		lang = strings.TrimSpace(lang)
+		if lang != "" {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
 }
 
 // cleanText выполняет очистку текста
-func cleanText() {
+func cleanText(langs []string, splitLangs bool, minConfidence float64, writeJSONL bool, textColumn string) {
 	fmt.Println("Шаг 1: Очистка текста...")
 	inputFile := "data/input.csv"
 	outputFile := "data/cleaned_corpus.txt"
 
-	err := textprocessor.ProcessCSV(inputFile, outputFile)
+	opts := textprocessor.Options{Languages: langs, MinConfidence: minConfidence, Columns: textprocessor.Columns{Text: textColumn}}
+	if splitLangs {
+		opts.SplitDir = languageDir
+	}
+	if writeJSONL {
+		opts.JSONLFile = "data/corpus.jsonl"
+	}
+	err := textprocessor.ProcessCSVWithOptions(inputFile, outputFile, opts)
 	if err != nil {
 		log.Fatalf("Ошибка при очистке текста: %v", err)
 	}
 }
 
-// runGlove запускает GloVe
-func runGlove() {
+// extractText получает текстовый корпус для GloVe из корпуса с метаданными
+func extractText() {
+	fmt.Println("Извлечение текста из корпуса с метаданными...")
+	inputFile := "data/corpus.jsonl"
+	outputFile := "data/cleaned_corpus.txt"
+
+	if err := corpus.ExtractText(inputFile, outputFile); err != nil {
+		log.Fatalf("Ошибка при извлечении текста: %v", err)
+	}
+	log.Printf("Текстовый корпус сохранен в файл %s\n", outputFile)
+}
+
+// runGlove запускает GloVe: для общего корпуса или отдельно для каждого языка
+func runGlove(langs []string, splitLangs bool) {
 	fmt.Println("Шаг 2: Запуск GloVe...")
-	err := glove.Run()
-	if err != nil {
-		log.Fatalf("Ошибка при запуске GloVe: %v", err)
+	if !splitLangs {
+		if err := glove.Run(); err != nil {
+			log.Fatalf("Ошибка при запуске GloVe: %v", err)
+		}
+		return
+	}
+
+	for _, lang := range splitLanguages(langs) {
+		cfg := glove.DirConfig(filepath.Join(languageDir, lang))
+		if _, err := os.Stat(cfg.InputFile); err != nil {
+			log.Printf("Корпус языка %s не найден, пропускаем", lang)
+			continue
+		}
+		fmt.Printf("Обучение GloVe для языка %s...\n", lang)
+		if err := glove.RunWithConfig(cfg); err != nil {
+			log.Fatalf("Ошибка при запуске GloVe для языка %s: %v", lang, err)
+		}
 	}
 }
 
-// extractNGrams извлекает n-граммы
-func extractNGrams(n int, topN int, useStopwords bool) {
+// splitLanguages возвращает языки, по которым раскладывается корпус: заданные
+// флагом -langs или все встроенные
+func splitLanguages(langs []string) []string {
+	if len(langs) == 0 {
+		return langid.BuiltinLanguages()
+	}
+	return langs
+}
+
+// extractNGrams извлекает n-граммы: из общего корпуса или, при разложении
+// по языкам, отдельно из файлов GloVe каждого языка в data/{lang}/
+func extractNGrams(langs []string, splitLangs bool, n int, topN int, useStopwords bool) {
 	fmt.Printf("Шаг 3: Извлечение %d-грамм...\n", n)
-	cooccurrenceFile := "data/cooccurrence.bin"
-	vocabFile := "data/vocab.txt"
+	if !splitLangs {
+		extractNGramsIn("data", n, topN, useStopwords)
+		return
+	}
+	for _, lang := range splitLanguages(langs) {
+		dir := filepath.Join(languageDir, lang)
+		cfg := glove.DirConfig(dir)
+		if _, err := os.Stat(cfg.CooccurrenceFile); err != nil {
+			log.Printf("Файл совместной встречаемости языка %s не найден, пропускаем", lang)
+			continue
+		}
+		fmt.Printf("Извлечение %d-грамм для языка %s...\n", n, lang)
+		extractNGramsIn(dir, n, topN, useStopwords)
+	}
+}
+
+// extractNGramsIn извлекает n-граммы по словарю и файлу совместной встречаемости
+// из каталога dir и сохраняет их туда же. Стоп-слова общие: data/stopwords.txt.
+func extractNGramsIn(dir string, n int, topN int, useStopwords bool) {
+	cfg := glove.DirConfig(dir)
 	stopwordsFile := "data/stopwords.txt"
-	topNGrams, allNGrams, err := ngrams.ExtractNGrams(cooccurrenceFile, vocabFile, stopwordsFile, n, topN, useStopwords)
+	topNGrams, allNGrams, err := ngrams.ExtractNGrams(cfg.CooccurrenceFile, cfg.VocabFile, stopwordsFile, n, topN, useStopwords)
 	if err != nil {
 		log.Fatalf("Ошибка при извлечении n-грамм: %v", err)
 	}
 
 	// Сохранение всех n-грамм в файл
-	outputFile := fmt.Sprintf("data/%d_grams.txt", n)
+	outputFile := filepath.Join(dir, fmt.Sprintf("%d_grams.txt", n))
 	err = ngrams.SaveNGrams(allNGrams, outputFile)
 	if err != nil {
 		log.Fatalf("Ошибка при сохранении n-грамм: %v", err)
@@ -0,0 +1,129 @@
+package ann
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+// randomVectors возвращает n случайных векторов размерности dim
+func randomVectors(n, dim int, seed int64) [][]float64 {
+	rng := rand.New(rand.NewSource(seed))
+	vecs := make([][]float64, n)
+	for i := range vecs {
+		vecs[i] = make([]float64, dim)
+		for j := range vecs[i] {
+			vecs[i][j] = rng.NormFloat64()
+		}
+	}
+	return vecs
+}
+
+// recall возвращает долю точных соседей, найденных приближённым графом
+func recall(approx, exact Graph) float64 {
+	found, total := 0, 0
+	for i := range exact {
+		ids := make(map[int]bool)
+		for _, r := range approx[i] {
+			ids[r.ID] = true
+		}
+		for _, r := range exact[i] {
+			if ids[r.ID] {
+				found++
+			}
+		}
+		total += len(exact[i])
+	}
+	return float64(found) / float64(total)
+}
+
+func TestKNNRecall(t *testing.T) {
+	tests := []struct {
+		name      string
+		n, dim, k int
+		cfg       Config
+		minRecall float64
+	}{
+		{"по умолчанию", 1000, 16, 10, DefaultConfig(), 0.95},
+		{"малое M", 1000, 16, 10, Config{M: 4, EfConstruction: 50, EfSearch: 50, Seed: 1}, 0.8},
+		{"большая размерность", 500, 64, 5, DefaultConfig(), 0.9},
+		{"меньше элементов, чем соседей", 5, 8, 10, DefaultConfig(), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vecs := randomVectors(tt.n, tt.dim, 42)
+			ix, err := Build(vecs, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			approx, err := KNN(ix, tt.k, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exact, err := ExactKNN(vecs, tt.k, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := recall(approx, exact); r < tt.minRecall {
+				t.Errorf("полнота %.3f меньше %.3f", r, tt.minRecall)
+			}
+			for i, neighbors := range exact {
+				if len(neighbors) != min(tt.k, tt.n-1) {
+					t.Fatalf("у элемента %d точных соседей %d, want %d", i, len(neighbors), min(tt.k, tt.n-1))
+				}
+				for j, r := range neighbors {
+					if r.ID == i {
+						t.Fatalf("элемент %d среди своих соседей", i)
+					}
+					if j > 0 && r.Similarity > neighbors[j-1].Similarity {
+						t.Fatalf("соседи элемента %d не упорядочены по сходству", i)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestKNNInvalidK(t *testing.T) {
+	vecs := randomVectors(10, 4, 1)
+	ix, err := Build(vecs, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []int{0, -1} {
+		if _, err := KNN(ix, k, 0); err == nil {
+			t.Errorf("KNN с k = %d должен вернуть ошибку", k)
+		}
+		if _, err := ExactKNN(vecs, k, 0); err == nil {
+			t.Errorf("ExactKNN с k = %d должен вернуть ошибку", k)
+		}
+	}
+}
+
+func TestSearchFindsItself(t *testing.T) {
+	vecs := randomVectors(300, 8, 7)
+	ix, err := Build(vecs, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.Add(make([]float64, 3)); err == nil {
+		t.Error("Add с другой размерностью должен вернуть ошибку")
+	}
+
+	filename := filepath.Join(t.TempDir(), "index.gob")
+	if err := ix.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, index := range map[string]*Index{"построенный": ix, "загруженный": loaded} {
+		for i, vec := range vecs {
+			results := index.Search(vec, 1)
+			if len(results) != 1 || results[0].ID != i {
+				t.Fatalf("%s индекс: поиск вектора %d вернул %v", name, i, results)
+			}
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package ann
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+)
+
+// Config задаёт параметры графа HNSW
+type Config struct {
+	M              int   // Число связей узла на уровне (на нулевом уровне — 2·M)
+	EfConstruction int   // Ширина поиска при построении
+	EfSearch       int   // Ширина поиска при запросах
+	Seed           int64 // Зерно генератора уровней (для воспроизводимости)
+}
+
+// DefaultConfig возвращает параметры, подходящие для словарей в сотни тысяч слов
+func DefaultConfig() Config {
+	return Config{M: 16, EfConstruction: 200, EfSearch: 100, Seed: 1}
+}
+
+// Result — найденный элемент индекса и его косинусное сходство с запросом
+type Result struct {
+	ID         int
+	Similarity float64
+}
+
+// Index — приближённый поиск ближайших соседей по косинусному сходству
+// (иерархический граф малого мира, HNSW). Безопасен для конкурентного поиска;
+// добавление элементов блокирует индекс.
+type Index struct {
+	cfg      Config
+	dim      int
+	vectors  [][]float64 // Нормированные векторы
+	links    [][][]int32 // links[узел][уровень] — соседи узла
+	entry    int
+	maxLevel int
+	rng      *rand.Rand
+	mu       sync.RWMutex
+}
+
+// New создаёт пустой индекс для векторов размерности dim
+func New(dim int, cfg Config) *Index {
+	def := DefaultConfig()
+	if cfg.M <= 0 {
+		cfg.M = def.M
+	}
+	if cfg.EfConstruction <= 0 {
+		cfg.EfConstruction = def.EfConstruction
+	}
+	if cfg.EfSearch <= 0 {
+		cfg.EfSearch = def.EfSearch
+	}
+	return &Index{cfg: cfg, dim: dim, entry: -1, rng: rand.New(rand.NewSource(cfg.Seed))}
+}
+
+// Len возвращает число элементов индекса
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.vectors)
+}
+
+// Dim возвращает размерность векторов индекса
+func (ix *Index) Dim() int {
+	return ix.dim
+}
+
+// SetEfSearch меняет ширину поиска при запросах (больше — точнее и медленнее)
+func (ix *Index) SetEfSearch(ef int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if ef > 0 {
+		ix.cfg.EfSearch = ef
+	}
+}
+
+// Add добавляет вектор в индекс и возвращает его номер (номера идут подряд с нуля)
+func (ix *Index) Add(vec []float64) (int, error) {
+	if len(vec) != ix.dim {
+		return 0, fmt.Errorf("размерность вектора %d не совпадает с размерностью индекса %d", len(vec), ix.dim)
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	id := len(ix.vectors)
+	ix.vectors = append(ix.vectors, vectors.Normalize(vec))
+
+	// Уровень узла выбирается по экспоненциальному распределению
+	level := int(-math.Log(1-ix.rng.Float64()) / math.Log(float64(ix.cfg.M)))
+	ix.links = append(ix.links, make([][]int32, level+1))
+
+	if ix.entry < 0 {
+		ix.entry = id
+		ix.maxLevel = level
+		return id, nil
+	}
+
+	query := ix.vectors[id]
+	current := ix.entry
+	for l := ix.maxLevel; l > level; l-- {
+		current = ix.greedy(query, current, l)
+	}
+
+	for l := min(level, ix.maxLevel); l >= 0; l-- {
+		candidates := ix.searchLayer(query, current, ix.cfg.EfConstruction, l)
+		maxLinks := ix.maxLinks(l)
+		neighbors := ix.selectNeighbors(candidates, ix.cfg.M)
+		ix.links[id][l] = neighbors
+		for _, n := range neighbors {
+			ix.links[n][l] = append(ix.links[n][l], int32(id))
+			if len(ix.links[n][l]) > maxLinks {
+				ix.shrink(int(n), l, maxLinks)
+			}
+		}
+		if len(candidates) > 0 {
+			current = candidates[0].ID
+		}
+	}
+
+	if level > ix.maxLevel {
+		ix.maxLevel = level
+		ix.entry = id
+	}
+	return id, nil
+}
+
+// Search находит k ближайших к запросу элементов (по убыванию сходства)
+func (ix *Index) Search(query []float64, k int) []Result {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	if ix.entry < 0 || k <= 0 || len(query) != ix.dim {
+		return nil
+	}
+
+	q := vectors.Normalize(query)
+	current := ix.entry
+	for l := ix.maxLevel; l > 0; l-- {
+		current = ix.greedy(q, current, l)
+	}
+	results := ix.searchLayer(q, current, max(ix.cfg.EfSearch, k), 0)
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Vector возвращает нормированный вектор элемента
+func (ix *Index) Vector(id int) []float64 {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.vectors[id]
+}
+
+func (ix *Index) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * ix.cfg.M
+	}
+	return ix.cfg.M
+}
+
+func (ix *Index) similarity(q []float64, id int) float64 {
+	var sum float64
+	v := ix.vectors[id]
+	for i := range q {
+		sum += q[i] * v[i]
+	}
+	return sum
+}
+
+// greedy спускается к ближайшему узлу на уровне level
+func (ix *Index) greedy(q []float64, start, level int) int {
+	current := start
+	best := ix.similarity(q, current)
+	for changed := true; changed; {
+		changed = false
+		for _, n := range ix.links[current][level] {
+			if sim := ix.similarity(q, int(n)); sim > best {
+				best, current, changed = sim, int(n), true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer ищет ef ближайших узлов на уровне level, начиная с узла start;
+// результат отсортирован по убыванию сходства
+func (ix *Index) searchLayer(q []float64, start, ef, level int) []Result {
+	visited := ix.visited()
+	defer visitedPool.Put(visited)
+	visited.visit(start)
+	startSim := ix.similarity(q, start)
+	candidates := &maxHeap{{ID: start, Similarity: startSim}}
+	found := &minHeap{{ID: start, Similarity: startSim}}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(Result)
+		if found.Len() >= ef && c.Similarity < (*found)[0].Similarity {
+			break
+		}
+		if level >= len(ix.links[c.ID]) {
+			continue
+		}
+		for _, n := range ix.links[c.ID][level] {
+			id := int(n)
+			if !visited.visit(id) {
+				continue
+			}
+			sim := ix.similarity(q, id)
+			if found.Len() < ef || sim > (*found)[0].Similarity {
+				heap.Push(candidates, Result{ID: id, Similarity: sim})
+				heap.Push(found, Result{ID: id, Similarity: sim})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	results := make([]Result, found.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(found).(Result)
+	}
+	return results
+}
+
+// selectNeighbors выбирает до m соседей эвристикой HNSW: кандидат добавляется,
+// если он ближе к узлу, чем к любому уже выбранному соседу (это сохраняет связность графа)
+func (ix *Index) selectNeighbors(candidates []Result, m int) []int32 {
+	selected := make([]int32, 0, m)
+	var skipped []int32
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		good := true
+		for _, s := range selected {
+			if ix.similarity(ix.vectors[c.ID], int(s)) > c.Similarity {
+				good = false
+				break
+			}
+		}
+		if good {
+			selected = append(selected, int32(c.ID))
+		} else {
+			skipped = append(skipped, int32(c.ID))
+		}
+	}
+	// Оставшиеся места заполняются отброшенными кандидатами
+	for _, s := range skipped {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, s)
+	}
+	return selected
+}
+
+// shrink сокращает список соседей узла до maxLinks
+func (ix *Index) shrink(id, level, maxLinks int) {
+	q := ix.vectors[id]
+	links := ix.links[id][level]
+	candidates := make([]Result, len(links))
+	for i, n := range links {
+		candidates[i] = Result{ID: int(n), Similarity: ix.similarity(q, int(n))}
+	}
+	sortResults(candidates)
+	ix.links[id][level] = ix.selectNeighbors(candidates, maxLinks)
+}
+
+// snapshot — сериализуемое состояние индекса
+type snapshot struct {
+	Config   Config
+	Dim      int
+	Vectors  [][]float64
+	Links    [][][]int32
+	Entry    int
+	MaxLevel int
+}
+
+// Save сохраняет индекс в файл
+func (ix *Index) Save(filename string) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла индекса: %v", err)
+	}
+	defer file.Close()
+
+	s := snapshot{Config: ix.cfg, Dim: ix.dim, Vectors: ix.vectors, Links: ix.links, Entry: ix.entry, MaxLevel: ix.maxLevel}
+	if err := gob.NewEncoder(file).Encode(&s); err != nil {
+		return fmt.Errorf("ошибка при записи индекса: %v", err)
+	}
+	return nil
+}
+
+// Load загружает индекс из файла
+func Load(filename string) (*Index, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла индекса: %v", err)
+	}
+	defer file.Close()
+
+	var s snapshot
+	if err := gob.NewDecoder(file).Decode(&s); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении индекса: %v", err)
+	}
+	ix := New(s.Dim, s.Config)
+	ix.vectors = s.Vectors
+	ix.links = s.Links
+	ix.entry = s.Entry
+	ix.maxLevel = s.MaxLevel
+	// Продолжаем последовательность уровней так, чтобы дозагрузка была воспроизводимой
+	ix.rng = rand.New(rand.NewSource(s.Config.Seed + int64(len(s.Vectors))))
+	return ix, nil
+}
+
+// visitedSet отмечает просмотренные узлы; метки сбрасываются сменой эпохи, а не очисткой
+type visitedSet struct {
+	marks []uint32
+	epoch uint32
+}
+
+var visitedPool = sync.Pool{New: func() interface{} { return &visitedSet{} }}
+
+// visited возвращает пустое множество просмотренных узлов из пула
+func (ix *Index) visited() *visitedSet {
+	v := visitedPool.Get().(*visitedSet)
+	if len(v.marks) < len(ix.vectors) {
+		v.marks = append(v.marks, make([]uint32, len(ix.vectors)-len(v.marks)+1024)...)
+	}
+	v.epoch++
+	if v.epoch == 0 {
+		clear(v.marks)
+		v.epoch = 1
+	}
+	return v
+}
+
+// visit отмечает узел и сообщает, был ли он ещё не просмотрен
+func (v *visitedSet) visit(id int) bool {
+	if v.marks[id] == v.epoch {
+		return false
+	}
+	v.marks[id] = v.epoch
+	return true
+}
@@ -0,0 +1,159 @@
+package ann
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"sort"
+)
+
+// Build строит индекс по набору векторов (номера элементов совпадают с номерами векторов)
+func Build(vecs [][]float64, cfg Config) (*Index, error) {
+	dim := 0
+	if len(vecs) > 0 {
+		dim = len(vecs[0])
+	}
+	ix := New(dim, cfg)
+	for _, vec := range vecs {
+		if _, err := ix.Add(vec); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// Graph — граф k ближайших соседей: Graph[i] — соседи элемента i по убыванию сходства
+type Graph [][]Result
+
+// KNN строит граф k ближайших соседей по индексу. Соседи со сходством ниже
+// threshold отбрасываются; сам элемент в список своих соседей не входит.
+// Запросы выполняются параллельно, результат детерминирован.
+func KNN(ix *Index, k int, threshold float64) (Graph, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("число соседей должно быть положительным: %d", k)
+	}
+	n := ix.Len()
+	graph := make(Graph, n)
+	parallel.For(n, func(i int) {
+		results := ix.Search(ix.Vector(i), k+1)
+		graph[i] = filterResults(results, i, k, threshold)
+	})
+	return graph, nil
+}
+
+// ExactKNN строит точный граф k ближайших соседей полным перебором.
+// Подходит для словарей до нескольких десятков тысяч слов.
+func ExactKNN(vecs [][]float64, k int, threshold float64) (Graph, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("число соседей должно быть положительным: %d", k)
+	}
+	normed := make([][]float64, len(vecs))
+	for i, vec := range vecs {
+		normed[i] = vectors.Normalize(vec)
+	}
+	graph := make(Graph, len(vecs))
+	parallel.For(len(vecs), func(i int) {
+		var top []Result
+		for j, vec := range normed {
+			if j == i {
+				continue
+			}
+			var sim float64
+			for d := range vec {
+				sim += normed[i][d] * vec[d]
+			}
+			if sim < threshold || (len(top) == k && sim <= top[k-1].Similarity) {
+				continue
+			}
+			top = insertResult(top, Result{ID: j, Similarity: sim}, k)
+		}
+		graph[i] = top
+	})
+	return graph, nil
+}
+
+// Mutual оставляет только взаимных соседей: j остаётся в списке i, только если i есть в списке j
+func (g Graph) Mutual() Graph {
+	neighbors := make([]map[int]bool, len(g))
+	for i, list := range g {
+		neighbors[i] = make(map[int]bool, len(list))
+		for _, r := range list {
+			neighbors[i][r.ID] = true
+		}
+	}
+	result := make(Graph, len(g))
+	for i, list := range g {
+		for _, r := range list {
+			if neighbors[r.ID][i] {
+				result[i] = append(result[i], r)
+			}
+		}
+	}
+	return result
+}
+
+// filterResults убирает сам элемент и слабых соседей и ограничивает список k элементами
+func filterResults(results []Result, self, k int, threshold float64) []Result {
+	var filtered []Result
+	for _, r := range results {
+		if r.ID == self || r.Similarity < threshold {
+			continue
+		}
+		filtered = append(filtered, r)
+		if len(filtered) == k {
+			break
+		}
+	}
+	return filtered
+}
+
+// insertResult вставляет результат в отсортированный по убыванию сходства список длиной не более limit
+func insertResult(list []Result, r Result, limit int) []Result {
+	pos := sort.Search(len(list), func(i int) bool { return list[i].Similarity < r.Similarity })
+	if len(list) < limit {
+		list = append(list, Result{})
+	} else if pos >= limit {
+		return list
+	}
+	copy(list[pos+1:], list[pos:])
+	list[pos] = r
+	return list
+}
+
+// sortResults сортирует результаты по убыванию сходства (при равенстве — по номеру)
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Similarity != results[j].Similarity {
+			return results[i].Similarity > results[j].Similarity
+		}
+		return results[i].ID < results[j].ID
+	})
+}
+
+// maxHeap — кандидаты на просмотр, ближайший сверху
+type maxHeap []Result
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].Similarity > h[j].Similarity }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(Result)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// minHeap — найденные элементы, самый дальний сверху
+type minHeap []Result
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].Similarity < h[j].Similarity }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(Result)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
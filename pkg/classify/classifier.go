@@ -0,0 +1,225 @@
+package classify
+
+import (
+	"encoding/json"
+	"fmt"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Embedding — параметры построения векторов документов, с которыми обучен классификатор
+type Embedding struct {
+	VectorsFile string           `json:"vectors_file"`
+	VocabFile   string           `json:"vocab_file,omitempty"`
+	Weighting   docvec.Weighting `json:"weighting"`
+	A           float64          `json:"a"`
+	Component   []float64        `json:"component,omitempty"` // Вычитаемая главная компонента
+}
+
+// Embedder создаёт построитель векторов документов с этими параметрами
+func (e Embedding) Embedder(model *vectors.Model) (*docvec.Embedder, error) {
+	embedder, err := docvec.Load(model, e.VocabFile, docvec.Options{Weighting: e.Weighting, A: e.A})
+	if err != nil {
+		return nil, err
+	}
+	embedder.SetComponent(e.Component)
+	return embedder, nil
+}
+
+// Classifier — классификатор текстов: модель softmax вместе с параметрами векторов документов
+type Classifier struct {
+	Model
+	Embedding Embedding `json:"embedding"`
+	History   *History  `json:"history,omitempty"`
+	embedder  *docvec.Embedder
+}
+
+// Prediction — результат классификации текста
+type Prediction struct {
+	Label         string             `json:"label"`
+	Probability   float64            `json:"probability"`
+	Probabilities map[string]float64 `json:"probabilities"`
+}
+
+// Example — размеченный документ
+type Example struct {
+	ID    string
+	Text  string // Очищенный текст
+	Label string
+}
+
+// LoadExamples читает размеченные документы из CSV (текст очищается) или JSONL с полем label.
+// Документы без метки пропускаются.
+func LoadExamples(filename string, columns textprocessor.Columns) ([]Example, error) {
+	reader, err := textprocessor.OpenDocuments(filename, columns)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var examples []Example
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if doc.Label == "" {
+			continue
+		}
+		examples = append(examples, Example{ID: doc.ID, Text: doc.Text, Label: doc.Label})
+	}
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("в файле %s нет размеченных документов", filename)
+	}
+	return examples, nil
+}
+
+// Dataset — векторы примеров и номера их меток
+type Dataset struct {
+	Labels   []string
+	X        [][]float64
+	Y        []int
+	Examples []Example // Примеры, для которых построен вектор (в порядке X)
+	Coverage docvec.Summary
+}
+
+// Labels возвращает различные метки примеров в алфавитном порядке — в порядке их номеров в модели
+func Labels(examples []Example) []string {
+	seen := make(map[string]bool)
+	var labels []string
+	for _, e := range examples {
+		if !seen[e.Label] {
+			seen[e.Label] = true
+			labels = append(labels, e.Label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// NewDataset строит векторы примеров; метки нумеруются в алфавитном порядке.
+// Примеры без единого слова из модели пропускаются.
+func NewDataset(examples []Example, embedder *docvec.Embedder) *Dataset {
+	ds := &Dataset{Labels: Labels(examples)}
+	index := make(map[string]int, len(ds.Labels))
+	for i, label := range ds.Labels {
+		index[label] = i
+	}
+	for _, e := range examples {
+		vec, stats := embedder.EmbedText(e.Text)
+		ds.Coverage.Add(stats)
+		if vec == nil {
+			continue
+		}
+		ds.X = append(ds.X, vec)
+		ds.Y = append(ds.Y, index[e.Label])
+		ds.Examples = append(ds.Examples, e)
+	}
+	return ds
+}
+
+// TrainText обучает классификатор на размеченных текстах. Если removePC,
+// главная компонента векторов вычисляется по обучающим текстам и сохраняется
+// в классификаторе, чтобы новые тексты преобразовывались так же.
+func TrainText(model *vectors.Model, examples []Example, embedding Embedding, removePC bool, opts Options) (*Classifier, *Dataset, error) {
+	embedder, err := embedding.Embedder(model)
+	if err != nil {
+		return nil, nil, err
+	}
+	if removePC {
+		texts := make([][]string, len(examples))
+		for i, e := range examples {
+			texts[i] = strings.Fields(e.Text)
+		}
+		if err := embedder.Fit(texts); err != nil {
+			return nil, nil, err
+		}
+		embedding.Component = embedder.Component()
+	}
+
+	ds := NewDataset(examples, embedder)
+	if len(ds.X) == 0 {
+		return nil, nil, fmt.Errorf("ни в одном примере нет слов из векторов")
+	}
+	m, history, err := Train(ds.X, ds.Y, ds.Labels, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Classifier{Model: *m, Embedding: embedding, History: history, embedder: embedder}, ds, nil
+}
+
+// Classify относит очищенный текст к классу. Если ни одного слова нет в модели,
+// ok равно false.
+func (c *Classifier) Classify(text string) (p Prediction, stats docvec.Stats, ok bool) {
+	vec, stats := c.embedder.EmbedText(text)
+	if vec == nil {
+		return Prediction{}, stats, false
+	}
+	return c.PredictVector(vec), stats, true
+}
+
+// PredictVector классифицирует готовый вектор документа
+func (c *Classifier) PredictVector(vec []float64) Prediction {
+	probs := c.Probabilities(vec)
+	p := Prediction{Probabilities: make(map[string]float64, len(probs))}
+	for i, prob := range probs {
+		p.Probabilities[c.Labels[i]] = prob
+		if prob > p.Probability {
+			p.Label, p.Probability = c.Labels[i], prob
+		}
+	}
+	return p
+}
+
+// Embedder возвращает построитель векторов документов классификатора
+func (c *Classifier) Embedder() *docvec.Embedder {
+	return c.embedder
+}
+
+// Save сохраняет классификатор в формате JSON
+func (c *Classifier) Save(filename string) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи классификатора: %v", err)
+	}
+	return nil
+}
+
+// Load загружает классификатор. Если model равна nil, загружается модель векторов,
+// на которой классификатор обучался.
+func Load(filename string, model *vectors.Model) (*Classifier, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении классификатора: %v", err)
+	}
+	c := &Classifier{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе классификатора: %v", err)
+	}
+	if len(c.Labels) == 0 || len(c.Weights) != len(c.Labels) || len(c.Bias) != len(c.Labels) {
+		return nil, fmt.Errorf("файл %s не содержит корректной модели классификатора", filename)
+	}
+	if model == nil {
+		if model, err = vectors.Load(c.Embedding.VectorsFile); err != nil {
+			return nil, err
+		}
+	}
+	if model.Dim() != len(c.Weights[0]) {
+		return nil, fmt.Errorf("размерность модели %d не совпадает с размерностью классификатора %d", model.Dim(), len(c.Weights[0]))
+	}
+	if c.embedder, err = c.Embedding.Embedder(model); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,334 @@
+package classify
+
+import (
+	"encoding/json"
+	"fmt"
+	"glove-pipeline/pkg/textprocessor"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ClassMetrics — точность, полнота и F1 одного класса
+type ClassMetrics struct {
+	Label     string  `json:"label"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int     `json:"support"` // Число примеров класса
+}
+
+// Average — усреднённые точность, полнота и F1
+type Average struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+}
+
+// CalibrationBin — интервал уверенности модели и доля верных ответов в нём
+type CalibrationBin struct {
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	Count      int     `json:"count"`
+	Confidence float64 `json:"confidence"` // Средняя вероятность предсказанного класса
+	Accuracy   float64 `json:"accuracy"`   // Доля верных предсказаний
+}
+
+// Report — качество классификатора на размеченных примерах
+type Report struct {
+	Labels      []string         `json:"labels"`
+	Examples    int              `json:"examples"`
+	Folds       int              `json:"folds,omitempty"`
+	Accuracy    float64          `json:"accuracy"`
+	LogLoss     float64          `json:"log_loss"`
+	Classes     []ClassMetrics   `json:"classes"`
+	Macro       Average          `json:"macro"`
+	Micro       Average          `json:"micro"`
+	Weighted    Average          `json:"weighted"`
+	ROCAUC      *float64         `json:"roc_auc,omitempty"` // Только для двух классов; положительный — второй
+	Calibration []CalibrationBin `json:"calibration"`
+	ECE         float64          `json:"ece"`       // Ожидаемая ошибка калибровки
+	Confusion   [][]int          `json:"confusion"` // Confusion[истинный][предсказанный]
+}
+
+// Evaluate считает метрики по истинным меткам y и вероятностям классов probs.
+// Уверенность для калибровки — вероятность предсказанного класса, bins
+// интервалов равной ширины.
+func Evaluate(labels []string, y []int, probs [][]float64, bins int) *Report {
+	k := len(labels)
+	if bins <= 0 {
+		bins = 10
+	}
+	r := &Report{Labels: labels, Examples: len(y), Confusion: make([][]int, k)}
+	for c := range r.Confusion {
+		r.Confusion[c] = make([]int, k)
+	}
+	calibration := make([]CalibrationBin, bins)
+	for b := range calibration {
+		calibration[b].Lower = float64(b) / float64(bins)
+		calibration[b].Upper = float64(b+1) / float64(bins)
+	}
+
+	correct := 0
+	for i, p := range probs {
+		pred := 0
+		for c := range p {
+			if p[c] > p[pred] {
+				pred = c
+			}
+		}
+		r.Confusion[y[i]][pred]++
+		r.LogLoss -= math.Log(math.Max(p[y[i]], 1e-15))
+		b := min(int(p[pred]*float64(bins)), bins-1)
+		calibration[b].Count++
+		calibration[b].Confidence += p[pred]
+		if pred == y[i] {
+			correct++
+			calibration[b].Accuracy++
+		}
+	}
+	if len(y) == 0 {
+		return r
+	}
+	n := float64(len(y))
+	r.Accuracy = float64(correct) / n
+	r.LogLoss /= n
+
+	for _, bin := range calibration {
+		if bin.Count == 0 {
+			continue
+		}
+		bin.Confidence /= float64(bin.Count)
+		bin.Accuracy /= float64(bin.Count)
+		r.ECE += float64(bin.Count) / n * math.Abs(bin.Accuracy-bin.Confidence)
+		r.Calibration = append(r.Calibration, bin)
+	}
+
+	// Микроусреднение по всем примерам однозначной классификации совпадает с точностью
+	r.Micro = Average{Precision: r.Accuracy, Recall: r.Accuracy, F1: r.Accuracy}
+	present := 0
+	for c := 0; c < k; c++ {
+		var tp, predicted, support int
+		for other := 0; other < k; other++ {
+			predicted += r.Confusion[other][c]
+			support += r.Confusion[c][other]
+		}
+		tp = r.Confusion[c][c]
+		m := ClassMetrics{Label: labels[c], Support: support}
+		if predicted > 0 {
+			m.Precision = float64(tp) / float64(predicted)
+		}
+		if support > 0 {
+			m.Recall = float64(tp) / float64(support)
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		r.Classes = append(r.Classes, m)
+
+		// Классы без примеров не участвуют в макроусреднении
+		if support == 0 {
+			continue
+		}
+		present++
+		r.Macro.Precision += m.Precision
+		r.Macro.Recall += m.Recall
+		r.Macro.F1 += m.F1
+		w := float64(support) / n
+		r.Weighted.Precision += w * m.Precision
+		r.Weighted.Recall += w * m.Recall
+		r.Weighted.F1 += w * m.F1
+	}
+	if present > 0 {
+		r.Macro.Precision /= float64(present)
+		r.Macro.Recall /= float64(present)
+		r.Macro.F1 /= float64(present)
+	}
+
+	if k == 2 {
+		scores := make([]float64, len(probs))
+		for i, p := range probs {
+			scores[i] = p[1]
+		}
+		if auc, ok := rocAUC(scores, y); ok {
+			r.ROCAUC = &auc
+		}
+	}
+	return r
+}
+
+// rocAUC вычисляет площадь под ROC-кривой как статистику Манна — Уитни
+// (при равных оценках берётся средний ранг). Положительный класс — 1.
+func rocAUC(scores []float64, y []int) (float64, bool) {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return scores[order[a]] < scores[order[b]] })
+
+	var positives, negatives int
+	var rankSum float64
+	for start := 0; start < len(order); {
+		end := start
+		for end < len(order) && scores[order[end]] == scores[order[start]] {
+			end++
+		}
+		rank := float64(start+end+1) / 2 // Средний ранг группы равных оценок (ранги с единицы)
+		for _, i := range order[start:end] {
+			if y[i] == 1 {
+				positives++
+				rankSum += rank
+			} else {
+				negatives++
+			}
+		}
+		start = end
+	}
+	if positives == 0 || negatives == 0 {
+		return 0, false
+	}
+	p := float64(positives)
+	return (rankSum - p*(p+1)/2) / (p * float64(negatives)), true
+}
+
+// Folds делит примеры на k частей с сохранением пропорций классов и возвращает
+// номер части для каждого примера
+func Folds(y []int, k int, seed int64) []int {
+	byClass := make(map[int][]int)
+	for i, label := range y {
+		byClass[label] = append(byClass[label], i)
+	}
+	classes := make([]int, 0, len(byClass))
+	for c := range byClass {
+		classes = append(classes, c)
+	}
+	sort.Ints(classes)
+
+	rng := rand.New(rand.NewSource(seed))
+	fold := make([]int, len(y))
+	next := 0 // Части продолжают нумероваться между классами, чтобы малые классы не попадали все в первую
+	for _, c := range classes {
+		idx := byClass[c]
+		rng.Shuffle(len(idx), func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+		for _, i := range idx {
+			fold[i] = next % k
+			next++
+		}
+	}
+	return fold
+}
+
+// CrossValidate проводит стратифицированную k-кратную перекрёстную проверку:
+// модель обучается k раз без одной из частей, и метрики считаются по
+// предсказаниям для отложенных примеров. Части обучаются параллельно.
+func CrossValidate(x [][]float64, y []int, labels []string, k int, opts Options, bins int) (*Report, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("число частей перекрёстной проверки должно быть не меньше 2")
+	}
+	if k > len(y) {
+		return nil, fmt.Errorf("частей (%d) больше, чем примеров (%d)", k, len(y))
+	}
+	fold := Folds(y, k, opts.Seed)
+	probs := make([][]float64, len(y))
+	errs := make([]error, k)
+
+	var wg sync.WaitGroup
+	for f := 0; f < k; f++ {
+		wg.Add(1)
+		go func(f int) {
+			defer wg.Done()
+			var trainX [][]float64
+			var trainY []int
+			for i := range y {
+				if fold[i] != f {
+					trainX = append(trainX, x[i])
+					trainY = append(trainY, y[i])
+				}
+			}
+			m, _, err := Train(trainX, trainY, labels, opts)
+			if err != nil {
+				errs[f] = fmt.Errorf("часть %d: %v", f+1, err)
+				return
+			}
+			for i := range y {
+				if fold[i] == f {
+					probs[i] = m.Probabilities(x[i])
+				}
+			}
+		}(f)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := Evaluate(labels, y, probs, bins)
+	r.Folds = k
+	return r, nil
+}
+
+// SaveJSON сохраняет отчёт в формате JSON
+func (r *Report) SaveJSON(filename string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи отчёта: %v", err)
+	}
+	return nil
+}
+
+// Print выводит метрики, калибровку и матрицу ошибок
+func (r *Report) Print(w io.Writer) {
+	if r.Folds > 0 {
+		fmt.Fprintf(w, "Перекрёстная проверка: %d частей, %d примеров\n", r.Folds, r.Examples)
+	} else {
+		fmt.Fprintf(w, "Примеров: %d\n", r.Examples)
+	}
+	fmt.Fprintf(w, "Точность (accuracy): %.4f, log loss: %.4f", r.Accuracy, r.LogLoss)
+	if r.ROCAUC != nil {
+		fmt.Fprintf(w, ", ROC-AUC: %.4f", *r.ROCAUC)
+	}
+	fmt.Fprintln(w)
+
+	width := 12
+	for _, label := range r.Labels {
+		width = max(width, len([]rune(label)))
+	}
+	fmt.Fprintf(w, "\n%-*s %10s %10s %10s %8s\n", width, "Класс", "Precision", "Recall", "F1", "Примеров")
+	for _, m := range r.Classes {
+		fmt.Fprintf(w, "%-*s %10.4f %10.4f %10.4f %8d\n", width, m.Label, m.Precision, m.Recall, m.F1, m.Support)
+	}
+	for _, avg := range []struct {
+		name string
+		a    Average
+	}{{"macro", r.Macro}, {"micro", r.Micro}, {"weighted", r.Weighted}} {
+		fmt.Fprintf(w, "%-*s %10.4f %10.4f %10.4f\n", width, avg.name, avg.a.Precision, avg.a.Recall, avg.a.F1)
+	}
+
+	fmt.Fprintf(w, "\nКалибровка (ECE %.4f):\n", r.ECE)
+	fmt.Fprintf(w, "%-12s %8s %12s %10s\n", "Уверенность", "Примеров", "Средняя", "Точность")
+	for _, b := range r.Calibration {
+		fmt.Fprintf(w, "%.1f–%.1f      %8d %12.4f %10.4f\n", b.Lower, b.Upper, b.Count, b.Confidence, b.Accuracy)
+	}
+
+	fmt.Fprintln(w, "\nМатрица ошибок (строки — истинный класс, столбцы — предсказанный):")
+	fmt.Fprintf(w, "%-*s", width, "")
+	for _, label := range r.Labels {
+		fmt.Fprintf(w, " %8s", textprocessor.Truncate(label, 8))
+	}
+	fmt.Fprintln(w)
+	for c, row := range r.Confusion {
+		fmt.Fprintf(w, "%-*s", width, r.Labels[c])
+		for _, v := range row {
+			fmt.Fprintf(w, " %8d", v)
+		}
+		fmt.Fprintln(w)
+	}
+}
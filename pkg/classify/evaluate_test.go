@@ -0,0 +1,138 @@
+package classify
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	y := []int{0, 0, 1, 1}
+	probs := [][]float64{{0.9, 0.1}, {0.4, 0.6}, {0.2, 0.8}, {0.3, 0.7}}
+	r := Evaluate([]string{"a", "b"}, y, probs, 10)
+
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"accuracy", r.Accuracy, 0.75},
+		{"log loss", r.LogLoss, -(math.Log(0.9) + math.Log(0.4) + math.Log(0.8) + math.Log(0.7)) / 4},
+		{"precision a", r.Classes[0].Precision, 1},
+		{"recall a", r.Classes[0].Recall, 0.5},
+		{"f1 a", r.Classes[0].F1, 2.0 / 3},
+		{"precision b", r.Classes[1].Precision, 2.0 / 3},
+		{"recall b", r.Classes[1].Recall, 1},
+		{"f1 b", r.Classes[1].F1, 0.8},
+		{"macro precision", r.Macro.Precision, 5.0 / 6},
+		{"macro f1", r.Macro.F1, (2.0/3 + 0.8) / 2},
+		{"micro f1", r.Micro.F1, 0.75},
+		{"roc auc", *r.ROCAUC, 1},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-12 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	want := [][]int{{1, 1}, {0, 2}}
+	for i := range want {
+		for j := range want[i] {
+			if r.Confusion[i][j] != want[i][j] {
+				t.Fatalf("матрица ошибок %v, want %v", r.Confusion, want)
+			}
+		}
+	}
+	count := 0
+	for _, bin := range r.Calibration {
+		count += bin.Count
+	}
+	if count != len(y) {
+		t.Errorf("в интервалах калибровки %d примеров, want %d", count, len(y))
+	}
+}
+
+func TestROCAUC(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		y      []int
+		want   float64
+		ok     bool
+	}{
+		{"идеальное разделение", []float64{0.1, 0.2, 0.8, 0.9}, []int{0, 0, 1, 1}, 1, true},
+		{"обратное разделение", []float64{0.9, 0.8, 0.2, 0.1}, []int{0, 0, 1, 1}, 0, true},
+		{"равные оценки", []float64{0.5, 0.5, 0.5, 0.5}, []int{0, 1, 0, 1}, 0.5, true},
+		{"одна ошибка из четырёх пар", []float64{0.1, 0.6, 0.5, 0.9}, []int{0, 0, 1, 1}, 0.75, true},
+		{"только один класс", []float64{0.1, 0.9}, []int{1, 1}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := rocAUC(tt.scores, tt.y)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: rocAUC = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFolds(t *testing.T) {
+	y := []int{0, 0, 0, 0, 0, 0, 1, 1, 1, 2, 2, 2}
+	tests := []struct {
+		k       int
+		maxDiff int // Наибольшая разница размеров частей
+	}{
+		{2, 0},
+		{3, 0},
+		{5, 1},
+	}
+	for _, tt := range tests {
+		fold := Folds(y, tt.k, 1)
+		sizes := make([]int, tt.k)
+		perClass := make(map[[2]int]int)
+		for i, f := range fold {
+			if f < 0 || f >= tt.k {
+				t.Fatalf("k = %d: часть %d вне диапазона", tt.k, f)
+			}
+			sizes[f]++
+			perClass[[2]int{y[i], f}]++
+		}
+		lo, hi := sizes[0], sizes[0]
+		for _, s := range sizes {
+			lo, hi = min(lo, s), max(hi, s)
+		}
+		if hi-lo > tt.maxDiff {
+			t.Errorf("k = %d: размеры частей %v", tt.k, sizes)
+		}
+		// Пример класса 0 есть в каждой части, когда их не больше шести
+		for f := 0; f < tt.k; f++ {
+			if perClass[[2]int{0, f}] == 0 {
+				t.Errorf("k = %d: в части %d нет класса 0", tt.k, f)
+			}
+		}
+	}
+}
+
+func TestCrossValidate(t *testing.T) {
+	x, y := blobs([][]float64{{1, 0}, {0, 1}}, 20, 0.1, 1)
+	opts := DefaultOptions()
+	opts.Epochs = 50
+	r, err := CrossValidate(x, y, []string{"очень длинная метка", "b"}, 4, opts, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Folds != 4 || r.Examples != len(y) {
+		t.Errorf("частей %d, примеров %d, want 4, %d", r.Folds, r.Examples, len(y))
+	}
+	if r.Accuracy < 0.95 {
+		t.Errorf("точность перекрёстной проверки %.3f", r.Accuracy)
+	}
+	var out bytes.Buffer
+	r.Print(&out)
+	if !strings.Contains(out.String(), "очень…") {
+		t.Errorf("длинная метка не обрезана в матрице ошибок:\n%s", out.String())
+	}
+
+	for _, k := range []int{1, len(y) + 1} {
+		if _, err := CrossValidate(x, y, []string{"a", "b"}, k, opts, 10); err == nil {
+			t.Errorf("CrossValidate с k = %d должен вернуть ошибку", k)
+		}
+	}
+}
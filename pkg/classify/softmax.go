@@ -0,0 +1,352 @@
+package classify
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Model — полиномиальная логистическая регрессия (softmax) над векторами документов
+type Model struct {
+	Labels  []string    `json:"labels"`
+	Weights [][]float64 `json:"weights"` // Weights[класс][признак]
+	Bias    []float64   `json:"bias"`
+}
+
+// Options задаёт параметры обучения
+type Options struct {
+	Epochs       int       // Максимальное число эпох
+	BatchSize    int       // Размер мини-пакета
+	LearningRate float64   // Шаг оптимизатора Adam
+	L2           float64   // Коэффициент L2-регуляризации весов (смещения не регуляризуются)
+	Balanced     bool      // Веса классов обратно пропорциональны их частоте
+	ClassWeights []float64 // Явные веса классов (по номерам меток); важнее Balanced
+	Validation   float64   // Доля примеров для ранней остановки (0 — без валидации)
+	Patience     int       // Сколько эпох без улучшения потерь на валидации ждать до остановки
+	Seed         int64     // Зерно перемешивания и разбиения
+}
+
+// DefaultOptions возвращает параметры обучения по умолчанию
+func DefaultOptions() Options {
+	return Options{Epochs: 200, BatchSize: 32, LearningRate: 0.01, L2: 1e-4, Validation: 0.1, Patience: 10, Seed: 1}
+}
+
+// Epoch — потери и точность после одной эпохи
+type Epoch struct {
+	Epoch       int     `json:"epoch"`
+	TrainLoss   float64 `json:"train_loss"`
+	ValLoss     float64 `json:"val_loss,omitempty"`
+	ValAccuracy float64 `json:"val_accuracy,omitempty"`
+}
+
+// History — ход обучения; Best — эпоха, веса которой сохранены в модели
+type History struct {
+	Epochs  []Epoch `json:"epochs"`
+	Best    int     `json:"best"`
+	Stopped bool    `json:"stopped"` // Обучение остановлено досрочно
+}
+
+// Train обучает модель на векторах x с метками y (номерами в labels)
+// мини-пакетным градиентным спуском с оптимизатором Adam. Функция потерь —
+// взвешенная перекрёстная энтропия с L2-регуляризацией. Если задана
+// валидационная доля, она отделяется с сохранением пропорций классов,
+// и возвращаются веса эпохи с наименьшими потерями на валидации.
+func Train(x [][]float64, y []int, labels []string, opts Options) (*Model, *History, error) {
+	if len(x) == 0 || len(x) != len(y) {
+		return nil, nil, fmt.Errorf("нет обучающих примеров или число меток не совпадает с числом векторов")
+	}
+	k := len(labels)
+	if k < 2 {
+		return nil, nil, fmt.Errorf("для классификации нужно хотя бы два класса, найдено %d", k)
+	}
+	for _, label := range y {
+		if label < 0 || label >= k {
+			return nil, nil, fmt.Errorf("метка %d вне диапазона 0..%d", label, k-1)
+		}
+	}
+	def := DefaultOptions()
+	if opts.Epochs <= 0 {
+		opts.Epochs = def.Epochs
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = def.BatchSize
+	}
+	if opts.LearningRate <= 0 {
+		opts.LearningRate = def.LearningRate
+	}
+	if opts.Patience <= 0 {
+		opts.Patience = def.Patience
+	}
+
+	train, val := Split(y, opts.Validation, opts.Seed)
+	if len(train) == 0 {
+		return nil, nil, fmt.Errorf("после отделения валидации не осталось обучающих примеров")
+	}
+	weights := classWeights(y, train, k, opts)
+
+	dim := len(x[0])
+	m := &Model{Labels: labels, Weights: make([][]float64, k), Bias: make([]float64, k)}
+	for c := range m.Weights {
+		m.Weights[c] = make([]float64, dim)
+	}
+	opt := newAdam(k, dim, opts.LearningRate)
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	history := &History{}
+	best := m.clone()
+	bestLoss := math.Inf(1)
+	wait := 0
+	gradW := make([][]float64, k)
+	for c := range gradW {
+		gradW[c] = make([]float64, dim)
+	}
+	gradB := make([]float64, k)
+	order := append([]int(nil), train...)
+
+	for epoch := 1; epoch <= opts.Epochs; epoch++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		for start := 0; start < len(order); start += opts.BatchSize {
+			batch := order[start:min(start+opts.BatchSize, len(order))]
+			for c := range gradW {
+				clear(gradW[c])
+			}
+			clear(gradB)
+			var total float64
+			for _, i := range batch {
+				p := m.Probabilities(x[i])
+				w := weights[y[i]]
+				total += w
+				for c := range p {
+					g := p[c]
+					if c == y[i] {
+						g--
+					}
+					g *= w
+					gradB[c] += g
+					for d, v := range x[i] {
+						gradW[c][d] += g * v
+					}
+				}
+			}
+			if total == 0 {
+				continue
+			}
+			for c := range gradW {
+				gradB[c] /= total
+				for d := range gradW[c] {
+					gradW[c][d] = gradW[c][d]/total + opts.L2*m.Weights[c][d]
+				}
+			}
+			opt.step(m, gradW, gradB)
+		}
+
+		e := Epoch{Epoch: epoch, TrainLoss: m.loss(x, y, train, weights, opts.L2)}
+		if math.IsNaN(e.TrainLoss) || math.IsInf(e.TrainLoss, 0) {
+			history.Epochs = append(history.Epochs, e)
+			return nil, history, fmt.Errorf("обучение разошлось на эпохе %d: потери не конечны; уменьшите скорость обучения", epoch)
+		}
+		if len(val) == 0 {
+			history.Epochs = append(history.Epochs, e)
+			history.Best = epoch
+			continue
+		}
+		e.ValLoss = m.loss(x, y, val, weights, 0)
+		e.ValAccuracy = m.accuracy(x, y, val)
+		history.Epochs = append(history.Epochs, e)
+		if e.ValLoss < bestLoss-1e-6 {
+			bestLoss, best, history.Best, wait = e.ValLoss, m.clone(), epoch, 0
+			continue
+		}
+		if wait++; wait >= opts.Patience {
+			history.Stopped = true
+			break
+		}
+	}
+	if history.Best == 0 {
+		// Потери на валидации ни разу не были конечными (NaN или бесконечность)
+		return nil, history, fmt.Errorf("обучение разошлось: потери на валидации не конечны; уменьшите скорость обучения")
+	}
+	if len(val) > 0 {
+		m = best
+	}
+	return m, history, nil
+}
+
+// Probabilities возвращает вероятности классов для вектора документа
+func (m *Model) Probabilities(x []float64) []float64 {
+	scores := make([]float64, len(m.Weights))
+	maxScore := math.Inf(-1)
+	for c, w := range m.Weights {
+		s := m.Bias[c]
+		for d, v := range x {
+			s += w[d] * v
+		}
+		scores[c] = s
+		maxScore = math.Max(maxScore, s)
+	}
+	var sum float64
+	for c, s := range scores {
+		scores[c] = math.Exp(s - maxScore)
+		sum += scores[c]
+	}
+	for c := range scores {
+		scores[c] /= sum
+	}
+	return scores
+}
+
+// Predict возвращает номер наиболее вероятного класса и его вероятность
+func (m *Model) Predict(x []float64) (int, float64) {
+	p := m.Probabilities(x)
+	best := 0
+	for c := range p {
+		if p[c] > p[best] {
+			best = c
+		}
+	}
+	return best, p[best]
+}
+
+// loss — средняя взвешенная перекрёстная энтропия по примерам idx плюс L2-штраф
+func (m *Model) loss(x [][]float64, y []int, idx []int, weights []float64, l2 float64) float64 {
+	var sum, total float64
+	for _, i := range idx {
+		p := m.Probabilities(x[i])
+		w := weights[y[i]]
+		sum -= w * math.Log(math.Max(p[y[i]], 1e-15))
+		total += w
+	}
+	if total > 0 {
+		sum /= total
+	}
+	if l2 > 0 {
+		var norm float64
+		for _, w := range m.Weights {
+			for _, v := range w {
+				norm += v * v
+			}
+		}
+		sum += l2 / 2 * norm
+	}
+	return sum
+}
+
+// accuracy — доля верно классифицированных примеров idx
+func (m *Model) accuracy(x [][]float64, y []int, idx []int) float64 {
+	if len(idx) == 0 {
+		return 0
+	}
+	correct := 0
+	for _, i := range idx {
+		if c, _ := m.Predict(x[i]); c == y[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(idx))
+}
+
+func (m *Model) clone() *Model {
+	c := &Model{Labels: m.Labels, Weights: make([][]float64, len(m.Weights)), Bias: append([]float64(nil), m.Bias...)}
+	for i, w := range m.Weights {
+		c.Weights[i] = append([]float64(nil), w...)
+	}
+	return c
+}
+
+// classWeights возвращает веса классов: явные, сбалансированные по обучающей части или единичные
+func classWeights(y, train []int, k int, opts Options) []float64 {
+	weights := make([]float64, k)
+	if len(opts.ClassWeights) == k {
+		copy(weights, opts.ClassWeights)
+		return weights
+	}
+	for c := range weights {
+		weights[c] = 1
+	}
+	if !opts.Balanced {
+		return weights
+	}
+	counts := make([]int, k)
+	for _, i := range train {
+		counts[y[i]]++
+	}
+	for c, n := range counts {
+		if n > 0 {
+			weights[c] = float64(len(train)) / float64(k*n)
+		}
+	}
+	return weights
+}
+
+// Split делит примеры на обучающие и валидационные с сохранением пропорций
+// классов: из каждого класса в валидацию попадает доля share (но хотя бы один
+// пример, если в классе больше одного). Возвращаются номера примеров.
+func Split(y []int, share float64, seed int64) (train, val []int) {
+	if share <= 0 {
+		train = make([]int, len(y))
+		for i := range train {
+			train[i] = i
+		}
+		return train, nil
+	}
+	byClass := make(map[int][]int)
+	for i, label := range y {
+		byClass[label] = append(byClass[label], i)
+	}
+	classes := make([]int, 0, len(byClass))
+	for c := range byClass {
+		classes = append(classes, c)
+	}
+	sort.Ints(classes)
+
+	rng := rand.New(rand.NewSource(seed))
+	for _, c := range classes {
+		idx := byClass[c]
+		rng.Shuffle(len(idx), func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+		n := int(math.Round(share * float64(len(idx))))
+		if n == 0 && len(idx) > 1 {
+			n = 1
+		}
+		n = min(n, len(idx)-1)
+		val = append(val, idx[:n]...)
+		train = append(train, idx[n:]...)
+	}
+	sort.Ints(train)
+	sort.Ints(val)
+	return train, val
+}
+
+// adam — оптимизатор Adam для весов и смещений модели
+type adam struct {
+	lr, beta1, beta2, eps float64
+	t                     int
+	mW, vW                [][]float64
+	mB, vB                []float64
+}
+
+func newAdam(k, dim int, lr float64) *adam {
+	a := &adam{lr: lr, beta1: 0.9, beta2: 0.999, eps: 1e-8, mW: make([][]float64, k), vW: make([][]float64, k), mB: make([]float64, k), vB: make([]float64, k)}
+	for c := 0; c < k; c++ {
+		a.mW[c] = make([]float64, dim)
+		a.vW[c] = make([]float64, dim)
+	}
+	return a
+}
+
+func (a *adam) step(m *Model, gradW [][]float64, gradB []float64) {
+	a.t++
+	c1 := 1 - math.Pow(a.beta1, float64(a.t))
+	c2 := 1 - math.Pow(a.beta2, float64(a.t))
+	update := func(param, mom, vel *float64, g float64) {
+		*mom = a.beta1**mom + (1-a.beta1)*g
+		*vel = a.beta2**vel + (1-a.beta2)*g*g
+		*param -= a.lr * (*mom / c1) / (math.Sqrt(*vel/c2) + a.eps)
+	}
+	for c := range m.Weights {
+		for d := range m.Weights[c] {
+			update(&m.Weights[c][d], &a.mW[c][d], &a.vW[c][d], gradW[c][d])
+		}
+		update(&m.Bias[c], &a.mB[c], &a.vB[c], gradB[c])
+	}
+}
@@ -0,0 +1,137 @@
+package classify
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// blobs возвращает n точек на класс вокруг центров centers
+func blobs(centers [][]float64, n int, spread float64, seed int64) ([][]float64, []int) {
+	rng := rand.New(rand.NewSource(seed))
+	var x [][]float64
+	var y []int
+	for c, center := range centers {
+		for i := 0; i < n; i++ {
+			point := make([]float64, len(center))
+			for d, v := range center {
+				point[d] = v + spread*rng.NormFloat64()
+			}
+			x = append(x, point)
+			y = append(y, c)
+		}
+	}
+	return x, y
+}
+
+func TestTrain(t *testing.T) {
+	centers := [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	x, y := blobs(centers, 40, 0.1, 1)
+	labels := []string{"a", "b", "c"}
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"с валидацией", DefaultOptions()},
+		{"без валидации", Options{Epochs: 100, Validation: 0, Seed: 2}},
+		{"сбалансированные веса", Options{Epochs: 100, Balanced: true, Validation: 0.2, Seed: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, history, err := Train(x, y, labels, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if history.Best == 0 || history.Best > len(history.Epochs) {
+				t.Errorf("лучшая эпоха %d вне 1..%d", history.Best, len(history.Epochs))
+			}
+			all, _ := Split(y, 0, 0)
+			if acc := m.accuracy(x, y, all); acc < 0.99 {
+				t.Errorf("точность на обучающих примерах %.3f", acc)
+			}
+			for _, p := range [][]float64{m.Probabilities(x[0]), m.Probabilities([]float64{5, -5, 0})} {
+				var sum float64
+				for _, v := range p {
+					sum += v
+				}
+				if math.Abs(sum-1) > 1e-9 {
+					t.Errorf("сумма вероятностей %v = %v", p, sum)
+				}
+			}
+		})
+	}
+}
+
+func TestTrainErrors(t *testing.T) {
+	x, y := blobs([][]float64{{1, 0}, {0, 1}}, 10, 0.1, 1)
+	tests := []struct {
+		name   string
+		x      [][]float64
+		y      []int
+		labels []string
+		opts   Options
+	}{
+		{"нет примеров", nil, nil, []string{"a", "b"}, DefaultOptions()},
+		{"один класс", x, make([]int, len(x)), []string{"a"}, DefaultOptions()},
+		{"метка вне диапазона", x, append(y[:len(y)-1:len(y)-1], 2), []string{"a", "b"}, DefaultOptions()},
+		{"расходимость", x, y, []string{"a", "b"}, Options{Epochs: 5, LearningRate: 1e300, L2: 1e-4, Validation: 0.2, Seed: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m, _, err := Train(tt.x, tt.y, tt.labels, tt.opts); err == nil {
+				t.Errorf("Train = %v, want ошибку", m)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	y := []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2}
+	tests := []struct {
+		share   float64
+		wantVal map[int]int // Число валидационных примеров каждого класса
+	}{
+		{0, map[int]int{}},
+		{0.2, map[int]int{0: 2, 1: 1}},
+		{0.5, map[int]int{0: 5, 1: 2}},
+		{1, map[int]int{0: 9, 1: 3}}, // В обучении остаётся хотя бы один пример класса
+	}
+	for _, tt := range tests {
+		train, val := Split(y, tt.share, 1)
+		if len(train)+len(val) != len(y) {
+			t.Fatalf("share %v: %d + %d примеров, want %d", tt.share, len(train), len(val), len(y))
+		}
+		got := make(map[int]int)
+		for _, i := range val {
+			got[y[i]]++
+		}
+		for c := 0; c < 3; c++ {
+			if got[c] != tt.wantVal[c] {
+				t.Errorf("share %v: в валидации класса %d %d примеров, want %d", tt.share, c, got[c], tt.wantVal[c])
+			}
+		}
+	}
+}
+
+func TestClassWeights(t *testing.T) {
+	y := []int{0, 0, 0, 1}
+	train := []int{0, 1, 2, 3}
+	tests := []struct {
+		name string
+		opts Options
+		want []float64
+	}{
+		{"единичные", Options{}, []float64{1, 1}},
+		{"сбалансированные", Options{Balanced: true}, []float64{4.0 / 6, 2}},
+		{"явные важнее сбалансированных", Options{Balanced: true, ClassWeights: []float64{3, 5}}, []float64{3, 5}},
+	}
+	for _, tt := range tests {
+		got := classWeights(y, train, 2, tt.opts)
+		for c := range got {
+			if math.Abs(got[c]-tt.want[c]) > 1e-12 {
+				t.Errorf("%s: веса %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package cluster
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/vectors"
+	"sort"
+	"strings"
+)
+
+// GraphMethod — способ выделения групп в графе соседей
+type GraphMethod string
+
+// Поддерживаемые способы
+const (
+	Components GraphMethod = "components" // Компоненты связности
+	Louvain    GraphMethod = "louvain"    // Сообщества с максимальной модулярностью (алгоритм Louvain)
+	Cliques    GraphMethod = "cliques"    // Клики во взаимном графе соседей
+)
+
+// ParseGraphMethod разбирает название способа
+func ParseGraphMethod(name string) (GraphMethod, error) {
+	switch GraphMethod(strings.ToLower(strings.TrimSpace(name))) {
+	case Components, "cc":
+		return Components, nil
+	case Louvain:
+		return Louvain, nil
+	case "leiden":
+		return "", fmt.Errorf("алгоритм Leiden не поддерживается, используйте louvain")
+	case Cliques, "mutual":
+		return Cliques, nil
+	}
+	return "", fmt.Errorf("неизвестный способ кластеризации графа: %s (components, louvain, cliques)", name)
+}
+
+// GraphOptions задаёт параметры кластеризации графа
+type GraphOptions struct {
+	Method     GraphMethod
+	Resolution float64 // Разрешение для Louvain: больше — мельче сообщества
+	MinSize    int     // Минимальный размер группы (меньшие группы отбрасываются)
+}
+
+// Group — группа элементов (номера по возрастанию) и её качество
+type Group struct {
+	Members  []int   `json:"members"`
+	Cohesion float64 `json:"cohesion"` // Среднее попарное косинусное сходство внутри группы
+}
+
+// GraphResult — результат кластеризации графа
+type GraphResult struct {
+	Groups     []Group `json:"groups"`
+	Modularity float64 `json:"modularity"` // Модулярность разбиения на графе соседей
+	Cohesion   float64 `json:"cohesion"`   // Средняя связность групп, взвешенная по размеру
+}
+
+// ClusterGraph выделяет группы в графе k ближайших соседей. Рёбра графа считаются
+// неориентированными с весом, равным сходству. Результат детерминирован:
+// группы упорядочены по убыванию размера, затем по наименьшему номеру элемента.
+func ClusterGraph(g ann.Graph, vecs [][]float64, opts GraphOptions) (*GraphResult, error) {
+	if opts.Resolution <= 0 {
+		opts.Resolution = 1
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 2
+	}
+
+	var labels []int
+	switch opts.Method {
+	case Components, "":
+		labels = components(newWeightedGraph(g))
+	case Louvain:
+		labels = louvain(newWeightedGraph(g), opts.Resolution)
+	case Cliques:
+		labels = cliques(g.Mutual())
+	default:
+		return nil, fmt.Errorf("неизвестный способ кластеризации графа: %s", opts.Method)
+	}
+
+	result := &GraphResult{Modularity: modularity(newWeightedGraph(g), labels, 1)}
+	var total int
+	for _, members := range groupLabels(labels) {
+		if len(members) < opts.MinSize {
+			continue
+		}
+		group := Group{Members: members, Cohesion: Cohesion(vecs, members)}
+		result.Groups = append(result.Groups, group)
+		result.Cohesion += group.Cohesion * float64(len(members))
+		total += len(members)
+	}
+	if total > 0 {
+		result.Cohesion /= float64(total)
+	}
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		a, b := result.Groups[i].Members, result.Groups[j].Members
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a[0] < b[0]
+	})
+	return result, nil
+}
+
+// Cohesion вычисляет среднее попарное косинусное сходство элементов группы
+func Cohesion(vecs [][]float64, members []int) float64 {
+	if len(members) < 2 {
+		return 1
+	}
+	normed := make([][]float64, len(members))
+	for i, m := range members {
+		normed[i] = vectors.Normalize(vecs[m])
+	}
+	var sum float64
+	for i := range normed {
+		for j := i + 1; j < len(normed); j++ {
+			sum += linalg.Dot(normed[i], normed[j])
+		}
+	}
+	pairs := len(members) * (len(members) - 1) / 2
+	return sum / float64(pairs)
+}
+
+// groupLabels собирает номера элементов по меткам; группы упорядочены по первому элементу
+func groupLabels(labels []int) [][]int {
+	index := make(map[int]int)
+	var groups [][]int
+	for i, label := range labels {
+		g, ok := index[label]
+		if !ok {
+			g = len(groups)
+			index[label] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// edge — ребро взвешенного графа
+type edge struct {
+	to     int
+	weight float64
+}
+
+// weightedGraph — неориентированный взвешенный граф с петлями (петли появляются при агрегации в Louvain)
+type weightedGraph struct {
+	adj    [][]edge  // Соседи каждой вершины, упорядоченные по номеру
+	self   []float64 // Вес петли вершины
+	degree []float64 // Взвешенная степень вершины с учётом петли
+	total  float64   // Сумма степеней (удвоенный вес всех рёбер)
+}
+
+// newWeightedGraph строит неориентированный граф из графа соседей; вес ребра — наибольшее
+// из сходств в двух направлениях
+func newWeightedGraph(g ann.Graph) *weightedGraph {
+	weights := make([]map[int]float64, len(g))
+	for i := range g {
+		weights[i] = make(map[int]float64)
+	}
+	for i, list := range g {
+		for _, r := range list {
+			if r.ID == i || r.Similarity <= 0 {
+				continue
+			}
+			if r.Similarity > weights[i][r.ID] {
+				weights[i][r.ID] = r.Similarity
+				weights[r.ID][i] = r.Similarity
+			}
+		}
+	}
+	return fromWeights(weights, make([]float64, len(g)))
+}
+
+// fromWeights строит граф из матрицы весов в виде словарей
+func fromWeights(weights []map[int]float64, self []float64) *weightedGraph {
+	wg := &weightedGraph{adj: make([][]edge, len(weights)), self: self, degree: make([]float64, len(weights))}
+	for i, w := range weights {
+		for j, weight := range w {
+			wg.adj[i] = append(wg.adj[i], edge{to: j, weight: weight})
+		}
+		// Порядок суммирования фиксирован, чтобы результат не зависел от обхода словаря
+		sort.Slice(wg.adj[i], func(a, b int) bool { return wg.adj[i][a].to < wg.adj[i][b].to })
+		for _, e := range wg.adj[i] {
+			wg.degree[i] += e.weight
+		}
+		wg.degree[i] += self[i]
+		wg.total += wg.degree[i]
+	}
+	return wg
+}
+
+// components размечает компоненты связности
+func components(wg *weightedGraph) []int {
+	parent := make([]int, len(wg.adj))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, list := range wg.adj {
+		for _, e := range list {
+			a, b := find(i), find(e.to)
+			// Корнем остаётся меньший номер, чтобы метки не зависели от порядка рёбер
+			if a < b {
+				parent[b] = a
+			} else if b < a {
+				parent[a] = b
+			}
+		}
+	}
+	labels := make([]int, len(parent))
+	for i := range parent {
+		labels[i] = find(i)
+	}
+	return labels
+}
+
+// louvain ищет разбиение с максимальной модулярностью: вершины по очереди переносятся
+// в соседнее сообщество с наибольшим приростом модулярности, затем сообщества
+// сворачиваются в вершины, и шаги повторяются, пока разбиение меняется
+func louvain(wg *weightedGraph, resolution float64) []int {
+	labels := make([]int, len(wg.adj))
+	for i := range labels {
+		labels[i] = i
+	}
+	if wg.total == 0 {
+		return labels
+	}
+
+	for {
+		community, moved := localMoving(wg, resolution)
+		if !moved {
+			return labels
+		}
+		// Перенумерация сообществ подряд в порядке первой вершины
+		renumber := make(map[int]int)
+		for _, c := range community {
+			if _, ok := renumber[c]; !ok {
+				renumber[c] = len(renumber)
+			}
+		}
+		for i, l := range labels {
+			labels[i] = renumber[community[l]]
+		}
+		wg = aggregate(wg, community, renumber)
+	}
+}
+
+// localMoving выполняет фазу переноса вершин; возвращает сообщество каждой вершины
+func localMoving(wg *weightedGraph, resolution float64) ([]int, bool) {
+	n := len(wg.adj)
+	community := make([]int, n)
+	tot := make([]float64, n)
+	for i := range community {
+		community[i] = i
+		tot[i] = wg.degree[i]
+	}
+
+	moved := false
+	links := make(map[int]float64)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n; i++ {
+			current := community[i]
+			clear(links)
+			var candidates []int
+			for _, e := range wg.adj[i] {
+				c := community[e.to]
+				if _, ok := links[c]; !ok {
+					candidates = append(candidates, c)
+				}
+				links[c] += e.weight
+			}
+
+			tot[current] -= wg.degree[i]
+			k := wg.degree[i]
+			best := current
+			bestGain := links[current] - resolution*tot[current]*k/wg.total
+			sort.Ints(candidates)
+			for _, c := range candidates {
+				gain := links[c] - resolution*tot[c]*k/wg.total
+				if gain > bestGain+1e-12 {
+					best, bestGain = c, gain
+				}
+			}
+			tot[best] += k
+			if best != current {
+				community[i] = best
+				improved = true
+				moved = true
+			}
+		}
+	}
+	return community, moved
+}
+
+// aggregate сворачивает сообщества в вершины нового графа
+func aggregate(wg *weightedGraph, community []int, renumber map[int]int) *weightedGraph {
+	n := len(renumber)
+	weights := make([]map[int]float64, n)
+	for i := range weights {
+		weights[i] = make(map[int]float64)
+	}
+	self := make([]float64, n)
+	for i, list := range wg.adj {
+		ci := renumber[community[i]]
+		self[ci] += wg.self[i]
+		for _, e := range list {
+			cj := renumber[community[e.to]]
+			if ci == cj {
+				self[ci] += e.weight
+			} else {
+				weights[ci][cj] += e.weight
+			}
+		}
+	}
+	return fromWeights(weights, self)
+}
+
+// modularity вычисляет модулярность разбиения
+func modularity(wg *weightedGraph, labels []int, resolution float64) float64 {
+	if wg.total == 0 {
+		return 0
+	}
+	// Метки — номера вершин или сообществ, поэтому они меньше числа вершин
+	inner := make([]float64, len(labels))
+	tot := make([]float64, len(labels))
+	for i, list := range wg.adj {
+		c := labels[i]
+		tot[c] += wg.degree[i]
+		inner[c] += wg.self[i]
+		for _, e := range list {
+			if labels[e.to] == c {
+				inner[c] += e.weight
+			}
+		}
+	}
+	var q float64
+	for c, t := range tot {
+		q += inner[c]/wg.total - resolution*(t/wg.total)*(t/wg.total)
+	}
+	return q
+}
+
+// cliques жадно выделяет клики во взаимном графе соседей: вершины рассматриваются
+// по порядку, к клике добавляются свободные соседи (по убыванию сходства),
+// связанные со всеми её членами
+func cliques(g ann.Graph) []int {
+	labels := make([]int, len(g))
+	for i := range labels {
+		labels[i] = -1
+	}
+	neighbors := make([]map[int]bool, len(g))
+	for i, list := range g {
+		neighbors[i] = make(map[int]bool, len(list))
+		for _, r := range list {
+			neighbors[i][r.ID] = true
+		}
+	}
+
+	for i := range g {
+		if labels[i] >= 0 {
+			continue
+		}
+		labels[i] = i
+		clique := []int{i}
+		for _, r := range g[i] {
+			if labels[r.ID] >= 0 {
+				continue
+			}
+			connected := true
+			for _, m := range clique[1:] {
+				if !neighbors[r.ID][m] {
+					connected = false
+					break
+				}
+			}
+			if connected {
+				labels[r.ID] = i
+				clique = append(clique, r.ID)
+			}
+		}
+	}
+	return labels
+}
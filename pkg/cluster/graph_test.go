@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"glove-pipeline/pkg/ann"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// twoCliques возвращает две группы близких векторов и граф их ближайших соседей
+func twoCliques(t *testing.T) ([][]float64, ann.Graph) {
+	t.Helper()
+	vecs := [][]float64{
+		{1, 0.05, 0}, {1, 0, 0.05}, {1, 0.02, 0.02}, {1, -0.03, 0.01},
+		{0, 1, 0.05}, {0.05, 1, 0}, {0.02, 1, 0.02}, {-0.03, 1, 0.01},
+	}
+	// Три соседа — ровно своя группа; порог отсекает рёбра между группами
+	g, err := ann.ExactKNN(vecs, 3, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vecs, g
+}
+
+func TestClusterGraph(t *testing.T) {
+	vecs, g := twoCliques(t)
+	want := []Group{{Members: []int{0, 1, 2, 3}}, {Members: []int{4, 5, 6, 7}}}
+	for _, method := range []GraphMethod{Components, Louvain, Cliques} {
+		result, err := ClusterGraph(g, vecs, GraphOptions{Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Groups) != len(want) {
+			t.Fatalf("%s: группы %+v", method, result.Groups)
+		}
+		for i, group := range result.Groups {
+			if !reflect.DeepEqual(group.Members, want[i].Members) {
+				t.Errorf("%s: группа %d = %v, ожидалось %v", method, i, group.Members, want[i].Members)
+			}
+			if group.Cohesion < 0.99 {
+				t.Errorf("%s: связность группы %d = %.3f", method, i, group.Cohesion)
+			}
+		}
+		// Две равные несвязанные группы дают модулярность 1/2
+		if math.Abs(result.Modularity-0.5) > 1e-9 {
+			t.Errorf("%s: модулярность %.6f, ожидалось 0.5", method, result.Modularity)
+		}
+	}
+}
+
+func TestLouvainSplitsConnectedCliques(t *testing.T) {
+	vecs, g := twoCliques(t)
+	// Слабое ребро между группами объединяет их в одну компоненту связности
+	g[3] = append(g[3], ann.Result{ID: 4, Similarity: 0.1})
+
+	components, err := ClusterGraph(g, vecs, GraphOptions{Method: Components})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(components.Groups) != 1 {
+		t.Errorf("компонент связности %d, ожидалась одна", len(components.Groups))
+	}
+	communities, err := ClusterGraph(g, vecs, GraphOptions{Method: Louvain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(communities.Groups) != 2 || !reflect.DeepEqual(communities.Groups[0].Members, []int{0, 1, 2, 3}) {
+		t.Errorf("сообщества Louvain %+v", communities.Groups)
+	}
+	if communities.Modularity <= components.Modularity {
+		t.Errorf("модулярность сообществ %.3f не больше, чем у компоненты %.3f", communities.Modularity, components.Modularity)
+	}
+}
+
+func TestClusterGraphMinSize(t *testing.T) {
+	vecs, g := twoCliques(t)
+	vecs = append(vecs, []float64{0, 0, 1})
+	g = append(g, nil)
+	result, err := ClusterGraph(g, vecs, GraphOptions{Method: Components, MinSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Groups) != 2 {
+		t.Errorf("одиночная вершина не отброшена: %+v", result.Groups)
+	}
+}
+
+func TestCohesion(t *testing.T) {
+	vecs := [][]float64{{1, 0}, {0, 2}, {3, 0}}
+	if got := Cohesion(vecs, []int{0, 2}); math.Abs(got-1) > 1e-12 {
+		t.Errorf("связность сонаправленных векторов %v", got)
+	}
+	if got := Cohesion(vecs, []int{0, 1, 2}); math.Abs(got-1.0/3) > 1e-12 {
+		t.Errorf("связность %v, ожидалось 1/3", got)
+	}
+	if got := Cohesion(vecs, []int{1}); got != 1 {
+		t.Errorf("связность одного элемента %v", got)
+	}
+}
+
+func TestParseGraphMethod(t *testing.T) {
+	for name, want := range map[string]GraphMethod{"components": Components, "CC": Components, "louvain": Louvain, " mutual ": Cliques} {
+		if got, err := ParseGraphMethod(name); err != nil || got != want {
+			t.Errorf("ParseGraphMethod(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseGraphMethod("spectral"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного способа")
+	}
+}
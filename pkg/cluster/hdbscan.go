@@ -0,0 +1,360 @@
+package cluster
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Noise — метка объектов, не отнесённых ни к одному кластеру
+const Noise = -1
+
+// HDBSCANOptions задаёт параметры HDBSCAN
+type HDBSCANOptions struct {
+	MinClusterSize int // Минимальный размер кластера
+	MinSamples     int // Число соседей для оценки плотности, включая сам объект (0 — равно MinClusterSize)
+}
+
+// CondensedCluster — кластер сжатого дерева HDBSCAN
+type CondensedCluster struct {
+	ID        int     `json:"id"`
+	Parent    int     `json:"parent"` // −1 для корня
+	Birth     float64 `json:"birth"`  // λ = 1/расстояние, при котором кластер отделился
+	Size      int     `json:"size"`
+	Stability float64 `json:"stability"`
+	Selected  bool    `json:"selected"`
+	Label     int     `json:"label"` // Метка выбранного кластера или −1
+}
+
+// HDBSCANResult — результат HDBSCAN
+type HDBSCANResult struct {
+	Labels   []int              `json:"labels"` // Метка кластера или Noise
+	Clusters []CondensedCluster `json:"clusters"`
+	Noise    int                `json:"noise"`
+	Tree     *Dendrogram        `json:"-"` // Дерево одиночной связи по расстоянию взаимной достижимости
+	members  [][]int            // Объекты, выпавшие непосредственно из каждого кластера сжатого дерева
+}
+
+// HDBSCAN выделяет кластеры переменной плотности по косинусному расстоянию.
+// Плотность объекта оценивается расстоянием до MinSamples-го соседа, считая
+// сам объект, как в эталонной реализации; по
+// расстоянию взаимной достижимости строится минимальное остовное дерево,
+// из него — иерархия, сжатая до кластеров не меньше MinClusterSize. Выбираются
+// наиболее устойчивые кластеры, остальные объекты помечаются как шум.
+func HDBSCAN(vecs [][]float64, opts HDBSCANOptions) (*HDBSCANResult, error) {
+	n := len(vecs)
+	if opts.MinClusterSize < 2 {
+		opts.MinClusterSize = 2
+	}
+	if opts.MinSamples <= 0 {
+		opts.MinSamples = opts.MinClusterSize
+	}
+	if n <= opts.MinSamples {
+		return nil, fmt.Errorf("объектов (%d) должно быть больше MinSamples (%d)", n, opts.MinSamples)
+	}
+
+	normed := make([][]float64, n)
+	for i, vec := range vecs {
+		normed[i] = vectors.Normalize(vec)
+	}
+
+	core, err := coreDistances(normed, opts.MinSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &Dendrogram{Leaves: n, Merges: make([]Merge, 0, n-1)}
+	uf := newUnionFind(n)
+	for _, e := range mutualReachabilityMST(normed, core) {
+		tree.Merges = append(tree.Merges, uf.merge(e.from, e.to, e.weight))
+	}
+
+	result := &HDBSCANResult{Tree: tree}
+	result.condense(opts.MinClusterSize)
+	result.selectClusters()
+	return result, nil
+}
+
+// coreDistances возвращает расстояние от каждого объекта до его minSamples-го
+// соседа, считая сам объект первым: ищутся minSamples−1 других соседей, и при
+// minSamples = 1 расстояние нулевое
+func coreDistances(normed [][]float64, minSamples int) ([]float64, error) {
+	core := make([]float64, len(normed))
+	if minSamples <= 1 {
+		return core, nil
+	}
+	knn, err := ann.ExactKNN(normed, minSamples-1, math.Inf(-1))
+	if err != nil {
+		return nil, err
+	}
+	for i, neighbors := range knn {
+		core[i] = math.Max(0, 1-neighbors[len(neighbors)-1].Similarity)
+	}
+	return core, nil
+}
+
+type mstEdge struct {
+	from, to int
+	weight   float64
+}
+
+// mutualReachabilityMST строит минимальное остовное дерево алгоритмом Прима по расстоянию
+// max(core(a), core(b), d(a, b)); рёбра возвращаются по возрастанию веса.
+// Расстояния до новой вершины дерева пересчитываются на каждом шаге, поэтому
+// при n не больше одного блока пересчёт идёт в текущей горутине: запуск потоков
+// на каждом из n шагов обошёлся бы дороже самого пересчёта.
+func mutualReachabilityMST(normed [][]float64, core []float64) []mstEdge {
+	n := len(normed)
+	inTree := make([]bool, n)
+	best := make([]float64, n)
+	from := make([]int, n)
+	for i := range best {
+		best[i] = math.Inf(1)
+	}
+
+	edges := make([]mstEdge, 0, n-1)
+	current := 0
+	inTree[0] = true
+	relax := func(chunk int) {
+		for j := chunk * chunkSize; j < min(n, (chunk+1)*chunkSize); j++ {
+			if inTree[j] {
+				continue
+			}
+			d := math.Max(1-linalg.Dot(normed[current], normed[j]), 0)
+			d = math.Max(d, math.Max(core[current], core[j]))
+			if d < best[j] {
+				best[j], from[j] = d, current
+			}
+		}
+	}
+	chunks := (n + chunkSize - 1) / chunkSize
+	for len(edges) < n-1 {
+		if chunks == 1 {
+			relax(0)
+		} else {
+			parallel.For(chunks, relax)
+		}
+		next := -1
+		for j := range best {
+			if !inTree[j] && (next < 0 || best[j] < best[next]) {
+				next = j
+			}
+		}
+		edges = append(edges, mstEdge{from: from[next], to: next, weight: best[next]})
+		inTree[next] = true
+		current = next
+	}
+	sort.SliceStable(edges, func(i, j int) bool { return edges[i].weight < edges[j].weight })
+	return edges
+}
+
+// lambda переводит расстояние в плотность λ = 1/расстояние; нулевые расстояния
+// (совпадающие векторы) ограничиваются, чтобы устойчивость оставалась конечной
+func lambda(distance float64) float64 {
+	return 1 / math.Max(distance, 1e-9)
+}
+
+// condense сжимает иерархию: при разделении, где обе части не меньше minSize,
+// рождаются два новых кластера; меньшие части считаются выпавшими из кластера
+// объектами. Устойчивость кластера — сумма (λ выпадения − λ рождения) по его объектам.
+func (r *HDBSCANResult) condense(minSize int) {
+	tree := r.Tree
+	size := func(node int) int {
+		if node < tree.Leaves {
+			return 1
+		}
+		return tree.Merges[node-tree.Leaves].Size
+	}
+	var leaves func(node int, out []int) []int
+	leaves = func(node int, out []int) []int {
+		stack := []int{node}
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if v < tree.Leaves {
+				out = append(out, v)
+				continue
+			}
+			m := tree.Merges[v-tree.Leaves]
+			stack = append(stack, m.Right, m.Left)
+		}
+		return out
+	}
+
+	root := tree.root()
+	r.Clusters = []CondensedCluster{{ID: 0, Parent: -1, Size: size(root), Label: Noise}}
+	r.members = [][]int{nil}
+
+	type task struct{ node, cluster int }
+	stack := []task{{root, 0}}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node, c := t.node, t.cluster
+		for {
+			if node < tree.Leaves {
+				// Одиночный объект остаётся в кластере до самого конца
+				r.members[c] = append(r.members[c], node)
+				break
+			}
+			m := tree.Merges[node-tree.Leaves]
+			l := lambda(m.Distance)
+			birth := r.Clusters[c].Birth
+			left, right := m.Left, m.Right
+			sl, sr := size(left), size(right)
+			switch {
+			case sl >= minSize && sr >= minSize:
+				r.Clusters[c].Stability += (l - birth) * float64(sl+sr)
+				for _, child := range []int{left, right} {
+					id := len(r.Clusters)
+					r.Clusters = append(r.Clusters, CondensedCluster{ID: id, Parent: c, Birth: l, Size: size(child), Label: Noise})
+					r.members = append(r.members, nil)
+					stack = append(stack, task{child, id})
+				}
+				node = -1
+			case sl < minSize && sr < minSize:
+				r.Clusters[c].Stability += (l - birth) * float64(sl+sr)
+				r.members[c] = leaves(left, r.members[c])
+				r.members[c] = leaves(right, r.members[c])
+				node = -1
+			default:
+				small, big := left, right
+				if sl >= minSize {
+					small, big = right, left
+				}
+				r.Clusters[c].Stability += (l - birth) * float64(size(small))
+				r.members[c] = leaves(small, r.members[c])
+				node = big
+			}
+			if node < 0 {
+				break
+			}
+		}
+	}
+}
+
+// selectClusters выбирает кластеры методом избытка массы: кластер выбирается, если его
+// устойчивость не меньше суммарной устойчивости выбранных потомков. Корень не выбирается.
+func (r *HDBSCANResult) selectClusters() {
+	children := make([][]int, len(r.Clusters))
+	for _, c := range r.Clusters[1:] {
+		children[c.Parent] = append(children[c.Parent], c.ID)
+	}
+	score := make([]float64, len(r.Clusters))
+	// Потомки создаются после родителей, поэтому обратный порядок — снизу вверх
+	for id := len(r.Clusters) - 1; id > 0; id-- {
+		var sum float64
+		for _, ch := range children[id] {
+			sum += score[ch]
+		}
+		if len(children[id]) == 0 || r.Clusters[id].Stability >= sum {
+			r.Clusters[id].Selected = true
+			score[id] = r.Clusters[id].Stability
+			var unselect func(int)
+			unselect = func(c int) {
+				for _, ch := range children[c] {
+					r.Clusters[ch].Selected = false
+					unselect(ch)
+				}
+			}
+			unselect(id)
+		} else {
+			score[id] = sum
+		}
+	}
+
+	r.Labels = make([]int, r.Tree.Leaves)
+	for i := range r.Labels {
+		r.Labels[i] = Noise
+	}
+	next := 0
+	var assign func(c, label int)
+	assign = func(c, label int) {
+		for _, p := range r.members[c] {
+			r.Labels[p] = label
+		}
+		for _, ch := range children[c] {
+			assign(ch, label)
+		}
+	}
+	for id := 1; id < len(r.Clusters); id++ {
+		if r.Clusters[id].Selected {
+			r.Clusters[id].Label = next
+			assign(id, next)
+			next++
+		}
+	}
+	r.Noise = 0
+	for _, l := range r.Labels {
+		if l == Noise {
+			r.Noise++
+		}
+	}
+}
+
+// Count возвращает число выбранных кластеров
+func (r *HDBSCANResult) Count() int {
+	count := 0
+	for _, c := range r.Clusters {
+		if c.Selected {
+			count++
+		}
+	}
+	return count
+}
+
+// CondensedTree возвращает сжатое дерево кластеров; листья выбранных кластеров — их объекты
+func (r *HDBSCANResult) CondensedTree(names []string) *TreeNode {
+	children := make([][]int, len(r.Clusters))
+	for _, c := range r.Clusters[1:] {
+		children[c.Parent] = append(children[c.Parent], c.ID)
+	}
+	var build func(id int) *TreeNode
+	build = func(id int) *TreeNode {
+		c := r.Clusters[id]
+		node := &TreeNode{Name: "c" + strconv.Itoa(id), Height: c.Birth, Size: c.Size}
+		if c.Selected {
+			node.Name = "cluster" + strconv.Itoa(c.Label)
+		}
+		for _, ch := range children[id] {
+			node.Children = append(node.Children, build(ch))
+		}
+		if c.Selected {
+			for i, l := range r.Labels {
+				if l == c.Label {
+					node.Children = append(node.Children, &TreeNode{Name: leafName(names, i), Size: 1})
+				}
+			}
+		}
+		return node
+	}
+	return build(0)
+}
+
+// Newick возвращает сжатое дерево в формате Newick (без длин ветвей)
+func (n *TreeNode) Newick() string {
+	var b strings.Builder
+	var write func(node *TreeNode)
+	write = func(node *TreeNode) {
+		if len(node.Children) > 0 {
+			b.WriteByte('(')
+			for i, ch := range node.Children {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				write(ch)
+			}
+			b.WriteByte(')')
+		}
+		b.WriteString(quoteNewick(node.Name))
+	}
+	write(n)
+	b.WriteString(";\n")
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// directionBlobs возвращает две плотные группы направлений вокруг осей x и y
+// и два выброса, направленных в сторону, противоположную обеим группам
+func directionBlobs(size int) [][]float64 {
+	rng := rand.New(rand.NewSource(1))
+	var vecs [][]float64
+	for _, axis := range []int{0, 1} {
+		for i := 0; i < size; i++ {
+			vec := []float64{0.05 * rng.NormFloat64(), 0.05 * rng.NormFloat64(), 0.05 * rng.NormFloat64()}
+			vec[axis] = 1
+			vecs = append(vecs, vec)
+		}
+	}
+	return append(vecs, []float64{-1, -1, 0.3}, []float64{-1, -0.8, -0.4})
+}
+
+func TestHDBSCAN(t *testing.T) {
+	const size = 15
+	vecs := directionBlobs(size)
+	result, err := HDBSCAN(vecs, HDBSCANOptions{MinClusterSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Count() != 2 {
+		t.Fatalf("кластеров %d, ожидалось 2: %v", result.Count(), result.Labels)
+	}
+	for i := 0; i < 2*size; i++ {
+		if want := result.Labels[i/size*size]; result.Labels[i] != want || want == Noise {
+			t.Errorf("объект %d: метка %d, ожидалась метка группы %d", i, result.Labels[i], want)
+		}
+	}
+	if result.Labels[0] == result.Labels[size] {
+		t.Error("группы объединены в один кластер")
+	}
+	if result.Labels[2*size] != Noise || result.Labels[2*size+1] != Noise || result.Noise != 2 {
+		t.Errorf("выбросы не помечены как шум: %v", result.Labels[2*size:])
+	}
+	if result.Tree.Leaves != len(vecs) || len(result.Tree.Merges) != len(vecs)-1 {
+		t.Errorf("дерево взаимной достижимости: %d листьев, %d слияний", result.Tree.Leaves, len(result.Tree.Merges))
+	}
+
+	if _, err := json.Marshal(result); err != nil {
+		t.Errorf("результат не сериализуется в JSON: %v", err)
+	}
+	newick := result.CondensedTree(nil).Newick()
+	if !strings.Contains(newick, "cluster0") || !strings.Contains(newick, "cluster1") || !strings.HasSuffix(newick, ";\n") {
+		t.Errorf("сжатое дерево %q", newick)
+	}
+}
+
+func TestCoreDistances(t *testing.T) {
+	vecs := angles(0, 10, 30, 90)
+	cos := func(deg float64) float64 { return 1 - math.Cos(deg*math.Pi/180) }
+	tests := []struct {
+		minSamples int
+		want       []float64
+	}{
+		// Сам объект считается первым соседом
+		{1, []float64{0, 0, 0, 0}},
+		{2, []float64{cos(10), cos(10), cos(20), cos(60)}},
+		{3, []float64{cos(30), cos(20), cos(30), cos(80)}},
+	}
+	for _, tt := range tests {
+		core, err := coreDistances(vecs, tt.minSamples)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range core {
+			if math.Abs(core[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("MinSamples %d: расстояние ядра %d = %.9f, ожидалось %.9f", tt.minSamples, i, core[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHDBSCANTooFewObjects(t *testing.T) {
+	if _, err := HDBSCAN(directionBlobs(1), HDBSCANOptions{MinClusterSize: 5}); err == nil {
+		t.Error("ожидалась ошибка, когда объектов не больше MinSamples")
+	}
+}
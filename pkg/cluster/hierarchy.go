@@ -0,0 +1,355 @@
+package cluster
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Linkage — способ пересчёта расстояния между объединяемыми кластерами
+type Linkage string
+
+// Поддерживаемые способы
+const (
+	Average  Linkage = "average"  // Среднее косинусное расстояние между элементами кластеров
+	Complete Linkage = "complete" // Наибольшее косинусное расстояние
+	Ward     Linkage = "ward"     // Прирост внутрикластерной дисперсии нормированных векторов
+)
+
+// ParseLinkage разбирает название способа
+func ParseLinkage(name string) (Linkage, error) {
+	switch Linkage(strings.ToLower(strings.TrimSpace(name))) {
+	case Average, "":
+		return Average, nil
+	case Complete:
+		return Complete, nil
+	case Ward:
+		return Ward, nil
+	}
+	return "", fmt.Errorf("неизвестный способ связи: %s (average, complete, ward)", name)
+}
+
+// Merge — слияние двух кластеров. Листья имеют номера 0..n−1, кластер,
+// образованный i-м слиянием, — номер n+i.
+type Merge struct {
+	Left     int     `json:"left"`
+	Right    int     `json:"right"`
+	Distance float64 `json:"distance"`
+	Size     int     `json:"size"`
+}
+
+// Dendrogram — дерево слияний, упорядоченных по возрастанию расстояния
+type Dendrogram struct {
+	Leaves int     `json:"leaves"`
+	Merges []Merge `json:"merges"`
+}
+
+// Agglomerative строит дерево иерархической кластеризации по косинусному расстоянию
+// алгоритмом цепочки ближайших соседей (время O(n²), память — n²/2 расстояний).
+// Для Ward расстояния считаются между нормированными векторами, а высота слияния —
+// корень из прироста дисперсии, как в scipy.
+func Agglomerative(vecs [][]float64, linkage Linkage) (*Dendrogram, error) {
+	n := len(vecs)
+	if n < 2 {
+		return nil, fmt.Errorf("для иерархической кластеризации нужно хотя бы два объекта")
+	}
+	if linkage == "" {
+		linkage = Average
+	}
+
+	normed := make([][]float64, n)
+	for i, vec := range vecs {
+		normed[i] = vectors.Normalize(vec)
+	}
+	dist := newCondensed(n)
+	parallel.For(n, func(i int) {
+		for j := i + 1; j < n; j++ {
+			d := 1 - linalg.Dot(normed[i], normed[j])
+			if linkage == Ward {
+				d *= 2 // Квадрат евклидова расстояния между единичными векторами
+			}
+			dist.set(i, j, math.Max(d, 0))
+		}
+	})
+
+	size := make([]int, n)
+	active := make([]bool, n)
+	for i := range size {
+		size[i] = 1
+		active[i] = true
+	}
+
+	// Слияния записываются по представителям кластеров (номерам ячеек) и затем упорядочиваются
+	type rawMerge struct {
+		a, b     int
+		distance float64
+	}
+	raw := make([]rawMerge, 0, n-1)
+	var chain []int
+	for len(raw) < n-1 {
+		if len(chain) == 0 {
+			for i := range active {
+				if active[i] {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		var a, b int
+		for {
+			a = chain[len(chain)-1]
+			b = -1
+			best := math.Inf(1)
+			// При равных расстояниях предпочитается предыдущий элемент цепочки, иначе она может зациклиться
+			if len(chain) > 1 {
+				b = chain[len(chain)-2]
+				best = dist.get(a, b)
+			}
+			for c := range active {
+				if !active[c] || c == a {
+					continue
+				}
+				if d := dist.get(a, c); d < best {
+					best, b = d, c
+				}
+			}
+			if len(chain) > 1 && b == chain[len(chain)-2] {
+				break
+			}
+			chain = append(chain, b)
+		}
+		chain = chain[:len(chain)-2]
+
+		dab := dist.get(a, b)
+		raw = append(raw, rawMerge{a: a, b: b, distance: dab})
+
+		// Новый кластер занимает ячейку b; расстояния пересчитываются по формуле Ланса — Уильямса
+		na, nb := float64(size[a]), float64(size[b])
+		for c := range active {
+			if !active[c] || c == a || c == b {
+				continue
+			}
+			dac, dbc := dist.get(a, c), dist.get(b, c)
+			var d float64
+			switch linkage {
+			case Average:
+				d = (na*dac + nb*dbc) / (na + nb)
+			case Complete:
+				d = math.Max(dac, dbc)
+			case Ward:
+				nc := float64(size[c])
+				d = ((na+nc)*dac + (nb+nc)*dbc - nc*dab) / (na + nb + nc)
+			}
+			dist.set(b, c, d)
+		}
+		active[a] = false
+		size[b] += size[a]
+	}
+
+	sort.SliceStable(raw, func(i, j int) bool { return raw[i].distance < raw[j].distance })
+	d := &Dendrogram{Leaves: n, Merges: make([]Merge, 0, n-1)}
+	uf := newUnionFind(n)
+	for _, m := range raw {
+		distance := m.distance
+		if linkage == Ward {
+			distance = math.Sqrt(distance)
+		}
+		d.Merges = append(d.Merges, uf.merge(m.a, m.b, distance))
+	}
+	return d, nil
+}
+
+// CutK разрезает дерево на k кластеров; метки нумеруются в порядке первого элемента
+func (d *Dendrogram) CutK(k int) []int {
+	k = max(1, min(k, d.Leaves))
+	return d.cut(func(i int, m Merge) bool { return d.Leaves-i > k })
+}
+
+// CutDistance разрезает дерево на высоте h: объединяются только кластеры, слитые на расстоянии не больше h
+func (d *Dendrogram) CutDistance(h float64) []int {
+	return d.cut(func(i int, m Merge) bool { return m.Distance <= h })
+}
+
+// cut применяет слияния, пока apply возвращает true
+func (d *Dendrogram) cut(apply func(i int, m Merge) bool) []int {
+	parent := make([]int, d.Leaves+len(d.Merges))
+	for i := range parent {
+		parent[i] = i
+	}
+	for i, m := range d.Merges {
+		if !apply(i, m) {
+			break
+		}
+		parent[m.Left] = d.Leaves + i
+		parent[m.Right] = d.Leaves + i
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			i = parent[i]
+		}
+		return i
+	}
+	labels := make([]int, d.Leaves)
+	index := make(map[int]int)
+	for i := range labels {
+		r := root(i)
+		if _, ok := index[r]; !ok {
+			index[r] = len(index)
+		}
+		labels[i] = index[r]
+	}
+	return labels
+}
+
+// height возвращает высоту узла дерева (0 для листьев)
+func (d *Dendrogram) height(node int) float64 {
+	if node < d.Leaves {
+		return 0
+	}
+	return d.Merges[node-d.Leaves].Distance
+}
+
+// Newick возвращает дерево в формате Newick; длина ветви — разность высот узлов
+func (d *Dendrogram) Newick(names []string) string {
+	var b strings.Builder
+	var write func(node int, parentHeight float64)
+	write = func(node int, parentHeight float64) {
+		if node < d.Leaves {
+			b.WriteString(quoteNewick(leafName(names, node)))
+		} else {
+			m := d.Merges[node-d.Leaves]
+			b.WriteByte('(')
+			write(m.Left, m.Distance)
+			b.WriteByte(',')
+			write(m.Right, m.Distance)
+			b.WriteByte(')')
+		}
+		if parentHeight >= 0 {
+			b.WriteString(":" + strconv.FormatFloat(parentHeight-d.height(node), 'f', 6, 64))
+		}
+	}
+	write(d.root(), -1)
+	b.WriteString(";\n")
+	return b.String()
+}
+
+// TreeNode — узел дерева для выгрузки в JSON
+type TreeNode struct {
+	Name     string      `json:"name,omitempty"`
+	Height   float64     `json:"height"`
+	Size     int         `json:"size"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// Tree возвращает дерево в виде вложенных узлов
+func (d *Dendrogram) Tree(names []string) *TreeNode {
+	var build func(node int) *TreeNode
+	build = func(node int) *TreeNode {
+		if node < d.Leaves {
+			return &TreeNode{Name: leafName(names, node), Size: 1}
+		}
+		m := d.Merges[node-d.Leaves]
+		return &TreeNode{Height: m.Distance, Size: m.Size, Children: []*TreeNode{build(m.Left), build(m.Right)}}
+	}
+	return build(d.root())
+}
+
+// root возвращает корень дерева; если слияний меньше n−1 (лес), корнем считается последнее слияние
+func (d *Dendrogram) root() int {
+	if len(d.Merges) == 0 {
+		return 0
+	}
+	return d.Leaves + len(d.Merges) - 1
+}
+
+func leafName(names []string, i int) string {
+	if i < len(names) {
+		return names[i]
+	}
+	return strconv.Itoa(i)
+}
+
+// quoteNewick экранирует имя узла: имена со спецсимволами берутся в одинарные кавычки
+func quoteNewick(name string) string {
+	if strings.ContainsAny(name, " ()[]':;,") {
+		return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+	}
+	return name
+}
+
+// AgglomerativeMemory возвращает объём памяти в байтах, который Agglomerative
+// занимает под матрицу расстояний n объектов
+func AgglomerativeMemory(n int) int64 {
+	return int64(n) * int64(n-1) / 2 * 8
+}
+
+// condensed — верхний треугольник симметричной матрицы расстояний без диагонали
+type condensed struct {
+	n    int
+	data []float64
+}
+
+func newCondensed(n int) *condensed {
+	return &condensed{n: n, data: make([]float64, n*(n-1)/2)}
+}
+
+func (c *condensed) index(i, j int) int {
+	if i > j {
+		i, j = j, i
+	}
+	return c.n*i - i*(i+1)/2 + j - i - 1
+}
+
+func (c *condensed) get(i, j int) float64 {
+	return c.data[c.index(i, j)]
+}
+
+func (c *condensed) set(i, j int, v float64) {
+	c.data[c.index(i, j)] = v
+}
+
+// unionFind нумерует кластеры при упорядочивании слияний: каждое слияние
+// получает номер n+i, где i — его позиция в отсортированном списке
+type unionFind struct {
+	parent []int
+	id     []int // Номер кластера для корня
+	size   []int
+	next   int
+}
+
+func newUnionFind(n int) *unionFind {
+	uf := &unionFind{parent: make([]int, n), id: make([]int, n), size: make([]int, n), next: n}
+	for i := range uf.parent {
+		uf.parent[i] = i
+		uf.id[i] = i
+		uf.size[i] = 1
+	}
+	return uf
+}
+
+func (uf *unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+	return i
+}
+
+// merge объединяет кластеры, содержащие элементы a и b, и возвращает запись слияния
+func (uf *unionFind) merge(a, b int, distance float64) Merge {
+	ra, rb := uf.find(a), uf.find(b)
+	m := Merge{Left: uf.id[ra], Right: uf.id[rb], Distance: distance, Size: uf.size[ra] + uf.size[rb]}
+	if m.Left > m.Right {
+		m.Left, m.Right = m.Right, m.Left
+	}
+	uf.parent[ra] = rb
+	uf.size[rb] = m.Size
+	uf.id[rb] = uf.next
+	uf.next++
+	return m
+}
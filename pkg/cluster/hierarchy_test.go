@@ -0,0 +1,120 @@
+package cluster
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// angles возвращает единичные векторы на плоскости под заданными углами (в градусах)
+func angles(degrees ...float64) [][]float64 {
+	vecs := make([][]float64, len(degrees))
+	for i, d := range degrees {
+		r := d * math.Pi / 180
+		vecs[i] = []float64{math.Cos(r), math.Sin(r)}
+	}
+	return vecs
+}
+
+func TestAgglomerative(t *testing.T) {
+	// Две пары векторов: внутри пары угол 10°, между парами — от 80° до 100°
+	vecs := angles(0, 10, 90, 100)
+	close := 1 - math.Cos(10*math.Pi/180)
+	tests := []struct {
+		linkage Linkage
+		heights []float64
+	}{
+		{Average, []float64{close, close, 1}},
+		{Complete, []float64{close, close, 1 - math.Cos(100*math.Pi/180)}},
+		// Для Ward высота пары — евклидово расстояние, корня — √2 · расстояние между центрами пар
+		{Ward, []float64{2 * math.Sin(5*math.Pi/180), 2 * math.Sin(5*math.Pi/180), math.Sqrt2 * 2 * math.Cos(5*math.Pi/180) * math.Sin(math.Pi/4)}},
+	}
+	for _, tt := range tests {
+		d, err := Agglomerative(vecs, tt.linkage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Leaves != 4 || len(d.Merges) != 3 {
+			t.Fatalf("%s: дерево %+v", tt.linkage, d)
+		}
+		for i, m := range d.Merges {
+			if math.Abs(m.Distance-tt.heights[i]) > 1e-9 {
+				t.Errorf("%s: высота слияния %d = %.9f, ожидалось %.9f", tt.linkage, i, m.Distance, tt.heights[i])
+			}
+		}
+		pairs := []Merge{d.Merges[0], d.Merges[1]}
+		if pairs[0].Left > pairs[1].Left {
+			pairs[0], pairs[1] = pairs[1], pairs[0]
+		}
+		if pairs[0].Left != 0 || pairs[0].Right != 1 || pairs[1].Left != 2 || pairs[1].Right != 3 {
+			t.Errorf("%s: слияния пар %+v", tt.linkage, pairs)
+		}
+		if root := d.Merges[2]; root.Left != 4 || root.Right != 5 || root.Size != 4 {
+			t.Errorf("%s: корень %+v", tt.linkage, root)
+		}
+	}
+}
+
+func TestDendrogramCut(t *testing.T) {
+	d, err := Agglomerative(angles(0, 90, 10, 100, 45), Average)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		got  []int
+		want []int
+	}{
+		{"k = 1", d.CutK(1), []int{0, 0, 0, 0, 0}},
+		{"k = 2", d.CutK(2), []int{0, 1, 0, 1, 0}},
+		{"k = 5", d.CutK(5), []int{0, 1, 2, 3, 4}},
+		{"k = 10", d.CutK(10), []int{0, 1, 2, 3, 4}},
+		{"h = 0.1", d.CutDistance(0.1), []int{0, 1, 0, 1, 2}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDendrogramExport(t *testing.T) {
+	d, err := Agglomerative(angles(0, 10, 90), Average)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newick := d.Newick([]string{"кот", "кошка", "мяч (футбол)"})
+	// Лист 2 идёт раньше узла пары (номер 3); длина ветви — разность высот родителя и узла
+	close := 1 - math.Cos(10*math.Pi/180)
+	far := (2 - math.Cos(90*math.Pi/180) - math.Cos(80*math.Pi/180)) / 2
+	want := "('мяч (футбол)':" + format(far) + ",(кот:" + format(close) + ",кошка:" + format(close) + "):" + format(far-close) + ");\n"
+	if newick != want {
+		t.Errorf("Newick = %q, ожидалось %q", newick, want)
+	}
+
+	tree := d.Tree(nil)
+	if tree.Size != 3 || len(tree.Children) != 2 || tree.Children[0].Name != "2" || tree.Children[1].Size != 2 {
+		t.Errorf("дерево %+v", tree)
+	}
+
+	if _, err := Agglomerative([][]float64{{1, 0}}, Average); err == nil {
+		t.Error("ожидалась ошибка для одного объекта")
+	}
+}
+
+func TestParseLinkage(t *testing.T) {
+	for _, name := range []string{"average", "complete", "ward"} {
+		if got, err := ParseLinkage(strings.ToUpper(name)); err != nil || string(got) != name {
+			t.Errorf("ParseLinkage(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseLinkage("single"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного способа связи")
+	}
+}
+
+func format(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
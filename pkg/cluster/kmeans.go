@@ -0,0 +1,397 @@
+package cluster
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Metric — мера расстояния для k-means
+type Metric string
+
+// Поддерживаемые меры
+const (
+	Euclidean Metric = "euclidean" // Квадрат евклидова расстояния
+	Cosine    Metric = "cosine"    // 1 − косинусное сходство (сферический k-means)
+)
+
+// ParseMetric разбирает название меры
+func ParseMetric(name string) (Metric, error) {
+	switch Metric(strings.ToLower(strings.TrimSpace(name))) {
+	case Euclidean, "l2", "":
+		return Euclidean, nil
+	case Cosine, "spherical":
+		return Cosine, nil
+	}
+	return "", fmt.Errorf("неизвестная мера расстояния: %s (euclidean, cosine)", name)
+}
+
+// chunkSize — размер блока точек при параллельном назначении кластеров. Блоки
+// фиксированного размера суммируются по порядку, поэтому результат не зависит
+// от числа ядер.
+const chunkSize = 4096
+
+// KMeansOptions задаёт параметры k-means
+type KMeansOptions struct {
+	K         int     // Число кластеров
+	MaxIter   int     // Максимум итераций (для мини-пакетного режима — число пакетов)
+	Tol       float64 // Порог сдвига центроидов для остановки
+	NInit     int     // Число перезапусков с разной инициализацией; выбирается лучший
+	Seed      int64   // Зерно генератора случайных чисел
+	Metric    Metric  // Мера расстояния
+	BatchSize int     // Размер мини-пакета; 0 — обычный (полный) k-means
+	Workers   int     // Число потоков; 0 — по числу ядер
+}
+
+// DefaultKMeansOptions возвращает параметры по умолчанию для k кластеров
+func DefaultKMeansOptions(k int) KMeansOptions {
+	return KMeansOptions{K: k, MaxIter: 300, Tol: 1e-4, NInit: 3, Seed: 1, Metric: Euclidean}
+}
+
+// KMeansResult — результат k-means
+type KMeansResult struct {
+	Labels     []int       // Кластер каждой точки
+	Centroids  [][]float64 // Центроиды (для Cosine — единичной длины)
+	Inertia    float64     // Сумма расстояний точек до их центроидов
+	Iterations int         // Число итераций лучшего запуска
+	Metric     Metric
+}
+
+// KMeans кластеризует точки алгоритмом k-means с инициализацией k-means++.
+// При Metric = Cosine точки нормируются, а центроиды после каждого шага
+// приводятся к единичной длине (сферический k-means). При BatchSize > 0
+// центроиды обновляются по случайным мини-пакетам, что позволяет
+// обрабатывать миллионы точек. Исходные данные не изменяются.
+func KMeans(data [][]float64, opts KMeansOptions) (*KMeansResult, error) {
+	if opts.K <= 0 {
+		return nil, fmt.Errorf("число кластеров должно быть положительным")
+	}
+	if len(data) < opts.K {
+		return nil, fmt.Errorf("точек (%d) меньше, чем кластеров (%d)", len(data), opts.K)
+	}
+	if opts.MaxIter <= 0 {
+		opts.MaxIter = 300
+	}
+	if opts.NInit <= 0 {
+		opts.NInit = 1
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Metric == "" {
+		opts.Metric = Euclidean
+	}
+
+	points := data
+	if opts.Metric == Cosine {
+		points = make([][]float64, len(data))
+		for i, vec := range data {
+			points[i] = vectors.Normalize(vec)
+		}
+	}
+
+	var best *KMeansResult
+	for run := 0; run < opts.NInit; run++ {
+		rng := rand.New(rand.NewSource(opts.Seed + int64(run)))
+		var result *KMeansResult
+		if opts.BatchSize > 0 {
+			result = miniBatchKMeans(points, opts, rng)
+		} else {
+			result = lloyd(points, opts, rng)
+		}
+		if best == nil || result.Inertia < best.Inertia {
+			best = result
+		}
+	}
+	return best, nil
+}
+
+// Predict возвращает ближайший к вектору центроид
+func (r *KMeansResult) Predict(vec []float64) int {
+	if r.Metric == Cosine {
+		vec = vectors.Normalize(vec)
+	}
+	label, _ := nearestCentroid(vec, r.Centroids, r.Metric)
+	return label
+}
+
+// lloyd — обычный k-means: назначение точек и пересчёт центроидов до сходимости
+func lloyd(points [][]float64, opts KMeansOptions, rng *rand.Rand) *KMeansResult {
+	centroids := kmeansPlusPlus(points, opts.K, opts.Metric, rng)
+	labels := make([]int, len(points))
+	dim := len(points[0])
+
+	var inertia float64
+	iter := 0
+	for iter < opts.MaxIter {
+		iter++
+		var sums [][]float64
+		var counts []int
+		inertia, sums, counts = assign(points, centroids, labels, opts.Metric, opts.Workers, true)
+
+		newCentroids := make([][]float64, opts.K)
+		reseeded := make(map[int]bool)
+		for c := range newCentroids {
+			if counts[c] == 0 {
+				// Пустой кластер получает точку, хуже всего описанную своим центроидом;
+				// разные пустые кластеры получают разные точки
+				newCentroids[c] = append([]float64(nil), points[farthestPoint(points, centroids, labels, opts.Metric, reseeded)]...)
+				continue
+			}
+			newCentroids[c] = make([]float64, dim)
+			for d := range sums[c] {
+				newCentroids[c][d] = sums[c][d] / float64(counts[c])
+			}
+			if opts.Metric == Cosine {
+				newCentroids[c] = vectors.Normalize(newCentroids[c])
+			}
+		}
+
+		shift := maxShift(centroids, newCentroids)
+		centroids = newCentroids
+		if shift <= opts.Tol {
+			break
+		}
+	}
+
+	inertia, _, _ = assign(points, centroids, labels, opts.Metric, opts.Workers, false)
+	return &KMeansResult{Labels: labels, Centroids: centroids, Inertia: inertia, Iterations: iter, Metric: opts.Metric}
+}
+
+// miniBatchKMeans обновляет центроиды по случайным мини-пакетам с убывающим шагом
+// 1/n, где n — число точек, уже отнесённых к центроиду
+func miniBatchKMeans(points [][]float64, opts KMeansOptions, rng *rand.Rand) *KMeansResult {
+	// Инициализация по случайной подвыборке, чтобы не проходить все точки k раз
+	initSize := min(len(points), max(3*opts.BatchSize, opts.K))
+	sample := make([][]float64, initSize)
+	for i, idx := range rng.Perm(len(points))[:initSize] {
+		sample[i] = points[idx]
+	}
+	centroids := kmeansPlusPlus(sample, opts.K, opts.Metric, rng)
+	counts := make([]int, opts.K)
+
+	batchSize := min(opts.BatchSize, len(points))
+	batch := make([]int, batchSize)
+	batchLabels := make([]int, batchSize)
+	iter := 0
+	for iter < opts.MaxIter {
+		iter++
+		for i := range batch {
+			batch[i] = rng.Intn(len(points))
+		}
+		for i, idx := range batch {
+			batchLabels[i], _ = nearestCentroid(points[idx], centroids, opts.Metric)
+		}
+
+		previous := make([][]float64, len(centroids))
+		for c := range centroids {
+			previous[c] = append([]float64(nil), centroids[c]...)
+		}
+		for i, idx := range batch {
+			c := batchLabels[i]
+			counts[c]++
+			eta := 1 / float64(counts[c])
+			for d, v := range points[idx] {
+				centroids[c][d] += eta * (v - centroids[c][d])
+			}
+		}
+		if opts.Metric == Cosine {
+			for c := range centroids {
+				centroids[c] = vectors.Normalize(centroids[c])
+			}
+		}
+		if maxShift(previous, centroids) <= opts.Tol {
+			break
+		}
+	}
+
+	labels := make([]int, len(points))
+	inertia, _, _ := assign(points, centroids, labels, opts.Metric, opts.Workers, false)
+	return &KMeansResult{Labels: labels, Centroids: centroids, Inertia: inertia, Iterations: iter, Metric: opts.Metric}
+}
+
+// kmeansPlusPlus выбирает начальные центроиды: первый — случайная точка, каждый
+// следующий — точка с вероятностью, пропорциональной расстоянию до ближайшего
+// из уже выбранных. Центроиды — копии точек, а не ссылки на них.
+func kmeansPlusPlus(points [][]float64, k int, metric Metric, rng *rand.Rand) [][]float64 {
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, append([]float64(nil), points[rng.Intn(len(points))]...))
+
+	dist := make([]float64, len(points))
+	for i, p := range points {
+		dist[i] = distance(p, centroids[0], metric)
+	}
+	for len(centroids) < k {
+		var total float64
+		for _, d := range dist {
+			total += d
+		}
+		var next int
+		if total > 0 {
+			next = pickWeighted(dist, rng.Float64()*total)
+		} else {
+			// Все точки совпадают с центроидами — берём любую
+			next = rng.Intn(len(points))
+		}
+		c := append([]float64(nil), points[next]...)
+		centroids = append(centroids, c)
+		for i, p := range points {
+			if d := distance(p, c, metric); d < dist[i] {
+				dist[i] = d
+			}
+		}
+	}
+	return centroids
+}
+
+// pickWeighted возвращает индекс, на который приходится target в накопленной
+// сумме весов. Из-за ошибок округления target может остаться положительным
+// после всех вычитаний — тогда возвращается последний индекс с ненулевым весом,
+// а не последний вообще: точка с нулевым весом уже совпадает с центроидом.
+func pickWeighted(weights []float64, target float64) int {
+	next := 0
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		next = i
+		target -= w
+		if target <= 0 {
+			break
+		}
+	}
+	return next
+}
+
+// assign относит точки к ближайшим центроидам параллельно по блокам. Возвращает
+// сумму расстояний и, если нужно, суммы точек и их число по кластерам.
+func assign(points, centroids [][]float64, labels []int, metric Metric, workers int, accumulate bool) (float64, [][]float64, []int) {
+	chunks := (len(points) + chunkSize - 1) / chunkSize
+	type partial struct {
+		inertia float64
+		sums    [][]float64
+		counts  []int
+	}
+	partials := make([]partial, chunks)
+
+	jobs := make(chan int, chunks)
+	for c := 0; c < chunks; c++ {
+		jobs <- c
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				p := partial{}
+				if accumulate {
+					p.sums = make([][]float64, len(centroids))
+					p.counts = make([]int, len(centroids))
+				}
+				for i := c * chunkSize; i < min((c+1)*chunkSize, len(points)); i++ {
+					label, d := nearestCentroid(points[i], centroids, metric)
+					labels[i] = label
+					p.inertia += d
+					if accumulate {
+						if p.sums[label] == nil {
+							p.sums[label] = make([]float64, len(points[i]))
+						}
+						for j, v := range points[i] {
+							p.sums[label][j] += v
+						}
+						p.counts[label]++
+					}
+				}
+				partials[c] = p
+			}
+		}()
+	}
+	wg.Wait()
+
+	var inertia float64
+	var sums [][]float64
+	var counts []int
+	if accumulate {
+		sums = make([][]float64, len(centroids))
+		for c := range sums {
+			sums[c] = make([]float64, len(centroids[c]))
+		}
+		counts = make([]int, len(centroids))
+	}
+	for _, p := range partials {
+		inertia += p.inertia
+		if !accumulate {
+			continue
+		}
+		for c := range p.sums {
+			counts[c] += p.counts[c]
+			for j, v := range p.sums[c] {
+				sums[c][j] += v
+			}
+		}
+	}
+	return inertia, sums, counts
+}
+
+// nearestCentroid возвращает ближайший центроид и расстояние до него
+func nearestCentroid(p []float64, centroids [][]float64, metric Metric) (int, float64) {
+	best, bestDist := 0, math.Inf(1)
+	for c, centroid := range centroids {
+		if d := distance(p, centroid, metric); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best, bestDist
+}
+
+// farthestPoint возвращает точку, наиболее удалённую от своего центроида, среди
+// ещё не выбранных (used), и отмечает её выбранной
+func farthestPoint(points, centroids [][]float64, labels []int, metric Metric, used map[int]bool) int {
+	best, bestDist := 0, -1.0
+	for i, p := range points {
+		if used[i] {
+			continue
+		}
+		if d := distance(p, centroids[labels[i]], metric); d > bestDist {
+			best, bestDist = i, d
+		}
+	}
+	used[best] = true
+	return best
+}
+
+// distance вычисляет расстояние между точкой и центроидом. Для Cosine векторы
+// уже нормированы, поэтому расстояние равно 1 − скалярное произведение.
+func distance(a, b []float64, metric Metric) float64 {
+	if metric == Cosine {
+		return 1 - linalg.Dot(a, b)
+	}
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return sum
+}
+
+// maxShift возвращает наибольший сдвиг центроида между итерациями
+func maxShift(a, b [][]float64) float64 {
+	var shift float64
+	for c := range a {
+		var sum float64
+		for d := range a[c] {
+			diff := a[c][d] - b[c][d]
+			sum += diff * diff
+		}
+		shift = math.Max(shift, math.Sqrt(sum))
+	}
+	return shift
+}
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// blobs возвращает по size точек с нормальным разбросом spread вокруг каждого центра
+// и номер центра каждой точки
+func blobs(centers [][]float64, size int, spread float64, seed int64) ([][]float64, []int) {
+	rng := rand.New(rand.NewSource(seed))
+	var points [][]float64
+	var labels []int
+	for c, center := range centers {
+		for i := 0; i < size; i++ {
+			p := make([]float64, len(center))
+			for d, v := range center {
+				p[d] = v + spread*rng.NormFloat64()
+			}
+			points = append(points, p)
+			labels = append(labels, c)
+		}
+	}
+	return points, labels
+}
+
+// samePartition проверяет, что разбиения совпадают с точностью до перенумерации
+func samePartition(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ab, ba := make(map[int]int), make(map[int]int)
+	for i := range a {
+		if x, ok := ab[a[i]]; ok && x != b[i] {
+			return false
+		}
+		if y, ok := ba[b[i]]; ok && y != a[i] {
+			return false
+		}
+		ab[a[i]], ba[b[i]] = b[i], a[i]
+	}
+	return true
+}
+
+func TestKMeans(t *testing.T) {
+	points, truth := blobs([][]float64{{0, 0}, {10, 0}, {0, 10}}, 30, 0.5, 1)
+	tests := []struct {
+		name string
+		opts KMeansOptions
+	}{
+		{"lloyd", DefaultKMeansOptions(3)},
+		{"workers", KMeansOptions{K: 3, Seed: 2, Workers: 1}},
+		{"mini-batch", KMeansOptions{K: 3, Seed: 1, BatchSize: 16, MaxIter: 200, NInit: 3}},
+	}
+	for _, tt := range tests {
+		result, err := KMeans(points, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !samePartition(result.Labels, truth) {
+			t.Errorf("%s: разбиение не совпадает с исходными группами: %v", tt.name, result.Labels)
+		}
+		// Ожидаемая инерция — 90 точек × 2 измерения × 0.25 ≈ 45
+		if len(result.Centroids) != 3 || result.Inertia <= 0 || result.Inertia > 90 {
+			t.Errorf("%s: центроидов %d, инерция %.3f", tt.name, len(result.Centroids), result.Inertia)
+		}
+		if label := result.Predict([]float64{9.5, 0.3}); label != result.Labels[30] {
+			t.Errorf("%s: точка (9.5, 0.3) отнесена к кластеру %d", tt.name, label)
+		}
+	}
+}
+
+func TestKMeansCosine(t *testing.T) {
+	// Направления различаются, а длины — нет: евклидова мера разделила бы точки по длине
+	var points [][]float64
+	var truth []int
+	for i := 0; i < 20; i++ {
+		scale := 1 + float64(i)
+		points = append(points, []float64{scale, 0.05 * scale}, []float64{0.05 * scale, scale})
+		truth = append(truth, 0, 1)
+	}
+	result, err := KMeans(points, KMeansOptions{K: 2, Metric: Cosine, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !samePartition(result.Labels, truth) {
+		t.Errorf("сферический k-means разделил точки неверно: %v", result.Labels)
+	}
+	for c, centroid := range result.Centroids {
+		if norm := math.Hypot(centroid[0], centroid[1]); math.Abs(norm-1) > 1e-9 {
+			t.Errorf("длина центроида %d = %.6f", c, norm)
+		}
+	}
+	if points[0][0] != 1 {
+		t.Error("исходные данные изменены")
+	}
+}
+
+func TestKMeansDeterministic(t *testing.T) {
+	points, _ := blobs([][]float64{{0, 0, 0}, {5, 5, 5}}, 50, 2, 3)
+	a, _ := KMeans(points, KMeansOptions{K: 4, Seed: 7, Workers: 1})
+	b, _ := KMeans(points, KMeansOptions{K: 4, Seed: 7, Workers: 8})
+	if a.Inertia != b.Inertia {
+		t.Errorf("инерция зависит от числа потоков: %v и %v", a.Inertia, b.Inertia)
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	weights := []float64{0, 1, 0, 2, 0, 0}
+	tests := []struct {
+		target float64
+		want   int
+	}{
+		{0.5, 1},
+		{1, 1},
+		{1.5, 3},
+		{3, 3},
+		// target больше суммы весов (ошибка округления) — последний ненулевой вес,
+		// а не последний индекс, чья точка уже совпадает с центроидом
+		{3 + 1e-12, 3},
+	}
+	for _, tt := range tests {
+		if got := pickWeighted(weights, tt.target); got != tt.want {
+			t.Errorf("pickWeighted(%v) = %d, ожидалось %d", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestKMeansErrors(t *testing.T) {
+	points := [][]float64{{0}, {1}}
+	if _, err := KMeans(points, KMeansOptions{K: 0}); err == nil {
+		t.Error("ожидалась ошибка для k = 0")
+	}
+	if _, err := KMeans(points, KMeansOptions{K: 3}); err == nil {
+		t.Error("ожидалась ошибка для k больше числа точек")
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	for name, want := range map[string]Metric{"": Euclidean, "L2": Euclidean, "cosine": Cosine, " spherical ": Cosine} {
+		if got, err := ParseMetric(name); err != nil || got != want {
+			t.Errorf("ParseMetric(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseMetric("manhattan"); err == nil {
+		t.Error("ожидалась ошибка для неизвестной меры")
+	}
+}
@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Term — характерный термин кластера и его вес c-TF-IDF
+type Term struct {
+	Term  string  `json:"term"`
+	Score float64 `json:"score"`
+}
+
+// Representative — документ, ближайший к центроиду кластера
+type Representative struct {
+	ID         string  `json:"id"`
+	Text       string  `json:"text"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Description — человекочитаемое описание кластера
+type Description struct {
+	Cluster   int                `json:"cluster"`
+	Size      int                `json:"size"`
+	Words     []vectors.Neighbor `json:"words"`     // Слова словаря, ближайшие к центроиду
+	Terms     []Term             `json:"terms"`     // Самые характерные термины (c-TF-IDF)
+	Documents []Representative   `json:"documents"` // Документы, ближайшие к центроиду
+}
+
+// DescribeOptions задаёт объём описаний
+type DescribeOptions struct {
+	TopWords   int                 // Число ближайших слов словаря
+	TopTerms   int                 // Число характерных терминов
+	TopDocs    int                 // Число представительных документов
+	MaxTextLen int                 // Максимальная длина текста документа в символах (0 — без ограничения)
+	StopWords  map[string]struct{} // Слова, которые не считаются терминами
+}
+
+// DefaultDescribeOptions возвращает параметры описаний по умолчанию
+func DefaultDescribeOptions() DescribeOptions {
+	return DescribeOptions{TopWords: 10, TopTerms: 10, TopDocs: 5, MaxTextLen: 300}
+}
+
+// Describe описывает кластеры: ближайшими к центроиду словами модели, терминами
+// с наибольшим c-TF-IDF (частота термина в кластере, взвешенная редкостью
+// термина в остальных кластерах) и документами, ближайшими к центроиду
+// по косинусному сходству. texts — очищенные тексты объектов, vecs — их векторы.
+// Объекты-шум (Noise) в описания не попадают.
+func Describe(model *vectors.Model, ids, texts []string, vecs [][]float64, labels []int, centroids [][]float64, opts DescribeOptions) []Description {
+	k := len(centroids)
+	descriptions := make([]Description, k)
+	for c := range descriptions {
+		descriptions[c] = Description{Cluster: c, Words: []vectors.Neighbor{}, Terms: []Term{}, Documents: []Representative{}}
+	}
+	for _, l := range labels {
+		if l != Noise {
+			descriptions[l].Size++
+		}
+	}
+
+	terms := classTFIDF(texts, labels, k, opts.StopWords)
+	for c := range descriptions {
+		if model != nil && opts.TopWords > 0 {
+			if words := model.Nearest(centroids[c], opts.TopWords, nil); words != nil {
+				descriptions[c].Words = words
+			}
+		}
+		if len(terms[c]) > opts.TopTerms {
+			terms[c] = terms[c][:opts.TopTerms]
+		}
+		descriptions[c].Terms = append(descriptions[c].Terms, terms[c]...)
+	}
+
+	// Для каждого кластера — документы с наибольшим сходством с его центроидом
+	normed := make([][]float64, k)
+	for c := range centroids {
+		normed[c] = vectors.Normalize(centroids[c])
+	}
+	for i, vec := range vecs {
+		c := labels[i]
+		if c == Noise {
+			continue
+		}
+		sim := linalg.Dot(vectors.Normalize(vec), normed[c])
+		docs := descriptions[c].Documents
+		if len(docs) >= opts.TopDocs && (opts.TopDocs <= 0 || sim <= docs[len(docs)-1].Similarity) {
+			continue
+		}
+		pos := sort.Search(len(docs), func(j int) bool { return docs[j].Similarity < sim })
+		docs = append(docs, Representative{})
+		copy(docs[pos+1:], docs[pos:])
+		docs[pos] = Representative{ID: ids[i], Text: textprocessor.Truncate(texts[i], opts.MaxTextLen), Similarity: sim}
+		if len(docs) > opts.TopDocs {
+			docs = docs[:opts.TopDocs]
+		}
+		descriptions[c].Documents = docs
+	}
+	return descriptions
+}
+
+// classTFIDF вычисляет c-TF-IDF: все тексты кластера объединяются в один документ,
+// tf — доля термина в нём, idf = ln(1 + A / f), где A — среднее число слов
+// в кластере, f — частота термина во всех кластерах. Термины каждого кластера
+// возвращаются по убыванию веса.
+func classTFIDF(texts []string, labels []int, k int, stopWords map[string]struct{}) [][]Term {
+	counts := make([]map[string]int, k)
+	for c := range counts {
+		counts[c] = make(map[string]int)
+	}
+	totals := make([]int, k)
+	frequency := make(map[string]int)
+	for i, text := range texts {
+		c := labels[i]
+		if c == Noise {
+			continue
+		}
+		for _, word := range strings.Fields(text) {
+			if _, stop := stopWords[word]; stop {
+				continue
+			}
+			counts[c][word]++
+			totals[c]++
+			frequency[word]++
+		}
+	}
+
+	var all int
+	for _, t := range totals {
+		all += t
+	}
+	average := float64(all) / float64(k)
+
+	result := make([][]Term, k)
+	for c := range counts {
+		for term, n := range counts[c] {
+			tf := float64(n) / float64(totals[c])
+			idf := math.Log(1 + average/float64(frequency[term]))
+			result[c] = append(result[c], Term{Term: term, Score: tf * idf})
+		}
+		sort.Slice(result[c], func(i, j int) bool {
+			a, b := result[c][i], result[c][j]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.Term < b.Term
+		})
+	}
+	return result
+}
+
+// WriteMarkdown выводит описания кластеров в формате Markdown
+func WriteMarkdown(w io.Writer, descriptions []Description) error {
+	writer := bufio.NewWriter(w)
+	for _, d := range descriptions {
+		fmt.Fprintf(writer, "## Кластер %d (%d)\n\n", d.Cluster, d.Size)
+		if len(d.Words) > 0 {
+			words := make([]string, len(d.Words))
+			for i, n := range d.Words {
+				words[i] = n.Word
+			}
+			fmt.Fprintf(writer, "**Ближайшие слова:** %s\n\n", strings.Join(words, ", "))
+		}
+		if len(d.Terms) > 0 {
+			terms := make([]string, len(d.Terms))
+			for i, t := range d.Terms {
+				terms[i] = t.Term
+			}
+			fmt.Fprintf(writer, "**Характерные термины:** %s\n\n", strings.Join(terms, ", "))
+		}
+		if len(d.Documents) > 0 {
+			fmt.Fprintln(writer, "**Представительные документы:**")
+			fmt.Fprintln(writer)
+			for _, doc := range d.Documents {
+				fmt.Fprintf(writer, "- `%s` (%.3f): %s\n", doc.ID, doc.Similarity, doc.Text)
+			}
+			fmt.Fprintln(writer)
+		}
+	}
+	return writer.Flush()
+}
+
+// SaveDescriptions сохраняет описания кластеров в формате Markdown (.md) или JSON (иначе)
+func SaveDescriptions(filename string, descriptions []Description) error {
+	if !strings.HasSuffix(filename, ".md") {
+		return saveJSON(filename, descriptions)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	if err := WriteMarkdown(file, descriptions); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"bytes"
+	"glove-pipeline/pkg/vectors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	model := vectors.New(
+		[]string{"кот", "кошка", "мяч", "гол"},
+		[][]float64{{1, 0}, {0.9, 0.1}, {0.1, 0.9}, {0, 1}},
+	)
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	texts := []string{
+		"кот и кошка",
+		"кошка спит и кот спит",
+		"матч и гол",
+		"гол в ворота и мяч",
+		"мяч летит мимо ворот после долгого и красивого удара",
+		"шум",
+	}
+	vecs := [][]float64{{1, 0}, {0.95, 0.05}, {0.05, 0.95}, {0, 1}, {0.3, 0.7}, {1, 1}}
+	labels := []int{0, 0, 1, 1, 1, Noise}
+	centroids, _ := means(vecs[:5], labels[:5], 2)
+
+	opts := DescribeOptions{TopWords: 2, TopTerms: 3, TopDocs: 3, MaxTextLen: 20, StopWords: map[string]struct{}{"и": {}}}
+	descriptions := Describe(model, ids, texts, vecs, labels, centroids, opts)
+	if len(descriptions) != 2 || descriptions[0].Size != 2 || descriptions[1].Size != 3 {
+		t.Fatalf("описания %+v", descriptions)
+	}
+
+	cats, sport := descriptions[0], descriptions[1]
+	if len(cats.Words) != 2 || cats.Words[0].Word != "кот" || sport.Words[0].Word != "мяч" {
+		t.Errorf("ближайшие слова %v и %v", cats.Words, sport.Words)
+	}
+	// Все термины первого кластера встречаются по два раза и с равным весом упорядочены по алфавиту
+	var terms []string
+	for _, term := range cats.Terms {
+		terms = append(terms, term.Term)
+	}
+	if strings.Join(terms, " ") != "кот кошка спит" {
+		t.Errorf("термины первого кластера %v", cats.Terms)
+	}
+	for _, d := range descriptions {
+		for _, term := range d.Terms {
+			if term.Term == "и" || term.Term == "шум" {
+				t.Errorf("кластер %d: лишний термин %s", d.Cluster, term.Term)
+			}
+		}
+		for i := 1; i < len(d.Terms); i++ {
+			if d.Terms[i].Score > d.Terms[i-1].Score {
+				t.Errorf("кластер %d: термины не упорядочены %v", d.Cluster, d.Terms)
+			}
+		}
+	}
+
+	if len(cats.Documents) != 2 || len(sport.Documents) != 3 || sport.Documents[2].ID != "e" {
+		t.Fatalf("документы %+v и %+v", cats.Documents, sport.Documents)
+	}
+	for _, d := range descriptions {
+		for _, doc := range d.Documents {
+			if doc.ID == "f" {
+				t.Errorf("документ-шум в описании кластера %d", d.Cluster)
+			}
+			if doc.ID == "e" && (len([]rune(doc.Text)) > opts.MaxTextLen+1 || !strings.HasSuffix(doc.Text, "…") || !strings.HasPrefix(texts[4], strings.TrimSuffix(doc.Text, "…"))) {
+				t.Errorf("длинный текст не сокращён по границе слова: %q", doc.Text)
+			}
+		}
+	}
+}
+
+func TestClassTFIDF(t *testing.T) {
+	texts := []string{"общий редкий", "общий общий", "общий другой"}
+	terms := classTFIDF(texts, []int{0, 0, 1}, 2, nil)
+	if len(terms[0]) != 2 || terms[0][0].Term != "общий" {
+		t.Errorf("термины первого кластера %v", terms[0])
+	}
+	// Термин только второго кластера весит больше общего для обоих кластеров
+	if terms[1][0].Term != "другой" || terms[1][0].Score <= terms[1][1].Score {
+		t.Errorf("термины второго кластера %v", terms[1])
+	}
+}
+
+func TestSaveDescriptions(t *testing.T) {
+	descriptions := []Description{{
+		Cluster:   0,
+		Size:      2,
+		Words:     []vectors.Neighbor{{Word: "кот", Similarity: 0.9}},
+		Terms:     []Term{{Term: "кошка", Score: 0.5}},
+		Documents: []Representative{{ID: "a", Text: "кот и кошка", Similarity: 0.99}},
+	}}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, descriptions); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Кластер 0 (2)", "**Ближайшие слова:** кот", "**Характерные термины:** кошка", "- `a` (0.990): кот и кошка"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("в Markdown нет %q:\n%s", want, buf.String())
+		}
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"clusters.md", "clusters.json"} {
+		if err := SaveDescriptions(filepath.Join(dir, name), descriptions); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// Scores — оценки качества разбиения на кластеры
+type Scores struct {
+	K                int     `json:"k"`
+	Inertia          float64 `json:"inertia"`           // Сумма расстояний точек до центроидов (меньше — лучше)
+	Silhouette       float64 `json:"silhouette"`        // Силуэт от −1 до 1 (больше — лучше)
+	DaviesBouldin    float64 `json:"davies_bouldin"`    // Индекс Дэвиса — Болдина (меньше — лучше)
+	CalinskiHarabasz float64 `json:"calinski_harabasz"` // Индекс Калински — Харабаза (больше — лучше)
+}
+
+// Evaluate вычисляет оценки разбиения. Для Cosine точки нормируются, и в силуэте
+// и инерции расстояние равно 1 − косинусное сходство; индексы Дэвиса — Болдина
+// и Калински — Харабаза всегда считаются в евклидовой метрике. Силуэт требует
+// попарных расстояний, поэтому при sample > 0 он считается по случайной
+// подвыборке из sample точек (с зерном seed). Объекты-шум (Noise) не учитываются.
+func Evaluate(data [][]float64, labels []int, metric Metric, sample int, seed int64) Scores {
+	data, labels = withoutNoise(data, labels)
+	if len(data) == 0 {
+		return Scores{}
+	}
+	points := data
+	if metric == Cosine {
+		points = make([][]float64, len(data))
+		for i, vec := range data {
+			points[i] = vectors.Normalize(vec)
+		}
+	}
+
+	k := 0
+	for _, l := range labels {
+		k = max(k, l+1)
+	}
+	centroids, counts := means(points, labels, k)
+
+	scores := Scores{K: k}
+	for i, p := range points {
+		centroid := centroids[labels[i]]
+		if metric == Cosine {
+			centroid = vectors.Normalize(centroid)
+		}
+		scores.Inertia += distance(p, centroid, metric)
+	}
+	scores.Silhouette = silhouette(points, labels, k, metric, sample, seed)
+	scores.DaviesBouldin = daviesBouldin(points, labels, centroids)
+	scores.CalinskiHarabasz = calinskiHarabasz(points, labels, centroids, counts)
+	return scores
+}
+
+// withoutNoise отбрасывает объекты с меткой Noise
+func withoutNoise(data [][]float64, labels []int) ([][]float64, []int) {
+	for _, l := range labels {
+		if l != Noise {
+			continue
+		}
+		var points [][]float64
+		var kept []int
+		for i, l := range labels {
+			if l != Noise {
+				points = append(points, data[i])
+				kept = append(kept, l)
+			}
+		}
+		return points, kept
+	}
+	return data, labels
+}
+
+// Centroids возвращает средние векторы кластеров по меткам; объекты-шум не учитываются
+func Centroids(data [][]float64, labels []int) [][]float64 {
+	data, labels = withoutNoise(data, labels)
+	k := 0
+	for _, l := range labels {
+		k = max(k, l+1)
+	}
+	if k == 0 {
+		return nil
+	}
+	centroids, _ := means(data, labels, k)
+	return centroids
+}
+
+// means вычисляет центры кластеров и их размеры
+func means(points [][]float64, labels []int, k int) ([][]float64, []int) {
+	dim := len(points[0])
+	centroids := make([][]float64, k)
+	counts := make([]int, k)
+	for c := range centroids {
+		centroids[c] = make([]float64, dim)
+	}
+	for i, p := range points {
+		c := labels[i]
+		counts[c]++
+		for d, v := range p {
+			centroids[c][d] += v
+		}
+	}
+	for c := range centroids {
+		if counts[c] == 0 {
+			continue
+		}
+		for d := range centroids[c] {
+			centroids[c][d] /= float64(counts[c])
+		}
+	}
+	return centroids, counts
+}
+
+// pointDistance — расстояние между точками для силуэта и индекса Дэвиса — Болдина (не квадрат)
+func pointDistance(a, b []float64, metric Metric) float64 {
+	if metric == Cosine {
+		return 1 - linalg.Dot(a, b)
+	}
+	return math.Sqrt(distance(a, b, Euclidean))
+}
+
+// silhouette вычисляет средний силуэт: для точки a — среднее расстояние до своего
+// кластера, b — наименьшее среднее расстояние до чужого, силуэт = (b − a) / max(a, b)
+func silhouette(points [][]float64, labels []int, k int, metric Metric, sample int, seed int64) float64 {
+	if k < 2 {
+		return 0
+	}
+	idx := make([]int, len(points))
+	for i := range idx {
+		idx[i] = i
+	}
+	if sample > 0 && sample < len(points) {
+		idx = rand.New(rand.NewSource(seed)).Perm(len(points))[:sample]
+	}
+
+	values := make([]float64, len(idx))
+	parallel.For(len(idx), func(n int) {
+		i := idx[n]
+		sums := make([]float64, k)
+		counts := make([]int, k)
+		for _, j := range idx {
+			if j == i {
+				continue
+			}
+			sums[labels[j]] += pointDistance(points[i], points[j], metric)
+			counts[labels[j]]++
+		}
+		own := labels[i]
+		if counts[own] == 0 {
+			return // Силуэт точки-одиночки равен 0
+		}
+		a := sums[own] / float64(counts[own])
+		b := math.Inf(1)
+		for c := range sums {
+			if c != own && counts[c] > 0 {
+				b = math.Min(b, sums[c]/float64(counts[c]))
+			}
+		}
+		if math.IsInf(b, 1) || math.Max(a, b) == 0 {
+			return
+		}
+		values[n] = (b - a) / math.Max(a, b)
+	})
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// daviesBouldin — среднее по кластерам наибольшего отношения (sᵢ + sⱼ) / d(cᵢ, cⱼ),
+// где s — средний разброс точек кластера вокруг центра
+func daviesBouldin(points [][]float64, labels []int, centroids [][]float64) float64 {
+	k := len(centroids)
+	scatter := make([]float64, k)
+	counts := make([]int, k)
+	for i, p := range points {
+		scatter[labels[i]] += pointDistance(p, centroids[labels[i]], Euclidean)
+		counts[labels[i]]++
+	}
+	var sum float64
+	var clusters int
+	for i := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+		clusters++
+		scatter[i] /= float64(counts[i])
+	}
+	if clusters < 2 {
+		return 0
+	}
+	for i := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+		var worst float64
+		for j := range centroids {
+			if i == j || counts[j] == 0 {
+				continue
+			}
+			d := pointDistance(centroids[i], centroids[j], Euclidean)
+			if d == 0 {
+				continue
+			}
+			worst = math.Max(worst, (scatter[i]+scatter[j])/d)
+		}
+		sum += worst
+	}
+	return sum / float64(clusters)
+}
+
+// calinskiHarabasz — отношение межкластерного разброса к внутрикластерному
+// с поправкой на число кластеров и точек. Когда все точки совпадают с
+// центроидами, индекс не определён: возвращается 0, а не +Inf, которую
+// нельзя записать в JSON
+func calinskiHarabasz(points [][]float64, labels []int, centroids [][]float64, counts []int) float64 {
+	n := len(points)
+	k := 0
+	for _, c := range counts {
+		if c > 0 {
+			k++
+		}
+	}
+	if k < 2 || n <= k {
+		return 0
+	}
+	center, _ := means(points, make([]int, n), 1)
+	var between, within float64
+	for c, centroid := range centroids {
+		if counts[c] > 0 {
+			between += float64(counts[c]) * distance(centroid, center[0], Euclidean)
+		}
+	}
+	for i, p := range points {
+		within += distance(p, centroids[labels[i]], Euclidean)
+	}
+	if within == 0 {
+		return 0
+	}
+	return (between / float64(k-1)) / (within / float64(n-k))
+}
+
+// Sweep — оценки разбиений для диапазона k и рекомендованное число кластеров
+type Sweep struct {
+	Metric      Metric   `json:"metric"`
+	Scores      []Scores `json:"scores"`
+	Silhouette  int      `json:"best_silhouette_k"` // k с наибольшим силуэтом
+	Elbow       int      `json:"elbow_k"`           // k в точке излома кривой инерции
+	Recommended int      `json:"recommended_k"`
+}
+
+// SweepK запускает k-means для каждого k от kMin до kMax параллельно и оценивает
+// разбиения. Рекомендуется k с наибольшим силуэтом; точка излома кривой инерции
+// (наибольшее отклонение от прямой между крайними точками) выводится для сравнения.
+func SweepK(data [][]float64, kMin, kMax int, opts KMeansOptions, sample int) (*Sweep, error) {
+	if kMin < 2 {
+		kMin = 2
+	}
+	if kMax < kMin {
+		return nil, fmt.Errorf("пустой диапазон k: %d..%d", kMin, kMax)
+	}
+	if kMax > len(data) {
+		return nil, fmt.Errorf("k (%d) больше числа точек (%d)", kMax, len(data))
+	}
+
+	// Параллельность — по значениям k, поэтому каждый запуск k-means однопоточный
+	opts.Workers = 1
+	scores := make([]Scores, kMax-kMin+1)
+	errs := make([]error, len(scores))
+	parallel.For(len(scores), func(i int) {
+		o := opts
+		o.K = kMin + i
+		result, err := KMeans(data, o)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		scores[i] = Evaluate(data, result.Labels, opts.Metric, sample, opts.Seed)
+		scores[i].K = o.K
+		scores[i].Inertia = result.Inertia
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sweep := &Sweep{Metric: opts.Metric, Scores: scores, Silhouette: scores[0].K, Elbow: elbow(scores)}
+	best := math.Inf(-1)
+	for _, s := range scores {
+		if s.Silhouette > best {
+			best, sweep.Silhouette = s.Silhouette, s.K
+		}
+	}
+	sweep.Recommended = sweep.Silhouette
+	return sweep, nil
+}
+
+// elbow находит точку излома кривой инерции: k, наиболее удалённое от прямой,
+// соединяющей первую и последнюю точки (в нормированных координатах)
+func elbow(scores []Scores) int {
+	if len(scores) < 3 {
+		return scores[0].K
+	}
+	first, last := scores[0], scores[len(scores)-1]
+	dk := float64(last.K - first.K)
+	di := first.Inertia - last.Inertia
+	if di <= 0 {
+		return first.K
+	}
+	best, bestDist := first.K, -1.0
+	for _, s := range scores {
+		x := float64(s.K-first.K) / dk
+		y := (first.Inertia - s.Inertia) / di
+		if d := y - x; d > bestDist {
+			best, bestDist = s.K, d
+		}
+	}
+	return best
+}
+
+// Print выводит таблицу оценок и рекомендацию
+func (s *Sweep) Print(w io.Writer) {
+	fmt.Fprintf(w, "%4s %14s %11s %15s %18s\n", "k", "Инерция", "Силуэт", "Дэвис-Болдин", "Калински-Харабаз")
+	for _, sc := range s.Scores {
+		fmt.Fprintf(w, "%4d %14.4f %11.4f %15.4f %18.4f\n", sc.K, sc.Inertia, sc.Silhouette, sc.DaviesBouldin, sc.CalinskiHarabasz)
+	}
+	fmt.Fprintf(w, "\nНаибольший силуэт: k = %d, излом инерции: k = %d\n", s.Silhouette, s.Elbow)
+	fmt.Fprintf(w, "Рекомендуемое число кластеров: %d\n", s.Recommended)
+}
+
+// SaveJSON сохраняет результаты в формате JSON
+func (s *Sweep) SaveJSON(filename string) error {
+	return saveJSON(filename, s)
+}
+
+func saveJSON(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи %s: %v", filename, err)
+	}
+	return nil
+}
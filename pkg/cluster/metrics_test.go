@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	// Две пары точек на прямой: {0, 1} и {10, 11}
+	points := [][]float64{{0}, {1}, {10}, {11}}
+	labels := []int{0, 0, 1, 1}
+	scores := Evaluate(points, labels, Euclidean, 0, 1)
+
+	// Силуэт: для крайних точек 1 − 1/10.5, для внутренних 1 − 1/9.5
+	silhouette := (2*(1-1/10.5) + 2*(1-1/9.5)) / 4
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"k", float64(scores.K), 2},
+		{"инерция", scores.Inertia, 1},
+		{"силуэт", scores.Silhouette, silhouette},
+		{"Дэвис — Болдин", scores.DaviesBouldin, (0.5 + 0.5) / 10},
+		{"Калински — Харабаз", scores.CalinskiHarabasz, (100.0 / 1) / (1.0 / 2)},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s = %.9f, ожидалось %.9f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateLayouts(t *testing.T) {
+	points, truth := blobs([][]float64{{0, 0}, {20, 0}, {0, 20}}, 20, 1, 1)
+	good := Evaluate(points, truth, Euclidean, 0, 1)
+	// Перемешанные метки: каждая третья точка в своём кластере
+	mixed := make([]int, len(truth))
+	for i := range mixed {
+		mixed[i] = i % 3
+	}
+	bad := Evaluate(points, mixed, Euclidean, 0, 1)
+
+	if good.Silhouette < 0.8 || bad.Silhouette > 0.1 {
+		t.Errorf("силуэт разделённых групп %.3f, перемешанных %.3f", good.Silhouette, bad.Silhouette)
+	}
+	if good.DaviesBouldin > 0.3 || bad.DaviesBouldin < good.DaviesBouldin {
+		t.Errorf("индекс Дэвиса — Болдина %.3f и %.3f", good.DaviesBouldin, bad.DaviesBouldin)
+	}
+	if good.CalinskiHarabasz < 100*bad.CalinskiHarabasz {
+		t.Errorf("индекс Калински — Харабаза %.3f и %.3f", good.CalinskiHarabasz, bad.CalinskiHarabasz)
+	}
+
+	// Подвыборка для силуэта даёт близкую оценку
+	sampled := Evaluate(points, truth, Euclidean, 30, 1)
+	if math.Abs(sampled.Silhouette-good.Silhouette) > 0.05 {
+		t.Errorf("силуэт по подвыборке %.3f, по всем точкам %.3f", sampled.Silhouette, good.Silhouette)
+	}
+}
+
+func TestEvaluateSingleCluster(t *testing.T) {
+	scores := Evaluate([][]float64{{0}, {1}, {2}}, []int{0, 0, 0}, Euclidean, 0, 1)
+	if scores.Silhouette != 0 || scores.DaviesBouldin != 0 || scores.CalinskiHarabasz != 0 {
+		t.Errorf("оценки одного кластера %+v", scores)
+	}
+}
+
+func TestEvaluateZeroWithin(t *testing.T) {
+	// Каждая точка совпадает с центроидом своего кластера
+	scores := Evaluate([][]float64{{0}, {0}, {5}, {5}}, []int{0, 0, 1, 1}, Euclidean, 0, 1)
+	if scores.CalinskiHarabasz != 0 {
+		t.Errorf("индекс Калински — Харабаза = %v, ожидалось 0", scores.CalinskiHarabasz)
+	}
+	if _, err := json.Marshal(scores); err != nil {
+		t.Errorf("оценки не сериализуются в JSON: %v", err)
+	}
+}
+
+func TestSweepK(t *testing.T) {
+	points, _ := blobs([][]float64{{0, 0}, {20, 0}, {0, 20}}, 20, 1, 2)
+	sweep, err := SweepK(points, 2, 6, DefaultKMeansOptions(2), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sweep.Scores) != 5 || sweep.Scores[0].K != 2 || sweep.Scores[4].K != 6 {
+		t.Fatalf("оценки %+v", sweep.Scores)
+	}
+	if sweep.Recommended != 3 || sweep.Elbow != 3 {
+		t.Errorf("рекомендовано k = %d, излом k = %d, ожидалось 3", sweep.Recommended, sweep.Elbow)
+	}
+	if _, err := SweepK(points, 5, 4, DefaultKMeansOptions(2), 0); err == nil {
+		t.Error("ожидалась ошибка для пустого диапазона")
+	}
+}
@@ -0,0 +1,272 @@
+package corpus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Максимальная длина строки корпуса (длинные посты не помещаются в буфер bufio.Scanner по умолчанию)
+const maxLineSize = 16 * 1024 * 1024
+
+// Document — очищенный документ корпуса вместе с метаданными
+type Document struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp,omitempty"` // RFC 3339, если дату удалось разобрать
+	Source    string `json:"source,omitempty"`
+	Author    string `json:"author,omitempty"`
+	Lang      string `json:"lang,omitempty"`
+	Label     string `json:"label,omitempty"` // Класс размеченного документа
+	Text      string `json:"text"`
+}
+
+// Форматы дат, которые распознаёт ParseTime
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05-07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// ParseTime разбирает дату в одном из распространённых форматов или Unix-время в секундах
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("пустая дата")
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неизвестный формат даты: %q", value)
+}
+
+// NormalizeTime приводит дату к формату RFC 3339; нераспознанная дата возвращается без изменений
+func NormalizeTime(value string) string {
+	t, err := ParseTime(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Time возвращает дату документа
+func (d Document) Time() (time.Time, error) {
+	return ParseTime(d.Timestamp)
+}
+
+// Reader последовательно читает документы из корпуса.
+// Поддерживаются JSONL (по документу на строку) и обычный текст (строка — документ).
+type Reader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	jsonl   bool
+	line    int
+}
+
+// Open открывает корпус и определяет его формат по первой непустой строке
+func Open(filename string) (*Reader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии корпуса: %v", err)
+	}
+
+	jsonl, err := detectJSONL(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	return &Reader{file: file, scanner: scanner, jsonl: jsonl}, nil
+}
+
+// detectJSONL проверяет, начинается ли первая непустая строка файла с '{',
+// и возвращает позицию чтения в начало файла
+func detectJSONL(file *os.File) (bool, error) {
+	reader := bufio.NewReader(file)
+	jsonl := false
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return false, fmt.Errorf("ошибка при чтении корпуса: %v", err)
+		}
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\uFEFF' {
+			continue
+		}
+		jsonl = r == '{'
+		break
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return false, fmt.Errorf("ошибка при чтении корпуса: %v", err)
+	}
+	return jsonl, nil
+}
+
+// IsJSONL сообщает, что корпус записан в формате JSONL
+func (r *Reader) IsJSONL() bool {
+	return r.jsonl
+}
+
+// Next возвращает следующий документ или io.EOF по окончании корпуса.
+// Для текстового корпуса идентификатором документа служит номер строки.
+func (r *Reader) Next() (Document, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !r.jsonl {
+			return Document{ID: strconv.Itoa(r.line), Text: line}, nil
+		}
+		var doc Document
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			return Document{}, fmt.Errorf("ошибка разбора JSON в строке %d: %v", r.line, err)
+		}
+		return doc, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Document{}, fmt.Errorf("ошибка при чтении корпуса: %v", err)
+	}
+	return Document{}, io.EOF
+}
+
+// Close закрывает файл корпуса
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+// ReadAll читает все документы корпуса
+func ReadAll(filename string) ([]Document, error) {
+	reader, err := Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var docs []Document
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// ReadTexts читает из корпуса только тексты документов
+func ReadTexts(filename string) ([]string, error) {
+	docs, err := ReadAll(filename)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
+	}
+	return texts, nil
+}
+
+// Writer записывает документы в формате JSONL
+type Writer struct {
+	file   *os.File
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	closed bool
+}
+
+// Create создаёт файл корпуса в формате JSONL
+func Create(filename string) (*Writer, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	buf := bufio.NewWriter(file)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	return &Writer{file: file, buf: buf, enc: enc}, nil
+}
+
+// Write записывает документ
+func (w *Writer) Write(doc Document) error {
+	if err := w.enc.Encode(doc); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// Close сбрасывает буфер и закрывает файл; повторный вызов ничего не делает
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return w.file.Close()
+}
+
+// ExtractText сохраняет тексты документов корпуса в обычный текстовый файл
+// (по документу на строку) — формат, который принимает GloVe
+func ExtractText(corpusFile, textFile string) error {
+	reader, err := Open(corpusFile)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	output, err := os.Create(textFile)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer output.Close()
+	writer := bufio.NewWriter(output)
+
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if doc.Text == "" {
+			continue
+		}
+		if _, err := writer.WriteString(doc.Text + "\n"); err != nil {
+			return fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
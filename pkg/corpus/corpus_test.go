@@ -0,0 +1,153 @@
+package corpus
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "corpus.jsonl")
+	docs := []Document{
+		{ID: "1", Timestamp: "2024-03-01T10:00:00Z", Source: "news", Author: "анна", Lang: "ru", Text: "первый текст"},
+		{ID: "2", Label: "спорт", Text: "второй текст с <тегом> & символами"},
+	}
+	w, err := Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range docs {
+		if err := w.Write(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("повторный Close: %v", err)
+	}
+
+	got, err := ReadAll(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, docs) {
+		t.Errorf("прочитано %+v, ожидалось %+v", got, docs)
+	}
+	data, _ := os.ReadFile(filename)
+	if !strings.Contains(string(data), "<тегом> & символами") {
+		t.Errorf("HTML-символы экранированы: %s", data)
+	}
+}
+
+func TestReadPlainText(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "corpus.txt")
+	if err := os.WriteFile(filename, []byte("первая строка\n\n  вторая строка  \n{не json в середине}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.IsJSONL() {
+		t.Fatal("текстовый корпус распознан как JSONL")
+	}
+	want := []Document{
+		{ID: "1", Text: "первая строка"},
+		{ID: "3", Text: "вторая строка"},
+		{ID: "4", Text: "{не json в середине}"},
+	}
+	for _, w := range want {
+		doc, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc != w {
+			t.Errorf("Next() = %+v, ожидалось %+v", doc, w)
+		}
+	}
+}
+
+func TestReadJSONLWithLeadingSpace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "corpus.jsonl")
+	content := "\n  {\"id\":\"a\",\"text\":\"текст\"}\n{\"id\":\"b\",\"text\":\"ещё\"}\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	texts, err := ReadTexts(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(texts, []string{"текст", "ещё"}) {
+		t.Errorf("ReadTexts = %q", texts)
+	}
+}
+
+func TestReadJSONLError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "corpus.jsonl")
+	if err := os.WriteFile(filename, []byte("{\"id\":\"a\",\"text\":\"x\"}\n{broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadAll(filename); err == nil {
+		t.Error("ожидалась ошибка разбора JSON")
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	dir := t.TempDir()
+	corpusFile := filepath.Join(dir, "corpus.jsonl")
+	textFile := filepath.Join(dir, "corpus.txt")
+	content := "{\"id\":\"1\",\"text\":\"первый\"}\n{\"id\":\"2\",\"text\":\"\"}\n{\"id\":\"3\",\"text\":\"третий\"}\n"
+	if err := os.WriteFile(corpusFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractText(corpusFile, textFile); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(textFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "первый\nтретий\n" {
+		t.Errorf("ExtractText записал %q", data)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	for _, value := range []string{
+		"2024-03-01T10:30:00Z",
+		"2024-03-01 10:30:00",
+		"2024-03-01 10:30",
+		"01.03.2024 10:30",
+		" 1709289000 ",
+	} {
+		got, err := ParseTime(value)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, ожидалось %v", value, got, want)
+		}
+	}
+	for _, value := range []string{"", "вчера", "2024/03/01"} {
+		if _, err := ParseTime(value); err == nil {
+			t.Errorf("ParseTime(%q): ожидалась ошибка", value)
+		}
+	}
+}
+
+func TestNormalizeTime(t *testing.T) {
+	if got := NormalizeTime("01.03.2024"); got != "2024-03-01T00:00:00Z" {
+		t.Errorf("NormalizeTime = %q", got)
+	}
+	if got := NormalizeTime(" вчера "); got != "вчера" {
+		t.Errorf("нераспознанная дата изменена: %q", got)
+	}
+}
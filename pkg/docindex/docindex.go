@@ -0,0 +1,331 @@
+package docindex
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Имена файлов индекса внутри каталога
+const (
+	metaFile = "meta.json"
+	annFile  = "index.ann"
+	docsFile = "docs.jsonl"
+)
+
+// Meta — параметры построения индекса, без которых нельзя согласованно добавлять документы и искать
+type Meta struct {
+	VectorsFile string           `json:"vectors_file"`
+	VocabFile   string           `json:"vocab_file,omitempty"`
+	Weighting   docvec.Weighting `json:"weighting"`
+	A           float64          `json:"a"`
+	Component   []float64        `json:"component,omitempty"` // Вычитаемая главная компонента
+	Dim         int              `json:"dim"`
+	ANN         ann.Config       `json:"ann"`
+}
+
+// Hit — найденный документ и его косинусное сходство с запросом
+type Hit struct {
+	corpus.Document
+	Score float64 `json:"score"`
+}
+
+// Index — поиск похожих документов корпуса: векторы документов хранятся
+// в графе HNSW, метаданные — в JSONL рядом с ним. Номер документа в графе
+// совпадает с номером строки в docs.jsonl. Безопасен для конкурентного поиска;
+// добавлять документы нужно из одной горутины и не одновременно с поиском.
+type Index struct {
+	meta     Meta
+	embedder *docvec.Embedder
+	graph    *ann.Index
+	docs     []corpus.Document
+	ids      map[string]int
+}
+
+// New создаёт пустой индекс. Главная компонента для вычитания берётся
+// из embedder (после Fit) и сохраняется вместе с индексом. Пути к файлам
+// векторов и словаря сохраняются абсолютными, чтобы индекс открывался
+// из любого рабочего каталога.
+func New(model *vectors.Model, embedder *docvec.Embedder, meta Meta) *Index {
+	meta.VectorsFile = absPath(meta.VectorsFile)
+	meta.VocabFile = absPath(meta.VocabFile)
+	meta.Dim = model.Dim()
+	meta.Component = embedder.Component()
+	return &Index{meta: meta, embedder: embedder, graph: ann.New(model.Dim(), meta.ANN), ids: make(map[string]int)}
+}
+
+// absPath возвращает абсолютный путь; пустой путь остаётся пустым
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// ReadMeta читает параметры индекса, сохранённого в каталоге
+func ReadMeta(dir string) (Meta, error) {
+	var meta Meta
+	data, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if err != nil {
+		return meta, fmt.Errorf("ошибка при чтении параметров индекса: %v", err)
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("ошибка при разборе параметров индекса: %v", err)
+	}
+	return meta, nil
+}
+
+// Load загружает индекс из каталога. Если model равна nil, загружается модель
+// из файла, по которому индекс строился.
+func Load(dir string, model *vectors.Model) (*Index, error) {
+	meta, err := ReadMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	if model == nil {
+		if model, err = vectors.Load(meta.VectorsFile); err != nil {
+			return nil, err
+		}
+	}
+	if model.Dim() != meta.Dim {
+		return nil, fmt.Errorf("размерность модели %d не совпадает с размерностью индекса %d", model.Dim(), meta.Dim)
+	}
+
+	embedder, err := NewEmbedder(model, meta)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := ann.Load(filepath.Join(dir, annFile))
+	if err != nil {
+		return nil, err
+	}
+	docs, err := corpus.ReadAll(filepath.Join(dir, docsFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != graph.Len() {
+		return nil, fmt.Errorf("индекс повреждён: %d документов и %d векторов", len(docs), graph.Len())
+	}
+
+	ix := &Index{meta: meta, embedder: embedder, graph: graph, docs: docs, ids: make(map[string]int, len(docs))}
+	for i, doc := range docs {
+		ix.ids[doc.ID] = i
+	}
+	return ix, nil
+}
+
+// NewEmbedder создаёт построитель векторов документов с параметрами индекса
+func NewEmbedder(model *vectors.Model, meta Meta) (*docvec.Embedder, error) {
+	embedder, err := docvec.Load(model, meta.VocabFile, docvec.Options{Weighting: meta.Weighting, A: meta.A})
+	if err != nil {
+		return nil, err
+	}
+	embedder.SetComponent(meta.Component)
+	return embedder, nil
+}
+
+// Len возвращает число документов индекса
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Meta возвращает параметры индекса
+func (ix *Index) Meta() Meta {
+	return ix.meta
+}
+
+// Add добавляет документ с очищенным текстом. Документ с уже известным
+// идентификатором или без единого слова из модели пропускается (added = false).
+func (ix *Index) Add(doc corpus.Document) (added bool, err error) {
+	if _, ok := ix.ids[doc.ID]; ok {
+		return false, nil
+	}
+	vec, _ := ix.embedder.EmbedText(doc.Text)
+	if vec == nil {
+		return false, nil
+	}
+	id, err := ix.graph.Add(vec)
+	if err != nil {
+		return false, err
+	}
+	ix.ids[doc.ID] = id
+	ix.docs = append(ix.docs, doc)
+	return true, nil
+}
+
+// Clean очищает текст запроса так же, как очищаются тексты CSV при индексации
+func Clean(text string) string {
+	return textprocessor.RemoveExcessNewlines(textprocessor.CleanText(text))
+}
+
+// Search находит k документов, ближайших к тексту запроса (текст очищается функцией Clean)
+func (ix *Index) Search(text string, k int) ([]Hit, docvec.Stats) {
+	hits, stats, _ := ix.SearchContext(context.Background(), text, k)
+	return hits, stats
+}
+
+// SearchContext работает как Search, но не начинает поиск по графу, если контекст
+// уже отменён (например, истекло время HTTP-запроса), и возвращает ошибку контекста
+func (ix *Index) SearchContext(ctx context.Context, text string, k int) ([]Hit, docvec.Stats, error) {
+	vec, stats := ix.embedder.EmbedText(Clean(text))
+	if vec == nil {
+		return nil, stats, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, stats, err
+	}
+	return ix.hits(ix.graph.Search(vec, k), ""), stats, nil
+}
+
+// Similar находит k документов, ближайших к документу индекса с идентификатором id
+func (ix *Index) Similar(id string, k int) ([]Hit, error) {
+	n, ok := ix.ids[id]
+	if !ok {
+		return nil, fmt.Errorf("документ %q не найден в индексе", id)
+	}
+	return ix.hits(ix.graph.Search(ix.graph.Vector(n), k+1), id), nil
+}
+
+// Document возвращает документ индекса по идентификатору
+func (ix *Index) Document(id string) (corpus.Document, bool) {
+	n, ok := ix.ids[id]
+	if !ok {
+		return corpus.Document{}, false
+	}
+	return ix.docs[n], true
+}
+
+// hits превращает результаты поиска в документы, пропуская документ exclude
+func (ix *Index) hits(results []ann.Result, exclude string) []Hit {
+	hits := make([]Hit, 0, len(results))
+	for _, r := range results {
+		doc := ix.docs[r.ID]
+		if doc.ID == exclude {
+			continue
+		}
+		hits = append(hits, Hit{Document: doc, Score: r.Similarity})
+	}
+	if exclude != "" && len(hits) == len(results) && len(hits) > 0 {
+		hits = hits[:len(hits)-1]
+	}
+	return hits
+}
+
+// Save сохраняет индекс в каталог. Файлы сначала записываются во временный
+// каталог рядом с dir, который затем подменяет dir целиком, поэтому сбой при
+// записи не портит ранее сохранённый индекс, а читатель не увидит смесь
+// старых и новых файлов.
+func (ix *Index) Save(dir string) error {
+	dir = filepath.Clean(dir)
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("ошибка при создании каталога индекса: %v", err)
+	}
+	temp, err := os.MkdirTemp(parent, filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return fmt.Errorf("ошибка при создании временного каталога индекса: %v", err)
+	}
+	defer os.RemoveAll(temp) // После успешной подмены каталога уже нет
+	if err := os.Chmod(temp, 0755); err != nil {
+		return fmt.Errorf("ошибка при создании временного каталога индекса: %v", err)
+	}
+
+	if err := ix.writeDocs(filepath.Join(temp, docsFile)); err != nil {
+		return err
+	}
+	if err := ix.graph.Save(filepath.Join(temp, annFile)); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ix.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(temp, metaFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи параметров индекса: %v", err)
+	}
+	return replaceDir(temp, dir)
+}
+
+// replaceDir подменяет каталог dst каталогом src. Прежний каталог на время
+// подмены переименовывается и удаляется только после успеха; если src не
+// удалось переименовать, прежний каталог возвращается на место.
+func replaceDir(src, dst string) error {
+	old := src + ".old"
+	if err := os.Rename(dst, old); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ошибка при сохранении индекса: %v", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		os.Rename(old, dst)
+		return fmt.Errorf("ошибка при сохранении индекса: %v", err)
+	}
+	if err := os.RemoveAll(old); err != nil {
+		return fmt.Errorf("ошибка при удалении прежнего индекса: %v", err)
+	}
+	return nil
+}
+
+// writeDocs записывает метаданные всех документов в JSONL
+func (ix *Index) writeDocs(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла документов: %v", err)
+	}
+	writer := bufio.NewWriter(file)
+	enc := json.NewEncoder(writer)
+	enc.SetEscapeHTML(false)
+	for _, doc := range ix.docs {
+		if err := enc.Encode(doc); err != nil {
+			file.Close()
+			return fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// Exists сообщает, есть ли в каталоге сохранённый индекс
+func Exists(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, metaFile))
+	return err == nil
+}
+
+// AddAll добавляет документы, которые возвращает next (до io.EOF), и возвращает
+// число добавленных и пропущенных документов
+func (ix *Index) AddAll(next func() (corpus.Document, error)) (added, skipped int, err error) {
+	for {
+		doc, err := next()
+		if err == io.EOF {
+			return added, skipped, nil
+		}
+		if err != nil {
+			return added, skipped, err
+		}
+		ok, err := ix.Add(doc)
+		if err != nil {
+			return added, skipped, err
+		}
+		if ok {
+			added++
+		} else {
+			skipped++
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package docindex
+
+import (
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/vectors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testIndex строит индекс из трёх документов по модели с тремя направлениями
+func testIndex(t *testing.T) (*Index, *vectors.Model) {
+	t.Helper()
+	model := vectors.New(
+		[]string{"кот", "кошка", "пёс", "собака", "чай", "кофе"},
+		[][]float64{{1, 0.1, 0}, {1, 0, 0.1}, {0.1, 1, 0}, {0, 1, 0.1}, {0, 0.1, 1}, {0.1, 0, 1}},
+	)
+	meta := Meta{VectorsFile: "vectors.txt", Weighting: docvec.Mean, ANN: ann.DefaultConfig()}
+	ix := New(model, docvec.New(model, nil, docvec.Options{Weighting: docvec.Mean}), meta)
+	docs := []corpus.Document{
+		{ID: "коты", Text: "кот кошка"},
+		{ID: "псы", Text: "пёс собака"},
+		{ID: "напитки", Text: "чай кофе"},
+		{ID: "коты", Text: "чай"},          // Повтор идентификатора
+		{ID: "пусто", Text: "жираф зебра"}, // Ни одного слова модели
+	}
+	for i, doc := range docs {
+		added, err := ix.Add(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i < 3; added != want {
+			t.Fatalf("Add(%q) = %v, want %v", doc.ID, added, want)
+		}
+	}
+	return ix, model
+}
+
+func TestSearch(t *testing.T) {
+	ix, model := testIndex(t)
+	dir := t.TempDir()
+	if err := ix.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(dir) {
+		t.Fatal("Exists после Save = false")
+	}
+	loaded, err := Load(dir, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 3 {
+		t.Fatalf("Len после Load = %d, want 3", loaded.Len())
+	}
+	if !filepath.IsAbs(loaded.Meta().VectorsFile) {
+		t.Errorf("путь к векторам не абсолютный: %q", loaded.Meta().VectorsFile)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"Кошка!", "коты"},
+		{"<b>собака</b> и пёс", "псы"},
+		{"кофе, https://example.com", "напитки"},
+	}
+	for name, index := range map[string]*Index{"построенный": ix, "загруженный": loaded} {
+		for _, tt := range tests {
+			hits, stats := index.Search(tt.query, 1)
+			if len(hits) != 1 || hits[0].ID != tt.want {
+				t.Errorf("%s индекс: Search(%q) = %v, want %q", name, tt.query, hits, tt.want)
+			}
+			if stats.Known == 0 {
+				t.Errorf("%s индекс: Search(%q) не нашёл слов модели", name, tt.query)
+			}
+		}
+	}
+	if hits, _ := ix.Search("жираф", 1); hits != nil {
+		t.Errorf("Search без слов модели = %v, want nil", hits)
+	}
+}
+
+func TestSaveReplaces(t *testing.T) {
+	ix, model := testIndex(t)
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "index")
+	if err := ix.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	// Повторное сохранение меньшего индекса целиком подменяет прежний каталог
+	small := New(model, docvec.New(model, nil, docvec.Options{Weighting: docvec.Mean}), ix.Meta())
+	if _, err := small.Add(corpus.Document{ID: "чай", Text: "чай"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := small.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir, model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 1 {
+		t.Errorf("Len после повторного Save = %d, want 1", loaded.Len())
+	}
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "index" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("после Save в каталоге остались %v, want [index]", names)
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	ix, _ := testIndex(t)
+	hits, err := ix.Similar("коты", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("Similar вернул %d документов, want 2", len(hits))
+	}
+	for _, hit := range hits {
+		if hit.ID == "коты" {
+			t.Error("Similar вернул сам документ")
+		}
+	}
+	if _, err := ix.Similar("нет такого", 1); err == nil {
+		t.Error("Similar для неизвестного документа должен вернуть ошибку")
+	}
+}
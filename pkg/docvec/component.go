@@ -0,0 +1,73 @@
+package docvec
+
+import (
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"sort"
+)
+
+// firstComponent находит первый правый сингулярный вектор матрицы данных
+// (без центрирования, как в SIF) степенным методом для XᵀX
+func firstComponent(data [][]float64) []float64 {
+	dim := len(data[0])
+	// Начальное приближение — среднее направление, к которому первая компонента обычно близка
+	v := vectors.Normalize(vectors.Mean(data))
+	if isZero(v) {
+		for i := range v {
+			v[i] = 1 / math.Sqrt(float64(dim))
+		}
+	}
+	next := make([]float64, dim)
+	for iter := 0; iter < 100; iter++ {
+		for i := range next {
+			next[i] = 0
+		}
+		for _, row := range data {
+			p := linalg.Dot(row, v)
+			for i, x := range row {
+				next[i] += p * x
+			}
+		}
+		next = vectors.Normalize(next)
+		if isZero(next) {
+			break
+		}
+		diff := 0.0
+		for i := range v {
+			diff = math.Max(diff, math.Abs(next[i]-v[i]))
+		}
+		v, next = next, v
+		if diff < 1e-9 {
+			break
+		}
+	}
+	return v
+}
+
+// removeProjection вычитает из vec его проекцию на единичный вектор u
+func removeProjection(vec, u []float64) {
+	p := linalg.Dot(vec, u)
+	for i := range vec {
+		vec[i] -= p * u[i]
+	}
+}
+
+func isZero(v []float64) bool {
+	for _, x := range v {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sortByCount упорядочивает слова по убыванию частоты, при равенстве — по алфавиту
+func sortByCount(words []string, counts map[string]int) {
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+}
@@ -0,0 +1,260 @@
+package docvec
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"strings"
+)
+
+// Weighting — способ взвешивания векторов слов при усреднении
+type Weighting string
+
+// Поддерживаемые способы
+const (
+	Mean  Weighting = "mean"  // Простое среднее
+	TFIDF Weighting = "tfidf" // Вес слова — idf = ln(1 + N/частота), частые слова весят меньше
+	SIF   Weighting = "sif"   // Smooth Inverse Frequency: a / (a + p(w))
+)
+
+// ParseWeighting разбирает название способа взвешивания
+func ParseWeighting(name string) (Weighting, error) {
+	switch Weighting(strings.ToLower(strings.TrimSpace(name))) {
+	case Mean, "":
+		return Mean, nil
+	case TFIDF, "tf-idf":
+		return TFIDF, nil
+	case SIF:
+		return SIF, nil
+	}
+	return "", fmt.Errorf("неизвестный способ взвешивания: %s (mean, tfidf, sif)", name)
+}
+
+// Options задаёт параметры построения векторов документов
+type Options struct {
+	Weighting Weighting
+	A         float64 // Параметр сглаживания SIF (обычно 1e-3 … 1e-4)
+}
+
+// DefaultOptions возвращает параметры по умолчанию: SIF с a = 1e-3
+func DefaultOptions() Options {
+	return Options{Weighting: SIF, A: 1e-3}
+}
+
+// Stats описывает, насколько текст представлен в модели
+type Stats struct {
+	Tokens  int      `json:"tokens"`  // Число слов текста
+	Known   int      `json:"known"`   // Число слов, для которых нашёлся вектор
+	Missing []string `json:"missing"` // Слова без вектора (OOV)
+}
+
+// OOV возвращает число слов, которых нет в модели
+func (s Stats) OOV() int {
+	return s.Tokens - s.Known
+}
+
+// Coverage возвращает долю слов текста, найденных в модели (0 для пустого текста)
+func (s Stats) Coverage() float64 {
+	if s.Tokens == 0 {
+		return 0
+	}
+	return float64(s.Known) / float64(s.Tokens)
+}
+
+// Сколько слов вне модели Summary хранит со счётчиками. Когда их становится
+// вдвое больше, остаются только самые частые, поэтому память не растёт с корпусом,
+// а счётчики слов, впервые встреченных поздно, могут быть занижены.
+const trackedOOV = 10000
+
+// Summary — покрытие набора текстов моделью
+type Summary struct {
+	Documents     int     `json:"documents"`
+	Unrepresented int     `json:"unrepresented"` // Тексты без единого известного слова
+	Tokens        int     `json:"tokens"`
+	Known         int     `json:"known"`
+	Coverage      float64 `json:"coverage"`
+	oov           map[string]int
+}
+
+// Add учитывает статистику ещё одного текста
+func (s *Summary) Add(stats Stats) {
+	s.Documents++
+	if stats.Known == 0 {
+		s.Unrepresented++
+	}
+	s.Tokens += stats.Tokens
+	s.Known += stats.Known
+	if s.Tokens > 0 {
+		s.Coverage = float64(s.Known) / float64(s.Tokens)
+	}
+	if s.oov == nil {
+		s.oov = make(map[string]int)
+	}
+	for _, word := range stats.Missing {
+		s.oov[word]++
+	}
+	if len(s.oov) > 2*trackedOOV {
+		top := s.TopOOV(trackedOOV)
+		kept := make(map[string]int, 2*trackedOOV)
+		for _, word := range top {
+			kept[word] = s.oov[word]
+		}
+		s.oov = kept
+	}
+}
+
+// TopOOV возвращает до n самых частых слов, которых нет в модели (n не больше trackedOOV)
+func (s *Summary) TopOOV(n int) []string {
+	words := make([]string, 0, len(s.oov))
+	for word := range s.oov {
+		words = append(words, word)
+	}
+	sortByCount(words, s.oov)
+	if len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// String возвращает краткое описание покрытия
+func (s *Summary) String() string {
+	return fmt.Sprintf("текстов %d (без известных слов %d), покрытие слов %.1f%% (%d из %d)",
+		s.Documents, s.Unrepresented, 100*s.Coverage, s.Known, s.Tokens)
+}
+
+// Embedder строит векторы текстов как взвешенное среднее векторов слов.
+// Частоты слов берутся из словаря GloVe (vocab.txt); слову модели, которого
+// нет в словаре, приписывается средняя частота словаря — иначе редкое и, скорее
+// всего, случайное слово получило бы наибольший вес. Без частот все способы
+// взвешивания сводятся к простому среднему. После Fit из векторов вычитается
+// проекция на первую главную компоненту корпуса (общее направление, которое
+// у всех текстов определяется служебными словами).
+// Безопасен для конкурентного использования после настройки.
+type Embedder struct {
+	model     *vectors.Model
+	counts    map[string]int
+	total     float64
+	mean      float64 // Средняя частота слова словаря
+	opts      Options
+	component []float64
+}
+
+// New создаёт построитель векторов текстов; counts — частоты слов (могут быть nil)
+func New(model *vectors.Model, counts map[string]int, opts Options) *Embedder {
+	if opts.A <= 0 {
+		opts.A = 1e-3
+	}
+	e := &Embedder{model: model, counts: counts, opts: opts}
+	for _, c := range counts {
+		e.total += float64(c)
+	}
+	if len(counts) > 0 {
+		e.mean = e.total / float64(len(counts))
+	}
+	return e
+}
+
+// Load создаёт построитель векторов текстов с частотами слов из словаря GloVe
+// vocabFile. Частоты нужны только для взвешивания tfidf и sif: при простом
+// среднем или пустом vocabFile словарь не читается.
+func Load(model *vectors.Model, vocabFile string, opts Options) (*Embedder, error) {
+	var counts map[string]int
+	if opts.Weighting != Mean && vocabFile != "" {
+		var err error
+		if counts, err = glove.VocabCounts(vocabFile); err != nil {
+			return nil, err
+		}
+	}
+	return New(model, counts, opts), nil
+}
+
+// Weight возвращает вес слова при усреднении
+func (e *Embedder) Weight(word string) float64 {
+	if e.total == 0 {
+		return 1
+	}
+	count := e.mean
+	if c, ok := e.counts[word]; ok && c > 0 {
+		count = float64(c)
+	}
+	switch e.opts.Weighting {
+	case TFIDF:
+		return math.Log(1 + e.total/count)
+	case SIF:
+		return e.opts.A / (e.opts.A + count/e.total)
+	}
+	return 1
+}
+
+// Embed возвращает вектор текста, заданного словами, и статистику покрытия.
+// Слово вне модели учитывается, если для него есть вектор Fallback модели.
+// Если ни для одного слова нет вектора, возвращается nil.
+func (e *Embedder) Embed(words []string) ([]float64, Stats) {
+	stats := Stats{Tokens: len(words)}
+	var sum []float64
+	var weights float64
+	for _, word := range words {
+		vec, ok := e.model.Vector(word)
+		if !ok {
+			stats.Missing = append(stats.Missing, word)
+			continue
+		}
+		if sum == nil {
+			sum = make([]float64, len(vec))
+		}
+		w := e.Weight(word)
+		for i, v := range vec {
+			sum[i] += w * v
+		}
+		weights += w
+		stats.Known++
+	}
+	if sum == nil {
+		return nil, stats
+	}
+	for i := range sum {
+		sum[i] /= weights
+	}
+	if e.component != nil {
+		removeProjection(sum, e.component)
+	}
+	return sum, stats
+}
+
+// EmbedText возвращает вектор очищенного текста (слова через пробел)
+func (e *Embedder) EmbedText(text string) ([]float64, Stats) {
+	return e.Embed(strings.Fields(text))
+}
+
+// Fit вычисляет первую главную компоненту векторов текстов корпуса; после этого
+// Embed вычитает из каждого вектора его проекцию на неё. Тексты без известных
+// слов пропускаются.
+func (e *Embedder) Fit(docs [][]string) error {
+	e.component = nil
+	var data [][]float64
+	for _, words := range docs {
+		if vec, _ := e.Embed(words); vec != nil {
+			data = append(data, vec)
+		}
+	}
+	if len(data) < 2 {
+		return fmt.Errorf("для главной компоненты нужно хотя бы два текста со словами из модели, найдено %d", len(data))
+	}
+	e.component = firstComponent(data)
+	return nil
+}
+
+// Component возвращает вычитаемую главную компоненту (nil до Fit)
+func (e *Embedder) Component() []float64 {
+	return e.component
+}
+
+// SetComponent задаёт вычитаемую компоненту, например сохранённую после Fit на обучающем корпусе
+func (e *Embedder) SetComponent(component []float64) {
+	if component == nil {
+		e.component = nil
+		return
+	}
+	e.component = vectors.Normalize(component)
+}
@@ -0,0 +1,109 @@
+package docvec
+
+import (
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"testing"
+)
+
+func TestWeight(t *testing.T) {
+	counts := map[string]int{"частое": 90, "редкое": 10} // Всего 100, средняя частота 50
+	tests := []struct {
+		name      string
+		counts    map[string]int
+		weighting Weighting
+		word      string
+		want      float64
+	}{
+		{"mean", counts, Mean, "частое", 1},
+		{"sif частое", counts, SIF, "частое", 0.1 / (0.1 + 0.9)},
+		{"sif редкое", counts, SIF, "редкое", 0.1 / (0.1 + 0.1)},
+		{"sif вне словаря — средняя частота", counts, SIF, "новое", 0.1 / (0.1 + 0.5)},
+		{"tfidf частое", counts, TFIDF, "частое", math.Log(1 + 100.0/90)},
+		{"tfidf редкое", counts, TFIDF, "редкое", math.Log(1 + 100.0/10)},
+		{"tfidf вне словаря — средняя частота", counts, TFIDF, "новое", math.Log(1 + 100.0/50)},
+		{"sif без частот", nil, SIF, "частое", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(nil, tt.counts, Options{Weighting: tt.weighting, A: 0.1})
+			if got := e.Weight(tt.word); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Weight(%q) = %v, want %v", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	model := vectors.New([]string{"a", "b"}, [][]float64{{1, 0}, {0, 1}})
+	counts := map[string]int{"a": 90, "b": 10}
+	tests := []struct {
+		name      string
+		weighting Weighting
+		words     []string
+		want      []float64
+		known     int
+	}{
+		{"mean", Mean, []string{"a", "b"}, []float64{0.5, 0.5}, 2},
+		{"sif", SIF, []string{"a", "b"}, []float64{0.1 / 0.6, 0.5 / 0.6}, 2},
+		{"слова вне модели пропускаются", Mean, []string{"a", "x", "y"}, []float64{1, 0}, 1},
+		{"нет слов модели", Mean, []string{"x"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(model, counts, Options{Weighting: tt.weighting, A: 0.1})
+			got, stats := e.Embed(tt.words)
+			if stats.Known != tt.known || stats.OOV() != len(tt.words)-tt.known {
+				t.Errorf("stats = %+v, want %d known of %d", stats, tt.known, len(tt.words))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Embed = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-12 {
+					t.Fatalf("Embed = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbedFallback(t *testing.T) {
+	model := vectors.New([]string{"a", "b"}, [][]float64{{1, 0}, {0, 1}})
+	model.SetFallback(func(word string) ([]float64, bool) {
+		if word == "x" {
+			return []float64{0, 1}, true
+		}
+		return nil, false
+	})
+	e := New(model, nil, DefaultOptions())
+	got, stats := e.Embed([]string{"a", "x", "y"})
+	if stats.Known != 2 || len(stats.Missing) != 1 || stats.Missing[0] != "y" {
+		t.Errorf("stats = %+v, want 2 known, missing [y]", stats)
+	}
+	if len(got) != 2 || math.Abs(got[0]-0.5) > 1e-12 || math.Abs(got[1]-0.5) > 1e-12 {
+		t.Errorf("Embed = %v, want [0.5 0.5]", got)
+	}
+}
+
+func TestFitRemovesComponent(t *testing.T) {
+	model := vectors.New([]string{"a", "b", "c"}, [][]float64{{3, 1, 0}, {3, 0, 1}, {3, -1, 0}})
+	e := New(model, nil, DefaultOptions())
+	docs := [][]string{{"a"}, {"b"}, {"c"}, {"a", "b"}}
+	if err := e.Fit(docs); err != nil {
+		t.Fatal(err)
+	}
+	if n := linalg.Norm(e.Component()); math.Abs(n-1) > 1e-9 {
+		t.Fatalf("норма компоненты = %v, want 1", n)
+	}
+	for _, doc := range docs {
+		vec, _ := e.Embed(doc)
+		if p := linalg.Dot(vec, e.Component()); math.Abs(p) > 1e-9 {
+			t.Errorf("проекция %v на компоненту = %v, want 0", doc, p)
+		}
+	}
+	if err := New(model, nil, DefaultOptions()).Fit(docs[:1]); err == nil {
+		t.Error("Fit по одному тексту должен вернуть ошибку")
+	}
+}
@@ -0,0 +1,133 @@
+package evaluate
+
+import (
+	"bufio"
+	"fmt"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"os"
+	"strings"
+)
+
+// AnalogyQuestion — вопрос вида a : b :: c : d (ожидается, что b − a + c ≈ d)
+type AnalogyQuestion struct {
+	A, B, C, D string
+}
+
+// AnalogySection — раздел файла вопросов (строка ": название" в формате word2vec)
+type AnalogySection struct {
+	Name      string
+	Questions []AnalogyQuestion
+}
+
+// SectionResult — точность ответов на вопросы одного раздела
+type SectionResult struct {
+	Section  string  `json:"section"`
+	Total    int     `json:"total"`
+	Answered int     `json:"answered"` // Вопросы, все слова которых есть в модели
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"` // Доля верных ответов среди вопросов, на которые модель отвечала
+	OOVRate  float64 `json:"oov_rate"`
+}
+
+// AnalogyResult — результат оценки на файле вопросов-аналогий
+type AnalogyResult struct {
+	Dataset  string          `json:"dataset"`
+	Method   string          `json:"method"`
+	Sections []SectionResult `json:"sections"`
+	Total    SectionResult   `json:"total"`
+}
+
+// LoadAnalogyQuestions загружает вопросы в формате word2vec (questions-words.txt):
+// строки ": раздел" начинают раздел, остальные содержат четыре слова a b c d
+func LoadAnalogyQuestions(filename string) ([]AnalogySection, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла аналогий: %v", err)
+	}
+	defer file.Close()
+
+	var sections []AnalogySection
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			sections = append(sections, AnalogySection{Name: strings.TrimSpace(line[1:])})
+			continue
+		}
+		parts := strings.Fields(strings.ToLower(line))
+		if len(parts) != 4 {
+			continue
+		}
+		if len(sections) == 0 {
+			sections = append(sections, AnalogySection{Name: "default"})
+		}
+		last := &sections[len(sections)-1]
+		last.Questions = append(last.Questions, AnalogyQuestion{A: parts[0], B: parts[1], C: parts[2], D: parts[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла аналогий: %v", err)
+	}
+	return sections, nil
+}
+
+// EvaluateAnalogies отвечает на вопросы-аналогии и считает точность по разделам.
+// Если restrictVocab > 0, ответы ищутся только среди первых restrictVocab слов модели
+// (в файлах GloVe слова упорядочены по убыванию частоты).
+func EvaluateAnalogies(model *vectors.Model, name string, sections []AnalogySection, method vectors.AnalogyMethod, restrictVocab int) AnalogyResult {
+	search := model
+	if restrictVocab > 0 && restrictVocab < model.Len() {
+		search = vectors.New(model.Words[:restrictVocab], model.Vectors[:restrictVocab])
+	}
+
+	result := AnalogyResult{Dataset: name, Method: string(method), Total: SectionResult{Section: "total"}}
+	for _, section := range sections {
+		sr := SectionResult{Section: section.Name, Total: len(section.Questions)}
+		correct := answerQuestions(search, section.Questions, method)
+		for _, c := range correct {
+			if c >= 0 {
+				sr.Answered++
+				sr.Correct += c
+			}
+		}
+		sr.finish()
+		result.Sections = append(result.Sections, sr)
+
+		result.Total.Total += sr.Total
+		result.Total.Answered += sr.Answered
+		result.Total.Correct += sr.Correct
+	}
+	result.Total.finish()
+	return result
+}
+
+// finish вычисляет точность и долю OOV
+func (r *SectionResult) finish() {
+	if r.Answered > 0 {
+		r.Accuracy = float64(r.Correct) / float64(r.Answered)
+	}
+	if r.Total > 0 {
+		r.OOVRate = 1 - float64(r.Answered)/float64(r.Total)
+	}
+}
+
+// answerQuestions параллельно отвечает на вопросы; для каждого вопроса возвращает
+// 1 (верно), 0 (неверно) или -1 (слова вопроса отсутствуют в модели)
+func answerQuestions(model *vectors.Model, questions []AnalogyQuestion, method vectors.AnalogyMethod) []int {
+	result := make([]int, len(questions))
+	parallel.For(len(questions), func(i int) {
+		q := questions[i]
+		if !model.Has(q.A) || !model.Has(q.B) || !model.Has(q.C) || !model.Has(q.D) {
+			result[i] = -1
+			return
+		}
+		answers, err := model.Analogy([]string{q.B, q.C}, []string{q.A}, method, 1)
+		if err == nil && len(answers) > 0 && answers[0].Word == q.D {
+			result[i] = 1
+		}
+	})
+	return result
+}
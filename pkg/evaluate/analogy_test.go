@@ -0,0 +1,70 @@
+package evaluate
+
+import (
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"testing"
+)
+
+// genderModel — модель, в которой пол — отдельная ось, а пары слов различаются только ею
+func genderModel() *vectors.Model {
+	return vectors.New(
+		[]string{"король", "королева", "мужчина", "женщина", "дядя", "тётя", "стол"},
+		[][]float64{
+			{1, 0, 1, 0, 0},
+			{1, 0, 0, 1, 0},
+			{0, 1, 1, 0, 0},
+			{0, 1, 0, 1, 0},
+			{0.5, 0.5, 1, 0, 0.5},
+			{0.5, 0.5, 0, 1, 0.5},
+			{0, 0, 0, 0, 1},
+		},
+	)
+}
+
+func TestLoadAnalogyQuestions(t *testing.T) {
+	content := "мужчина женщина король королева\n" +
+		": семья\nМужчина Женщина Дядя Тётя\nнеполная строка\n" +
+		": пустой\n"
+	sections, err := LoadAnalogyQuestions(writeFile(t, "questions.txt", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sections) != 3 || sections[0].Name != "default" || sections[1].Name != "семья" {
+		t.Fatalf("разделы %+v", sections)
+	}
+	want := AnalogyQuestion{A: "мужчина", B: "женщина", C: "дядя", D: "тётя"}
+	if len(sections[1].Questions) != 1 || sections[1].Questions[0] != want {
+		t.Errorf("вопросы раздела %+v", sections[1].Questions)
+	}
+}
+
+func TestEvaluateAnalogies(t *testing.T) {
+	sections := []AnalogySection{
+		{Name: "семья", Questions: []AnalogyQuestion{
+			{"мужчина", "женщина", "король", "королева"},
+			{"мужчина", "женщина", "дядя", "тётя"},
+			{"мужчина", "женщина", "король", "стол"}, // Неверный ожидаемый ответ
+			{"мужчина", "женщина", "барон", "баронесса"},
+		}},
+	}
+	for _, method := range []vectors.AnalogyMethod{vectors.CosAdd, vectors.CosMul} {
+		result := EvaluateAnalogies(genderModel(), "toy", sections, method, 0)
+		s := result.Sections[0]
+		if s.Total != 4 || s.Answered != 3 || s.Correct != 2 {
+			t.Errorf("%s: %+v", method, s)
+		}
+		if math.Abs(s.Accuracy-2.0/3) > 1e-12 || math.Abs(s.OOVRate-0.25) > 1e-12 {
+			t.Errorf("%s: точность %v, OOV %v", method, s.Accuracy, s.OOVRate)
+		}
+		if result.Total.Correct != 2 || result.Method != string(method) {
+			t.Errorf("%s: итог %+v", method, result.Total)
+		}
+	}
+
+	// Ответы ищутся только среди первых слов словаря: «тётя» и «стол» недоступны
+	restricted := EvaluateAnalogies(genderModel(), "toy", sections, vectors.CosAdd, 4)
+	if restricted.Total.Answered != 1 || restricted.Total.Correct != 1 {
+		t.Errorf("ограниченный словарь: %+v", restricted.Total)
+	}
+}
@@ -0,0 +1,112 @@
+package evaluate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Report — результаты оценки одной модели, сохраняемые для сравнения между запусками
+type Report struct {
+	Vectors    string             `json:"vectors"`
+	Words      int                `json:"words"`
+	Dim        int                `json:"dim"`
+	CreatedAt  string             `json:"created_at"`
+	Similarity []SimilarityResult `json:"similarity,omitempty"`
+	Analogies  []AnalogyResult    `json:"analogies,omitempty"`
+}
+
+// SaveJSON сохраняет отчёт в формате JSON
+func (r *Report) SaveJSON(filename string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи отчёта: %v", err)
+	}
+	return nil
+}
+
+// LoadReport загружает ранее сохранённый отчёт
+func LoadReport(filename string) (*Report, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении отчёта: %v", err)
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("ошибка разбора отчёта: %v", err)
+	}
+	return &r, nil
+}
+
+// Print выводит отчёт; если задан предыдущий отчёт, рядом выводится изменение метрик
+func (r *Report) Print(w io.Writer, previous *Report) {
+	fmt.Fprintf(w, "Модель: %s (%d слов, размерность %d)\n", r.Vectors, r.Words, r.Dim)
+
+	if len(r.Similarity) > 0 {
+		fmt.Fprintln(w, "\nСходство пар слов:")
+		fmt.Fprintf(w, "%-24s %8s %8s %10s %10s\n", "Набор", "Пар", "OOV", "Spearman", "Pearson")
+		for _, s := range r.Similarity {
+			fmt.Fprintf(w, "%-24s %8d %7.1f%% %10.4f %10.4f", s.Dataset, s.Pairs, s.OOVRate*100, s.Spearman, s.Pearson)
+			if prev, ok := previous.similarity(s.Dataset); ok {
+				fmt.Fprintf(w, "  (Δ Spearman %+.4f)", s.Spearman-prev.Spearman)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	for _, a := range r.Analogies {
+		fmt.Fprintf(w, "\nАналогии %s (%s):\n", a.Dataset, a.Method)
+		fmt.Fprintf(w, "%-32s %8s %8s %10s\n", "Раздел", "Вопросов", "OOV", "Точность")
+		prev, hasPrev := previous.analogies(a.Dataset)
+		rows := append(append([]SectionResult{}, a.Sections...), a.Total)
+		for _, s := range rows {
+			fmt.Fprintf(w, "%-32s %8d %7.1f%% %10.4f", s.Section, s.Total, s.OOVRate*100, s.Accuracy)
+			if hasPrev {
+				if ps, ok := prev.section(s.Section); ok {
+					fmt.Fprintf(w, "  (Δ %+.4f)", s.Accuracy-ps.Accuracy)
+				}
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func (r *Report) similarity(dataset string) (SimilarityResult, bool) {
+	if r == nil {
+		return SimilarityResult{}, false
+	}
+	for _, s := range r.Similarity {
+		if s.Dataset == dataset {
+			return s, true
+		}
+	}
+	return SimilarityResult{}, false
+}
+
+func (r *Report) analogies(dataset string) (AnalogyResult, bool) {
+	if r == nil {
+		return AnalogyResult{}, false
+	}
+	for _, a := range r.Analogies {
+		if a.Dataset == dataset {
+			return a, true
+		}
+	}
+	return AnalogyResult{}, false
+}
+
+func (a AnalogyResult) section(name string) (SectionResult, bool) {
+	if name == a.Total.Section {
+		return a.Total, true
+	}
+	for _, s := range a.Sections {
+		if s.Section == name {
+			return s, true
+		}
+	}
+	return SectionResult{}, false
+}
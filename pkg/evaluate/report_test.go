@@ -0,0 +1,38 @@
+package evaluate
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportRoundTrip(t *testing.T) {
+	report := &Report{
+		Vectors:    "vectors.txt",
+		Words:      7,
+		Dim:        5,
+		Similarity: []SimilarityResult{{Dataset: "simlex", Pairs: 10, Found: 9, Spearman: 0.4}},
+		Analogies: []AnalogyResult{{Dataset: "questions", Method: "3cosadd",
+			Sections: []SectionResult{{Section: "семья", Total: 2, Answered: 2, Correct: 1, Accuracy: 0.5}},
+			Total:    SectionResult{Section: "total", Total: 2, Answered: 2, Correct: 1, Accuracy: 0.5}}},
+	}
+	filename := filepath.Join(t.TempDir(), "report.json")
+	if err := report.SaveJSON(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReport(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Words != 7 || len(loaded.Analogies) != 1 || loaded.Similarity[0].Spearman != 0.4 {
+		t.Errorf("загружен отчёт %+v", loaded)
+	}
+
+	report.Similarity[0].Spearman = 0.5
+	var out bytes.Buffer
+	report.Print(&out, loaded)
+	if !strings.Contains(out.String(), "Δ Spearman +0.1000") || !strings.Contains(out.String(), "Δ +0.0000") {
+		t.Errorf("сравнение с предыдущим отчётом:\n%s", out.String())
+	}
+}
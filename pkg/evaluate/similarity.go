@@ -0,0 +1,172 @@
+package evaluate
+
+import (
+	"bufio"
+	"fmt"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SimilarityPair — пара слов с оценкой сходства, выставленной людьми
+type SimilarityPair struct {
+	Word1 string
+	Word2 string
+	Score float64
+}
+
+// SimilarityResult — результат оценки на одном наборе пар слов
+type SimilarityResult struct {
+	Dataset  string  `json:"dataset"`
+	Pairs    int     `json:"pairs"`
+	Found    int     `json:"found"`
+	OOVRate  float64 `json:"oov_rate"`
+	Spearman float64 `json:"spearman"`
+	Pearson  float64 `json:"pearson"`
+}
+
+// LoadSimilarityDataset загружает набор пар слов в формате SimLex/WordSim:
+// два слова и оценка, разделённые табуляцией, запятой или пробелами.
+// Строки заголовка (без числовой оценки) и комментарии (#) пропускаются.
+// Если столбцов больше трёх, оценкой считается первый числовой столбец после слов.
+func LoadSimilarityDataset(filename string) ([]SimilarityPair, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии набора данных: %v", err)
+	}
+	defer file.Close()
+
+	var pairs []SimilarityPair
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// При разделении табуляцией запятая может быть десятичным разделителем оценки
+		separator := func(r rune) bool { return r == '\t' }
+		if !strings.Contains(line, "\t") {
+			separator = func(r rune) bool { return r == ',' || r == ';' || r == ' ' }
+		}
+		parts := strings.FieldsFunc(line, separator)
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		if len(parts) < 3 {
+			continue
+		}
+		score, ok := firstNumber(parts[2:])
+		if !ok {
+			continue
+		}
+		pairs = append(pairs, SimilarityPair{
+			Word1: strings.ToLower(parts[0]),
+			Word2: strings.ToLower(parts[1]),
+			Score: score,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении набора данных: %v", err)
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("в наборе данных %s нет пар слов", filename)
+	}
+	return pairs, nil
+}
+
+// firstNumber возвращает первое значение, которое удалось разобрать как число
+func firstNumber(values []string) (float64, bool) {
+	for _, v := range values {
+		if f, err := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// EvaluateSimilarity сравнивает косинусное сходство модели с оценками людей.
+// Пары, в которых хотя бы одного слова нет в модели, пропускаются и учитываются в доле OOV.
+func EvaluateSimilarity(model *vectors.Model, name string, pairs []SimilarityPair) SimilarityResult {
+	var human, predicted []float64
+	for _, p := range pairs {
+		sim, err := model.Similarity(p.Word1, p.Word2)
+		if err != nil {
+			continue
+		}
+		human = append(human, p.Score)
+		predicted = append(predicted, sim)
+	}
+
+	result := SimilarityResult{
+		Dataset: name,
+		Pairs:   len(pairs),
+		Found:   len(human),
+		OOVRate: 1 - float64(len(human))/float64(len(pairs)),
+	}
+	if len(human) > 1 {
+		result.Spearman = Spearman(human, predicted)
+		result.Pearson = Pearson(human, predicted)
+	}
+	return result
+}
+
+// DatasetName возвращает имя набора данных по имени файла
+func DatasetName(filename string) string {
+	return strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+}
+
+// Pearson вычисляет коэффициент корреляции Пирсона
+func Pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= n
+	my /= n
+
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// Spearman вычисляет коэффициент ранговой корреляции Спирмена (с усреднением рангов при совпадениях)
+func Spearman(x, y []float64) float64 {
+	return Pearson(ranks(x), ranks(y))
+}
+
+// ranks возвращает ранги значений; совпадающим значениям назначается средний ранг
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	result := make([]float64, len(values))
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && values[order[j+1]] == values[order[i]] {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			result[order[k]] = rank
+		}
+		i = j + 1
+	}
+	return result
+}
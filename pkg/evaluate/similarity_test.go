@@ -0,0 +1,90 @@
+package evaluate
+
+import (
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadSimilarityDataset(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"tab.tsv", "word1\tword2\tscore\nКот\tпёс\t7,5\n# комментарий\nкот\tдом\t1.25\n"},
+		{"csv.csv", "word1,word2,SimLex999\nкот,пёс,7.5\nкот,дом,1.25\n"},
+		{"spaces.txt", "кот пёс pos 7.5 0.3\nкот дом pos 1.25 0.1\n"},
+	}
+	for _, tt := range tests {
+		pairs, err := LoadSimilarityDataset(writeFile(t, tt.name, tt.content))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		want := []SimilarityPair{{"кот", "пёс", 7.5}, {"кот", "дом", 1.25}}
+		if len(pairs) != 2 || pairs[0] != want[0] || pairs[1] != want[1] {
+			t.Errorf("%s: %v, ожидалось %v", tt.name, pairs, want)
+		}
+	}
+	if _, err := LoadSimilarityDataset(writeFile(t, "empty.txt", "word1 word2 score\n")); err == nil {
+		t.Error("ожидалась ошибка для набора без пар")
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	if got := Pearson(x, []float64{2, 4, 6, 8, 10}); math.Abs(got-1) > 1e-12 {
+		t.Errorf("Pearson линейной зависимости = %v", got)
+	}
+	if got := Pearson(x, []float64{5, 4, 3, 2, 1}); math.Abs(got+1) > 1e-12 {
+		t.Errorf("Pearson обратной зависимости = %v", got)
+	}
+	if got := Pearson(x, []float64{3, 3, 3, 3, 3}); got != 0 {
+		t.Errorf("Pearson с постоянной = %v", got)
+	}
+	// Монотонная нелинейная зависимость: Спирмен равен 1, Пирсон меньше
+	y := []float64{1, 8, 27, 64, 125}
+	if got := Spearman(x, y); math.Abs(got-1) > 1e-12 {
+		t.Errorf("Spearman монотонной зависимости = %v", got)
+	}
+	if Pearson(x, y) >= 1-1e-9 {
+		t.Error("Pearson нелинейной зависимости равен 1")
+	}
+	if got := ranks([]float64{10, 20, 20, 5}); got[0] != 2 || got[1] != 3.5 || got[2] != 3.5 || got[3] != 1 {
+		t.Errorf("ranks с совпадениями = %v", got)
+	}
+}
+
+func TestEvaluateSimilarity(t *testing.T) {
+	model := vectors.New(
+		[]string{"a", "b", "c", "d"},
+		[][]float64{{1, 0}, {1, 0.1}, {1, 1}, {0, 1}},
+	)
+	pairs := []SimilarityPair{
+		{"a", "b", 9}, // Почти совпадают
+		{"a", "c", 5},
+		{"a", "d", 1}, // Ортогональны
+		{"a", "нет", 3},
+	}
+	got := EvaluateSimilarity(model, "toy", pairs)
+	if got.Pairs != 4 || got.Found != 3 || math.Abs(got.OOVRate-0.25) > 1e-12 {
+		t.Errorf("результат %+v", got)
+	}
+	if math.Abs(got.Spearman-1) > 1e-12 {
+		t.Errorf("Spearman = %v, ожидалось 1", got.Spearman)
+	}
+	if DatasetName("/data/simlex-999.txt") != "simlex-999" {
+		t.Errorf("DatasetName = %q", DatasetName("/data/simlex-999.txt"))
+	}
+}
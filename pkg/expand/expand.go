@@ -0,0 +1,213 @@
+package expand
+
+import (
+	"context"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"sort"
+	"strings"
+)
+
+// Term — слово расширения запроса и его вес (косинусное сходство с исходным термином)
+type Term struct {
+	Term   string  `json:"term"`
+	Weight float64 `json:"weight"`
+}
+
+// Token — единица запроса: отдельное слово или известное словосочетание
+type Token struct {
+	Text   string   `json:"text"`
+	Words  []string `json:"words"`
+	Phrase bool     `json:"phrase"` // Словосочетание расширяется целиком, а не по словам
+	Found  bool     `json:"found"`  // Есть ли вектор для единицы
+	Terms  []Term   `json:"terms"`
+}
+
+// Expansion — результат расширения запроса
+type Expansion struct {
+	Query   string  `json:"query"`
+	Cleaned string  `json:"cleaned"`
+	Tokens  []Token `json:"tokens"`
+	Phrase  []Term  `json:"phrase"` // Расширения для запроса в целом
+}
+
+// Options задаёт ограничения расширения
+type Options struct {
+	MaxPerTerm    int     // Максимум расширений на одну единицу запроса
+	MaxPhrase     int     // Максимум расширений для запроса в целом
+	MinSimilarity float64 // Минимальное сходство расширения
+}
+
+// DefaultOptions возвращает ограничения по умолчанию
+func DefaultOptions() Options {
+	return Options{MaxPerTerm: 5, MaxPhrase: 10, MinSimilarity: 0.6}
+}
+
+// Expander расширяет поисковые запросы соседями слов из векторной модели.
+// Безопасен для конкурентного использования после настройки.
+type Expander struct {
+	model     *vectors.Model
+	stopWords map[string]struct{}
+	phrases   map[string]bool
+	maxLen    int // Длина самого длинного известного словосочетания в словах
+}
+
+// New создаёт расширитель запросов для модели; stopWords могут быть nil.
+// Слитные токены модели ("дмитрий_песков") считаются словосочетаниями и без AddPhrases.
+func New(model *vectors.Model, stopWords map[string]struct{}) *Expander {
+	e := &Expander{model: model, stopWords: stopWords, phrases: make(map[string]bool), maxLen: 1}
+	for _, word := range model.Words {
+		e.maxLen = max(e.maxLen, strings.Count(word, "_")+1)
+	}
+	return e
+}
+
+// AddPhrases регистрирует словосочетания (например, n-граммы корпуса),
+// которые расширяются как единое целое
+func (e *Expander) AddPhrases(phrases [][]string) {
+	for _, words := range phrases {
+		if len(words) < 2 {
+			continue
+		}
+		e.phrases[strings.Join(words, " ")] = true
+		if len(words) > e.maxLen {
+			e.maxLen = len(words)
+		}
+	}
+}
+
+// Expand очищает запрос так же, как корпус (textprocessor.CleanText), удаляет стоп-слова,
+// объединяет известные словосочетания и подбирает расширения для каждой единицы
+// запроса и для запроса в целом
+func (e *Expander) Expand(query string, opts Options) *Expansion {
+	result, _ := e.ExpandContext(context.Background(), query, opts)
+	return result
+}
+
+// ExpandContext работает как Expand, но прекращает поиск соседей при отмене контекста
+// и возвращает его ошибку
+func (e *Expander) ExpandContext(ctx context.Context, query string, opts Options) (*Expansion, error) {
+	words := strings.Fields(textprocessor.RemoveStopWords(textprocessor.CleanText(query), e.stopWords))
+	result := &Expansion{Query: query, Cleaned: strings.Join(words, " "), Tokens: []Token{}, Phrase: []Term{}}
+
+	exclude := make(map[string]bool)
+	for _, word := range words {
+		exclude[word] = true
+	}
+
+	var found [][]float64
+	for _, unit := range e.segment(words) {
+		token := Token{Text: strings.Join(unit, " "), Words: unit, Phrase: len(unit) > 1, Terms: []Term{}}
+		vec := e.vector(unit)
+		if vec != nil {
+			token.Found = true
+			exclude[strings.Join(unit, "_")] = true
+			terms, err := e.neighbors(ctx, vec, opts.MaxPerTerm, opts.MinSimilarity, exclude)
+			if err != nil {
+				return nil, err
+			}
+			token.Terms = terms
+			found = append(found, vectors.Normalize(vec))
+		}
+		result.Tokens = append(result.Tokens, token)
+	}
+
+	// Для запроса из нескольких единиц ищутся соседи его среднего вектора
+	if len(found) > 1 {
+		terms, err := e.neighbors(ctx, vectors.Mean(found), opts.MaxPhrase, opts.MinSimilarity, exclude)
+		if err != nil {
+			return nil, err
+		}
+		result.Phrase = terms
+	}
+	return result, nil
+}
+
+// Terms возвращает все термины запроса с весами: исходные слова с весом 1
+// и расширения; при повторах остаётся наибольший вес
+func (x *Expansion) Terms() []Term {
+	weights := make(map[string]float64)
+	var order []string
+	add := func(term string, weight float64) {
+		if w, ok := weights[term]; !ok {
+			order = append(order, term)
+			weights[term] = weight
+		} else if weight > w {
+			weights[term] = weight
+		}
+	}
+	for _, token := range x.Tokens {
+		add(token.Text, 1)
+		for _, t := range token.Terms {
+			add(t.Term, t.Weight)
+		}
+	}
+	for _, t := range x.Phrase {
+		add(t.Term, t.Weight)
+	}
+
+	terms := make([]Term, len(order))
+	for i, term := range order {
+		terms[i] = Term{Term: term, Weight: weights[term]}
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return terms[i].Weight > terms[j].Weight })
+	return terms
+}
+
+// segment разбивает слова запроса на единицы, жадно выбирая самые длинные
+// известные словосочетания
+func (e *Expander) segment(words []string) [][]string {
+	var units [][]string
+	for i := 0; i < len(words); {
+		length := 1
+		for n := min(e.maxLen, len(words)-i); n > 1; n-- {
+			if e.isPhrase(words[i : i+n]) {
+				length = n
+				break
+			}
+		}
+		units = append(units, words[i:i+length])
+		i += length
+	}
+	return units
+}
+
+// isPhrase сообщает, известно ли словосочетание: из n-грамм или как слитный токен модели ("дмитрий_песков")
+func (e *Expander) isPhrase(words []string) bool {
+	return e.phrases[strings.Join(words, " ")] || e.model.Has(strings.Join(words, "_"))
+}
+
+// vector возвращает вектор единицы запроса: слитного токена, если он есть в модели,
+// иначе — среднее нормированных векторов её слов. Слитный токен ищется только
+// среди слов модели: вектор, построенный для него по n-граммам, не заменяет
+// векторы слов словосочетания.
+func (e *Expander) vector(unit []string) []float64 {
+	if len(unit) > 1 {
+		if i, ok := e.model.Index(strings.Join(unit, "_")); ok {
+			return e.model.Vectors[i]
+		}
+	}
+	var vecs [][]float64
+	for _, word := range unit {
+		if vec, ok := e.model.Vector(word); ok {
+			vecs = append(vecs, vectors.Normalize(vec))
+		}
+	}
+	return vectors.Mean(vecs)
+}
+
+// neighbors возвращает соседей вектора со сходством не ниже порога
+func (e *Expander) neighbors(ctx context.Context, vec []float64, limit int, minSimilarity float64, exclude map[string]bool) ([]Term, error) {
+	nearest, err := e.model.NearestContext(ctx, vec, limit, exclude)
+	if err != nil {
+		return nil, err
+	}
+	terms := []Term{}
+	for _, n := range nearest {
+		if n.Similarity < minSimilarity {
+			break
+		}
+		terms = append(terms, Term{Term: strings.ReplaceAll(n.Word, "_", " "), Weight: n.Similarity})
+	}
+	return terms, nil
+}
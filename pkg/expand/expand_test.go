@@ -0,0 +1,121 @@
+package expand
+
+import (
+	"context"
+	"glove-pipeline/pkg/vectors"
+	"reflect"
+	"testing"
+)
+
+func testModel() *vectors.Model {
+	return vectors.New(
+		[]string{"дмитрий_песков", "песков", "пресс_секретарь", "кремль", "дмитрий", "собака", "пёс", "кот"},
+		[][]float64{
+			{1, 0, 0, 0.1},
+			{1, 0.3, 0, 0},
+			{0.95, 0, 0.1, 0.1},
+			{0.7, 0, 0.7, 0},
+			{0.2, 1, 0, 0},
+			{0, 0, 0, 1},
+			{0, 0.1, 0, 1},
+			{0, 0, 0.5, 0.5},
+		},
+	)
+}
+
+func TestSegment(t *testing.T) {
+	e := New(testModel(), nil)
+	e.AddPhrases([][]string{{"злая", "собака"}, {"одно"}})
+	tests := []struct {
+		words []string
+		want  [][]string
+	}{
+		{[]string{"дмитрий", "песков", "сказал"}, [][]string{{"дмитрий", "песков"}, {"сказал"}}},
+		{[]string{"злая", "собака", "кот"}, [][]string{{"злая", "собака"}, {"кот"}}},
+		{[]string{"песков", "дмитрий"}, [][]string{{"песков"}, {"дмитрий"}}},
+	}
+	for _, tt := range tests {
+		if got := e.segment(tt.words); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("segment(%v) = %v, ожидалось %v", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	e := New(testModel(), map[string]struct{}{"и": {}})
+	x := e.Expand("Дмитрий Песков и собака, неизвестное!", Options{MaxPerTerm: 2, MaxPhrase: 3, MinSimilarity: 0.9})
+	if x.Cleaned != "дмитрий песков собака неизвестное" {
+		t.Errorf("очищенный запрос %q", x.Cleaned)
+	}
+	if len(x.Tokens) != 3 {
+		t.Fatalf("единицы запроса %+v", x.Tokens)
+	}
+	phrase, dog, unknown := x.Tokens[0], x.Tokens[1], x.Tokens[2]
+	if !phrase.Phrase || !phrase.Found || phrase.Text != "дмитрий песков" {
+		t.Errorf("словосочетание %+v", phrase)
+	}
+	// Слова запроса и сам слитный токен в расширения не попадают
+	if len(phrase.Terms) != 1 || phrase.Terms[0].Term != "пресс секретарь" {
+		t.Errorf("расширения словосочетания %+v", phrase.Terms)
+	}
+	if len(dog.Terms) != 1 || dog.Terms[0].Term != "пёс" {
+		t.Errorf("расширения слова %+v", dog.Terms)
+	}
+	if unknown.Found || len(unknown.Terms) != 0 {
+		t.Errorf("неизвестное слово %+v", unknown)
+	}
+	for _, term := range x.Phrase {
+		if term.Weight < 0.9 {
+			t.Errorf("расширение запроса ниже порога: %+v", term)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	x := &Expansion{
+		Tokens: []Token{
+			{Text: "песков", Terms: []Term{{"пресс секретарь", 0.8}, {"кремль", 0.7}}},
+			{Text: "кремль", Terms: []Term{{"пресс секретарь", 0.9}}},
+		},
+		Phrase: []Term{{"кремль", 0.95}, {"власть", 0.75}},
+	}
+	want := []Term{{"песков", 1}, {"кремль", 1}, {"пресс секретарь", 0.9}, {"власть", 0.75}}
+	if got := x.Terms(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %v, ожидалось %v", got, want)
+	}
+}
+
+func TestExpandCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New(testModel(), nil).ExpandContext(ctx, "собака", DefaultOptions()); err == nil {
+		t.Error("ожидалась ошибка отменённого контекста")
+	}
+}
+
+func TestExpandFallback(t *testing.T) {
+	model := testModel()
+	forms := map[string]string{"собаки": "собака", "злая_собака": "кот"}
+	model.SetFallback(func(word string) ([]float64, bool) {
+		i, ok := model.Index(forms[word])
+		if !ok {
+			return nil, false
+		}
+		return model.Vectors[i], true
+	})
+	e := New(model, nil)
+	e.AddPhrases([][]string{{"злая", "собака"}})
+	opts := Options{MaxPerTerm: 1, MaxPhrase: 1, MinSimilarity: 0.9}
+
+	// Слово вне модели получает вектор Fallback и расширяется начальной формой
+	x := e.Expand("собаки", opts)
+	if len(x.Tokens) != 1 || !x.Tokens[0].Found || len(x.Tokens[0].Terms) != 1 || x.Tokens[0].Terms[0].Term != "собака" {
+		t.Errorf("расширения слова вне модели %+v", x.Tokens)
+	}
+
+	// Вектор словосочетания строится по его словам, а не по Fallback для слитного токена
+	x = e.Expand("злая собака", opts)
+	if len(x.Tokens) != 1 || !x.Tokens[0].Phrase || len(x.Tokens[0].Terms) != 1 || x.Tokens[0].Terms[0].Term != "пёс" {
+		t.Errorf("расширения словосочетания %+v", x.Tokens)
+	}
+}
@@ -1,16 +1,73 @@
 package glove
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
+// Config задаёт пути к входным и выходным файлам одного запуска GloVe
+type Config struct {
+	InputFile        string // Очищенный корпус
+	VocabFile        string // Словарь
+	CooccurrenceFile string // Файл совместной встречаемости
+	VectorsFile      string // Префикс файла векторов (GloVe добавляет расширение .txt)
+}
+
+// DirConfig возвращает конфигурацию, в которой все файлы лежат в каталоге dir
+// под стандартными именами (cleaned_corpus.txt, vocab.txt, cooccurrence.bin, vectors.txt)
+func DirConfig(dir string) Config {
+	return Config{
+		InputFile:        filepath.Join(dir, "cleaned_corpus.txt"),
+		VocabFile:        filepath.Join(dir, "vocab.txt"),
+		CooccurrenceFile: filepath.Join(dir, "cooccurrence.bin"),
+		VectorsFile:      filepath.Join(dir, "vectors.txt"),
+	}
+}
+
+// VectorsTextFile возвращает путь к текстовому файлу векторов, который создаёт GloVe
+func (c Config) VectorsTextFile() string {
+	vectorsFile := c.VectorsFile
+	if vectorsFile == "" {
+		vectorsFile = "data/vectors.txt"
+	}
+	return vectorsFile + ".txt"
+}
+
+// env возвращает переменные окружения, переопределяющие пути в glove.sh
+func (c Config) env() []string {
+	var env []string
+	if c.InputFile != "" {
+		env = append(env, "INPUT_FILE="+c.InputFile)
+	}
+	if c.VocabFile != "" {
+		env = append(env, "OUTPUT_VOCAB="+c.VocabFile)
+	}
+	if c.CooccurrenceFile != "" {
+		env = append(env, "OUTPUT_COOCCURRENCE="+c.CooccurrenceFile)
+	}
+	if c.VectorsFile != "" {
+		env = append(env, "OUTPUT_VECTORS="+c.VectorsFile)
+	}
+	return env
+}
+
 // Run запускает GloVe с использованием скрипта glove.sh
 func Run() error {
+	return RunWithConfig(Config{})
+}
+
+// RunWithConfig запускает GloVe с путями из конфигурации.
+// Незаданные поля берутся из значений по умолчанию в glove.sh.
+func RunWithConfig(cfg Config) error {
 	fmt.Println("Запуск GloVe...")
 	cmd := exec.Command("sh", "./scripts/glove.sh")
 	cmd.Dir = "." // Указываем корневую директорию проекта
+	cmd.Env = append(os.Environ(), cfg.env()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	err := cmd.Run()
@@ -19,3 +76,50 @@ func Run() error {
 	}
 	return nil
 }
+
+// VocabEntry — слово словаря GloVe и его частота в корпусе
+type VocabEntry struct {
+	Word  string
+	Count int
+}
+
+// LoadVocab загружает словарь vocab.txt (слово и частота через пробел) в порядке файла
+func LoadVocab(vocabFile string) ([]VocabEntry, error) {
+	file, err := os.Open(vocabFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла словаря: %v", err)
+	}
+	defer file.Close()
+
+	var entries []VocabEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при разборе частоты слова %q: %v", parts[0], err)
+		}
+		entries = append(entries, VocabEntry{Word: parts[0], Count: count})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла словаря: %v", err)
+	}
+	return entries, nil
+}
+
+// VocabCounts загружает словарь в виде отображения слово → частота
+func VocabCounts(vocabFile string) (map[string]int, error) {
+	entries, err := LoadVocab(vocabFile)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(entries))
+	for _, e := range entries {
+		counts[e.Word] = e.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,340 @@
+package keywords
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Method — способ извлечения ключевых слов
+type Method string
+
+const (
+	TextRank  Method = "textrank"  // PageRank по графу совместной встречаемости слов внутри документа
+	RAKE      Method = "rake"      // Rapid Automatic Keyword Extraction: степень слова к его частоте
+	EmbedRank Method = "embedrank" // Сходство вектора фразы с вектором документа
+)
+
+// ParseMethod разбирает название способа извлечения
+func ParseMethod(name string) (Method, error) {
+	switch Method(name) {
+	case TextRank:
+		return TextRank, nil
+	case RAKE:
+		return RAKE, nil
+	case EmbedRank:
+		return EmbedRank, nil
+	}
+	return "", fmt.Errorf("неизвестный способ извлечения %q (ожидается textrank, rake или embedrank)", name)
+}
+
+// Options задаёт параметры извлечения
+type Options struct {
+	Method    Method
+	Top       int     // Число ключевых фраз документа
+	MaxWords  int     // Максимальная длина фразы в словах
+	MinLength int     // Минимальная длина слова в символах; более короткие слова и числа разделяют фразы
+	Window    int     // TextRank: окно совместной встречаемости в словах
+	Damping   float64 // TextRank: коэффициент затухания PageRank
+	Diversity float64 // EmbedRank: вес разнообразия при отборе фраз (0 — только сходство с документом)
+}
+
+// DefaultOptions возвращает параметры по умолчанию
+func DefaultOptions() Options {
+	return Options{Method: TextRank, Top: 10, MaxWords: 3, MinLength: 3, Window: 4, Damping: 0.85, Diversity: 0.5}
+}
+
+// Keyword — ключевая фраза и её оценка (шкала зависит от способа)
+type Keyword struct {
+	Phrase string  `json:"phrase"`
+	Score  float64 `json:"score"`
+}
+
+// Extractor извлекает ключевые фразы из очищенных текстов. Безопасен для
+// конкурентного использования.
+type Extractor struct {
+	opts      Options
+	stopwords map[string]struct{}
+	embedder  *docvec.Embedder
+}
+
+// New создаёт извлекатель. Стоп-слова разделяют фразы (для RAKE они обязательны);
+// embedder нужен только для EmbedRank.
+func New(stopwords map[string]struct{}, embedder *docvec.Embedder, opts Options) (*Extractor, error) {
+	def := DefaultOptions()
+	if opts.Method == "" {
+		opts.Method = def.Method
+	}
+	if opts.Top <= 0 {
+		opts.Top = def.Top
+	}
+	if opts.MaxWords <= 0 {
+		opts.MaxWords = def.MaxWords
+	}
+	if opts.Window < 2 {
+		opts.Window = def.Window
+	}
+	if opts.Damping <= 0 || opts.Damping >= 1 {
+		opts.Damping = def.Damping
+	}
+	if opts.Method == RAKE && len(stopwords) == 0 {
+		return nil, fmt.Errorf("для RAKE нужен список стоп-слов")
+	}
+	if opts.Method == EmbedRank && embedder == nil {
+		return nil, fmt.Errorf("для EmbedRank нужны векторы слов")
+	}
+	return &Extractor{opts: opts, stopwords: stopwords, embedder: embedder}, nil
+}
+
+// Extract возвращает ключевые фразы очищенного текста по убыванию оценки
+func (e *Extractor) Extract(text string) []Keyword {
+	words := strings.Fields(text)
+	switch e.opts.Method {
+	case RAKE:
+		return e.rake(words)
+	case EmbedRank:
+		return e.embedRank(words)
+	}
+	return e.textRank(words)
+}
+
+// content сообщает, может ли слово входить в ключевую фразу
+func (e *Extractor) content(word string) bool {
+	if _, ok := e.stopwords[word]; ok {
+		return false
+	}
+	if utf8.RuneCountInString(word) < e.opts.MinLength {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates делит текст на фразы-кандидаты: непрерывные цепочки значимых слов,
+// разделённые стоп-словами; длинные цепочки режутся на куски по MaxWords слов
+func (e *Extractor) candidates(words []string) [][]string {
+	var phrases [][]string
+	var run []string
+	flush := func() {
+		for len(run) > 0 {
+			n := min(len(run), e.opts.MaxWords)
+			phrases = append(phrases, run[:n])
+			run = run[n:]
+		}
+	}
+	for _, word := range words {
+		if e.content(word) {
+			run = append(run, word)
+			continue
+		}
+		flush()
+		run = nil
+	}
+	flush()
+	return phrases
+}
+
+// textRank оценивает слова PageRank по графу совместной встречаемости значимых
+// слов в окне Window, а фразу — суммой оценок её слов
+func (e *Extractor) textRank(words []string) []Keyword {
+	var seq []string
+	for _, word := range words {
+		if e.content(word) {
+			seq = append(seq, word)
+		}
+	}
+	index := make(map[string]int)
+	var vocab []string
+	for _, word := range seq {
+		if _, ok := index[word]; !ok {
+			index[word] = len(vocab)
+			vocab = append(vocab, word)
+		}
+	}
+	n := len(vocab)
+	if n == 0 {
+		return nil
+	}
+
+	edges := make([]map[int]float64, n)
+	for i := range edges {
+		edges[i] = make(map[int]float64)
+	}
+	for i := range seq {
+		for j := i + 1; j < len(seq) && j < i+e.opts.Window; j++ {
+			a, b := index[seq[i]], index[seq[j]]
+			if a != b {
+				edges[a][b]++
+				edges[b][a]++
+			}
+		}
+	}
+	degree := make([]float64, n)
+	for i, edge := range edges {
+		for _, w := range edge {
+			degree[i] += w
+		}
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	next := make([]float64, n)
+	d := e.opts.Damping
+	for it := 0; it < 100; it++ {
+		for i := range next {
+			next[i] = (1 - d) / float64(n)
+		}
+		for i, edge := range edges {
+			if degree[i] == 0 {
+				continue
+			}
+			for j, w := range edge {
+				next[j] += d * rank[i] * w / degree[i]
+			}
+		}
+		var diff float64
+		for i := range next {
+			diff += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if diff < 1e-8 {
+			break
+		}
+	}
+
+	return e.scorePhrases(words, func(phrase []string) float64 {
+		var score float64
+		for _, word := range phrase {
+			score += rank[index[word]]
+		}
+		return score
+	})
+}
+
+// rake оценивает слово отношением его степени (суммы длин фраз, в которые оно
+// входит) к частоте, а фразу — суммой оценок её слов
+func (e *Extractor) rake(words []string) []Keyword {
+	freq := make(map[string]float64)
+	degree := make(map[string]float64)
+	for _, phrase := range e.candidates(words) {
+		for _, word := range phrase {
+			freq[word]++
+			degree[word] += float64(len(phrase))
+		}
+	}
+	return e.scorePhrases(words, func(phrase []string) float64 {
+		var score float64
+		for _, word := range phrase {
+			score += degree[word] / freq[word]
+		}
+		return score
+	})
+}
+
+// scorePhrases оценивает уникальные фразы-кандидаты и возвращает Top лучших
+func (e *Extractor) scorePhrases(words []string, score func(phrase []string) float64) []Keyword {
+	seen := make(map[string]bool)
+	var result []Keyword
+	for _, phrase := range e.candidates(words) {
+		text := strings.Join(phrase, " ")
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+		result = append(result, Keyword{Phrase: text, Score: score(phrase)})
+	}
+	return top(result, e.opts.Top)
+}
+
+// embedRank оценивает фразы косинусным сходством их векторов с вектором
+// документа. С Diversity > 0 фразы отбираются по максимальной предельной
+// значимости (MMR, как в EmbedRank++), чтобы не повторять близкие по смыслу.
+func (e *Extractor) embedRank(words []string) []Keyword {
+	doc, _ := e.embedder.Embed(words)
+	if doc == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var phrases []string
+	var vecs [][]float64
+	var sims []float64
+	for _, phrase := range e.candidates(words) {
+		text := strings.Join(phrase, " ")
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+		vec, _ := e.embedder.Embed(phrase)
+		if vec == nil {
+			continue
+		}
+		phrases = append(phrases, text)
+		vecs = append(vecs, vec)
+		sims = append(sims, vectors.CosineSimilarity(vec, doc))
+	}
+
+	if e.opts.Diversity <= 0 {
+		result := make([]Keyword, len(phrases))
+		for i, text := range phrases {
+			result[i] = Keyword{Phrase: text, Score: sims[i]}
+		}
+		return top(result, e.opts.Top)
+	}
+
+	// Похожесть на уже выбранные фразы (наибольшая) уменьшает оценку кандидата
+	lambda := 1 - e.opts.Diversity
+	redundancy := make([]float64, len(phrases))
+	used := make([]bool, len(phrases))
+	var result []Keyword
+	for len(result) < e.opts.Top && len(result) < len(phrases) {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range phrases {
+			if used[i] {
+				continue
+			}
+			s := lambda * sims[i]
+			if len(result) > 0 {
+				s -= (1 - lambda) * redundancy[i]
+			}
+			if s > bestScore {
+				best, bestScore = i, s
+			}
+		}
+		used[best] = true
+		result = append(result, Keyword{Phrase: phrases[best], Score: sims[best]})
+		for i := range phrases {
+			if !used[i] {
+				sim := vectors.CosineSimilarity(vecs[i], vecs[best])
+				if len(result) == 1 || sim > redundancy[i] {
+					redundancy[i] = sim
+				}
+			}
+		}
+	}
+	return result
+}
+
+// top сортирует фразы по убыванию оценки (при равенстве — по алфавиту) и оставляет n первых
+func top(keywords []Keyword, n int) []Keyword {
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Phrase < keywords[j].Phrase
+	})
+	if len(keywords) > n {
+		keywords = keywords[:n]
+	}
+	return keywords
+}
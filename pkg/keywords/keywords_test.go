@@ -0,0 +1,131 @@
+package keywords
+
+import (
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"strings"
+	"testing"
+)
+
+var stopwords = map[string]struct{}{"и": {}, "в": {}, "на": {}, "для": {}}
+
+func TestCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxWords int
+		want     []string
+	}{
+		{"стоп-слова разделяют фразы", "анализ данных и машинное обучение", 3, []string{"анализ данных", "машинное обучение"}},
+		{"короткие слова и числа разделяют фразы", "отчёт за 2024 год продажи", 3, []string{"отчёт", "год продажи"}},
+		{"длинные цепочки режутся по MaxWords", "один два три четыре пять", 2, []string{"один два", "три четыре", "пять"}},
+		{"нет значимых слов", "и в на 42", 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(stopwords, nil, Options{MaxWords: tt.maxWords, MinLength: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, phrase := range e.candidates(strings.Fields(tt.text)) {
+				got = append(got, strings.Join(phrase, " "))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("candidates(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRAKE(t *testing.T) {
+	e, err := New(stopwords, nil, Options{Method: RAKE, MinLength: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Степени: анализ 3, данных 5, машинное 3, обучение 3; частоты: анализ 2, данных 2
+	got := e.Extract("анализ данных и машинное обучение данных и анализ")
+	want := []Keyword{{"машинное обучение данных", 3 + 3 + 2.5}, {"анализ данных", 1.5 + 2.5}, {"анализ", 1.5}}
+	if len(got) != len(want) {
+		t.Fatalf("Extract = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Phrase != want[i].Phrase || math.Abs(got[i].Score-want[i].Score) > 1e-12 {
+			t.Errorf("Extract[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTextRank(t *testing.T) {
+	e, err := New(stopwords, nil, Options{Method: TextRank, MaxWords: 1, MinLength: 3, Top: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// «центр» встречается рядом со всеми остальными словами
+	got := e.Extract("центр альфа и центр бета и центр гамма и центр дельта")
+	if len(got) != 5 || got[0].Phrase != "центр" {
+		t.Fatalf("Extract = %v, want «центр» первым из 5 слов", got)
+	}
+	var sum float64
+	for _, k := range got {
+		sum += k.Score
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("сумма PageRank по однословным фразам = %v, want 1", sum)
+	}
+	if got := e.Extract("и в на"); got != nil {
+		t.Errorf("Extract без значимых слов = %v, want nil", got)
+	}
+}
+
+func TestEmbedRank(t *testing.T) {
+	model := vectors.New(
+		[]string{"кошка", "котёнок", "собака", "погода"},
+		[][]float64{{1, 0, 0}, {0.99, 0.1, 0}, {0.7, 0.7, 0}, {0, 0, 1}},
+	)
+	embedder := docvec.New(model, nil, docvec.Options{Weighting: docvec.Mean})
+	text := "кошка и котёнок и собака и кошка и котёнок и погода"
+	tests := []struct {
+		name      string
+		diversity float64
+		want      []string
+	}{
+		{"только сходство", 0, []string{"котёнок", "кошка"}},
+		{"с разнообразием близкая к первой фраза пропускается", 0.7, []string{"котёнок", "погода"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(stopwords, embedder, Options{Method: EmbedRank, Top: 2, MaxWords: 1, MinLength: 3, Diversity: tt.diversity})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := e.Extract(text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Extract = %v, want %v", got, tt.want)
+			}
+			for i, phrase := range tt.want {
+				if got[i].Phrase != phrase {
+					t.Errorf("Extract = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		stopwords map[string]struct{}
+		method    Method
+	}{
+		{"RAKE без стоп-слов", nil, RAKE},
+		{"EmbedRank без векторов", stopwords, EmbedRank},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.stopwords, nil, Options{Method: tt.method}); err == nil {
+			t.Errorf("%s: New должен вернуть ошибку", tt.name)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package langid
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Unknown — код, возвращаемый для текстов, язык которых определить не удалось
+const Unknown = "und"
+
+// Минимальная и максимальная длина символьных n-грамм
+const (
+	minGram = 1
+	maxGram = 3
+)
+
+//go:embed profiles/*.txt
+var builtinProfiles embed.FS
+
+// Profile хранит частоты символьных n-грамм одного языка
+type Profile struct {
+	Lang   string
+	counts map[string]float64
+	total  float64
+}
+
+// Result содержит результат определения языка
+type Result struct {
+	Lang       string  // Код языка (ru, uk, en) или Unknown
+	Confidence float64 // Апостериорная вероятность лучшего языка (0..1)
+}
+
+// Identifier определяет язык текста наивным байесовским классификатором по символьным n-граммам
+type Identifier struct {
+	profiles []*Profile
+	vocab    map[string]struct{}
+}
+
+// New создаёт классификатор со встроенными профилями указанных языков.
+// Если языки не указаны, используются все встроенные профили.
+func New(langs ...string) (*Identifier, error) {
+	if len(langs) == 0 {
+		langs = BuiltinLanguages()
+	}
+
+	id := &Identifier{vocab: make(map[string]struct{})}
+	for _, lang := range langs {
+		data, err := builtinProfiles.ReadFile("profiles/" + lang + ".txt")
+		if err != nil {
+			return nil, fmt.Errorf("нет встроенного профиля для языка %q", lang)
+		}
+		id.Train(lang, string(data))
+	}
+	return id, nil
+}
+
+// BuiltinLanguages возвращает коды языков, для которых есть встроенные профили
+func BuiltinLanguages() []string {
+	entries, err := builtinProfiles.ReadDir("profiles")
+	if err != nil {
+		return nil
+	}
+	var langs []string
+	for _, entry := range entries {
+		langs = append(langs, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Train дополняет профиль языка n-граммами из обучающего текста.
+// Если профиля ещё нет, он создаётся.
+func (id *Identifier) Train(lang, text string) {
+	var profile *Profile
+	for _, p := range id.profiles {
+		if p.Lang == lang {
+			profile = p
+			break
+		}
+	}
+	if profile == nil {
+		profile = &Profile{Lang: lang, counts: make(map[string]float64)}
+		id.profiles = append(id.profiles, profile)
+	}
+
+	for _, gram := range extractGrams(text) {
+		profile.counts[gram]++
+		profile.total++
+		id.vocab[gram] = struct{}{}
+	}
+}
+
+// Languages возвращает коды языков, известных классификатору
+func (id *Identifier) Languages() []string {
+	langs := make([]string, len(id.profiles))
+	for i, p := range id.profiles {
+		langs[i] = p.Lang
+	}
+	return langs
+}
+
+// Detect определяет язык текста
+func (id *Identifier) Detect(text string) Result {
+	grams := extractGrams(text)
+	if len(grams) == 0 || len(id.profiles) == 0 {
+		return Result{Lang: Unknown}
+	}
+
+	// Логарифм правдоподобия с аддитивным сглаживанием
+	vocabSize := float64(len(id.vocab))
+	scores := make([]float64, len(id.profiles))
+	for i, p := range id.profiles {
+		denominator := math.Log(p.total + vocabSize)
+		for _, gram := range grams {
+			scores[i] += math.Log(p.counts[gram]+1) - denominator
+		}
+	}
+
+	// Нормализация в вероятности (softmax по логарифмам)
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	var sum float64
+	for i := range scores {
+		sum += math.Exp(scores[i] - scores[best])
+	}
+
+	return Result{Lang: id.profiles[best].Lang, Confidence: 1 / sum}
+}
+
+// extractGrams разбивает текст на слова и возвращает их символьные n-граммы.
+// Слова дополняются пробелами, чтобы учитывать начало и конец слова.
+func extractGrams(text string) []string {
+	var grams []string
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for n := minGram; n <= maxGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram == " " {
+					continue
+				}
+				grams = append(grams, gram)
+			}
+		}
+	}
+	return grams
+}
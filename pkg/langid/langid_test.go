@@ -0,0 +1,81 @@
+package langid
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Правительство утвердило новый план развития транспортной системы города", "ru"},
+		{"Уряд затвердив новий план розвитку транспортної системи міста", "uk"},
+		{"The government approved a new plan for the city transport system", "en"},
+		{"Завтра обещают сильный ветер и мокрый снег, будьте осторожны на дорогах", "ru"},
+		{"Завтра обіцяють сильний вітер і мокрий сніг, будьте обережні на дорогах", "uk"},
+		// Короткие реплики
+		{"привет", "ru"},
+		{"привіт", "uk"},
+		{"спасибо", "ru"},
+		{"дякую", "uk"},
+		{"как дела", "ru"},
+		{"як справи", "uk"},
+		{"hello there", "en"},
+		// Смешанный текст: язык определяется по основной части
+		{"Вчера купил новый iPhone в магазине Apple, доволен покупкой", "ru"},
+		{"Учора купив новий iPhone у крамниці Apple, задоволений покупкою", "uk"},
+		{"We visited Москва last summer and loved the food", "en"},
+	}
+	for _, tt := range tests {
+		if got := id.Detect(tt.text); got.Lang != tt.want {
+			t.Errorf("Detect(%q) = %s (%.2f), ожидался %s", tt.text, got.Lang, got.Confidence, tt.want)
+		}
+	}
+}
+
+func TestDetectUnknown(t *testing.T) {
+	id, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"", "   ", "12345 67", "!!! ??? ..."} {
+		if got := id.Detect(text); got.Lang != Unknown || got.Confidence != 0 {
+			t.Errorf("Detect(%q) = %+v, ожидался %s", text, got, Unknown)
+		}
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	id, err := New("ru", "uk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := id.Detect("Вчера вечером в центре города прошёл концерт, на который пришли тысячи зрителей")
+	if long.Lang != "ru" || long.Confidence < 0.99 {
+		t.Errorf("длинный текст: %+v, ожидалась уверенность в ru", long)
+	}
+	if got := id.Detect("и"); got.Confidence <= 0 || got.Confidence > 1 {
+		t.Errorf("уверенность вне (0, 1]: %+v", got)
+	}
+}
+
+func TestNewUnknownLanguage(t *testing.T) {
+	if _, err := New("xx"); err == nil {
+		t.Error("ожидалась ошибка для языка без профиля")
+	}
+}
+
+func TestTrain(t *testing.T) {
+	id := &Identifier{vocab: make(map[string]struct{})}
+	id.Train("a", "aaa aa a")
+	id.Train("b", "bbb bb b")
+	if got := id.Detect("aaaa"); got.Lang != "a" {
+		t.Errorf("Detect(aaaa) = %s, ожидался a", got.Lang)
+	}
+	if got := id.Languages(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Languages() = %v", got)
+	}
+}
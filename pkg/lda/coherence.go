@@ -0,0 +1,122 @@
+package lda
+
+import (
+	"glove-pipeline/pkg/vectors"
+	"math"
+)
+
+// Cooccurrence — документные частоты слов и пар слов, нужные для оценки согласованности тем
+type Cooccurrence struct {
+	docs  int
+	df    map[int32]int
+	pairs map[[2]int32]int
+}
+
+// NewCooccurrence считает, в скольких документах корпуса встречается каждое
+// слово из words и каждая их пара
+func NewCooccurrence(c *Corpus, words []int32) *Cooccurrence {
+	wanted := make(map[int32]bool, len(words))
+	for _, w := range words {
+		wanted[w] = true
+	}
+	co := &Cooccurrence{docs: len(c.Docs), df: make(map[int32]int), pairs: make(map[[2]int32]int)}
+	for _, doc := range c.Docs {
+		seen := make(map[int32]bool)
+		var present []int32
+		for _, w := range doc {
+			if wanted[w] && !seen[w] {
+				seen[w] = true
+				present = append(present, w)
+			}
+		}
+		for i, a := range present {
+			co.df[a]++
+			for _, b := range present[i+1:] {
+				co.pairs[pairKey(a, b)]++
+			}
+		}
+	}
+	return co
+}
+
+func pairKey(a, b int32) [2]int32 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int32{a, b}
+}
+
+// UMass — согласованность темы по Mimno et al.: среднее log((D(wᵢ, wⱼ) + 1) / D(wⱼ))
+// по парам, где wⱼ стоит в теме выше wᵢ. Ближе к нулю — лучше.
+func (co *Cooccurrence) UMass(words []int32) float64 {
+	var sum float64
+	pairs := 0
+	for i := 1; i < len(words); i++ {
+		for j := 0; j < i; j++ {
+			dj := co.df[words[j]]
+			if dj == 0 {
+				continue
+			}
+			sum += math.Log(float64(co.pairs[pairKey(words[i], words[j])]+1) / float64(dj))
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return sum / float64(pairs)
+}
+
+// NPMI — средняя нормированная поточечная взаимная информация пар слов темы
+// по совместной встречаемости в документах (от −1 до 1, больше — лучше)
+func (co *Cooccurrence) NPMI(words []int32) float64 {
+	if co.docs == 0 {
+		return 0
+	}
+	n := float64(co.docs)
+	var sum float64
+	pairs := 0
+	for i := 0; i < len(words); i++ {
+		for j := i + 1; j < len(words); j++ {
+			pi := float64(co.df[words[i]]) / n
+			pj := float64(co.df[words[j]]) / n
+			pij := float64(co.pairs[pairKey(words[i], words[j])]) / n
+			pairs++
+			switch {
+			case pi == 0 || pj == 0 || pij == 0:
+				sum -= 1 // Слова ни разу не встретились вместе
+			case pij == 1:
+				sum += 1
+			default:
+				sum += math.Log(pij/(pi*pj)) / -math.Log(pij)
+			}
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return sum / float64(pairs)
+}
+
+// EmbeddingCoherence — среднее косинусное сходство векторов пар слов темы.
+// Слова, которых нет в модели, не учитываются; ok равно false, если их меньше двух.
+func EmbeddingCoherence(model *vectors.Model, words []string) (float64, bool) {
+	var vecs [][]float64
+	for _, word := range words {
+		if vec, ok := model.Vector(word); ok {
+			vecs = append(vecs, vec)
+		}
+	}
+	if len(vecs) < 2 {
+		return 0, false
+	}
+	var sum float64
+	pairs := 0
+	for i := range vecs {
+		for j := i + 1; j < len(vecs); j++ {
+			sum += vectors.CosineSimilarity(vecs[i], vecs[j])
+			pairs++
+		}
+	}
+	return sum / float64(pairs), true
+}
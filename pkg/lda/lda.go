@@ -0,0 +1,397 @@
+package lda
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Corpus — документы в виде номеров слов словаря
+type Corpus struct {
+	Vocab  []string
+	Docs   [][]int32
+	Source []int // Номер документа во входных данных (документы без слов словаря пропускаются)
+	index  map[string]int32
+	added  int
+}
+
+// NewCorpus создаёт пустой корпус со словарём vocab
+func NewCorpus(vocab []string) *Corpus {
+	c := &Corpus{Vocab: vocab, index: make(map[string]int32, len(vocab))}
+	for i, word := range vocab {
+		c.index[word] = int32(i)
+	}
+	return c
+}
+
+// Add добавляет документ (список слов). Слова вне словаря отбрасываются;
+// документ, в котором осталось меньше двух слов, пропускается (added = false).
+func (c *Corpus) Add(words []string) (added bool) {
+	source := c.added
+	c.added++
+	var doc []int32
+	for _, word := range words {
+		if id, ok := c.index[word]; ok {
+			doc = append(doc, id)
+		}
+	}
+	if len(doc) < 2 {
+		return false
+	}
+	c.Docs = append(c.Docs, doc)
+	c.Source = append(c.Source, source)
+	return true
+}
+
+// Tokens возвращает число слов корпуса
+func (c *Corpus) Tokens() int {
+	n := 0
+	for _, doc := range c.Docs {
+		n += len(doc)
+	}
+	return n
+}
+
+// Split откладывает случайную долю share документов для оценки перплексии
+func (c *Corpus) Split(share float64, seed int64) (train, test *Corpus) {
+	train = &Corpus{Vocab: c.Vocab, index: c.index}
+	test = &Corpus{Vocab: c.Vocab, index: c.index}
+	rng := rand.New(rand.NewSource(seed))
+	for i, doc := range c.Docs {
+		target := train
+		if rng.Float64() < share {
+			target = test
+		}
+		target.Docs = append(target.Docs, doc)
+		target.Source = append(target.Source, c.Source[i])
+	}
+	return train, test
+}
+
+// Options задаёт параметры модели и сэмплирования
+type Options struct {
+	K          int     // Число тем
+	Alpha      float64 // Параметр априорного распределения тем документа (0 — 50/K)
+	Beta       float64 // Параметр априорного распределения слов темы
+	Iterations int     // Число итераций сэмплирования по Гиббсу
+	Threads    int     // Число потоков (документы делятся между потоками, как в AD-LDA)
+	Seed       int64
+	Every      int // Как часто вызывать progress (в итерациях, 0 — не вызывать)
+}
+
+// DefaultOptions возвращает параметры по умолчанию
+func DefaultOptions() Options {
+	return Options{K: 20, Beta: 0.01, Iterations: 500, Threads: runtime.NumCPU(), Seed: 1, Every: 50}
+}
+
+// Model — обученная модель LDA: счётчики слов по темам и тем по документам обучающего корпуса
+type Model struct {
+	K     int
+	Alpha float64
+	Beta  float64
+	Vocab []string
+	nwk   [][]int32 // nwk[слово][тема]
+	nk    []int64   // Число слов в каждой теме
+	ndk   [][]int32 // ndk[документ][тема]
+	nd    []int     // Длина документа
+}
+
+// Train обучает LDA свёрнутым сэмплированием по Гиббсу. При Threads > 1 документы
+// делятся между потоками; каждый поток сэмплирует по своей копии счётчиков слов,
+// и после итерации изменения складываются (приближённый распределённый LDA,
+// Newman et al.). progress получает номер итерации и перплексию обучающего корпуса.
+func Train(c *Corpus, opts Options, progress func(iteration int, perplexity float64)) (*Model, error) {
+	def := DefaultOptions()
+	if opts.K < 2 {
+		return nil, fmt.Errorf("число тем должно быть не меньше 2")
+	}
+	if len(c.Docs) == 0 {
+		return nil, fmt.Errorf("в корпусе нет документов со словами словаря")
+	}
+	if opts.Alpha <= 0 {
+		opts.Alpha = 50 / float64(opts.K)
+	}
+	if opts.Beta <= 0 {
+		opts.Beta = def.Beta
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = def.Iterations
+	}
+	threads := min(max(opts.Threads, 1), len(c.Docs))
+
+	k, v := opts.K, len(c.Vocab)
+	m := &Model{K: k, Alpha: opts.Alpha, Beta: opts.Beta, Vocab: c.Vocab, nwk: make([][]int32, v), nk: make([]int64, k),
+		ndk: make([][]int32, len(c.Docs)), nd: make([]int, len(c.Docs))}
+	for w := range m.nwk {
+		m.nwk[w] = make([]int32, k)
+	}
+
+	// Случайное начальное назначение тем
+	rng := rand.New(rand.NewSource(opts.Seed))
+	z := make([][]int32, len(c.Docs))
+	for d, doc := range c.Docs {
+		z[d] = make([]int32, len(doc))
+		m.ndk[d] = make([]int32, k)
+		m.nd[d] = len(doc)
+		for i, w := range doc {
+			t := int32(rng.Intn(k))
+			z[d][i] = t
+			m.ndk[d][t]++
+			m.nwk[w][t]++
+			m.nk[t]++
+		}
+	}
+
+	// Каждый поток получает непрерывный диапазон документов и свой генератор
+	bounds := make([]int, threads+1)
+	for p := range bounds {
+		bounds[p] = p * len(c.Docs) / threads
+	}
+	rngs := make([]*rand.Rand, threads)
+	for p := range rngs {
+		rngs[p] = rand.New(rand.NewSource(opts.Seed + int64(p) + 1))
+	}
+	var local []*counts
+	if threads > 1 {
+		local = make([]*counts, threads)
+		for p := range local {
+			local[p] = newCounts(v, k)
+		}
+	}
+
+	for it := 1; it <= opts.Iterations; it++ {
+		if threads == 1 {
+			m.sweep(c.Docs, z, 0, len(c.Docs), m.nwk, m.nk, rngs[0])
+		} else {
+			var wg sync.WaitGroup
+			for p := 0; p < threads; p++ {
+				wg.Add(1)
+				go func(p int) {
+					defer wg.Done()
+					local[p].copyFrom(m.nwk, m.nk)
+					m.sweep(c.Docs, z, bounds[p], bounds[p+1], local[p].nwk, local[p].nk, rngs[p])
+				}(p)
+			}
+			wg.Wait()
+			m.merge(local)
+		}
+		if progress != nil && opts.Every > 0 && (it%opts.Every == 0 || it == opts.Iterations) {
+			progress(it, m.trainPerplexity(c))
+		}
+	}
+	return m, nil
+}
+
+// sweep пересэмплирует темы всех слов документов [from, to) по счётчикам nwk и nk
+func (m *Model) sweep(docs [][]int32, z [][]int32, from, to int, nwk [][]int32, nk []int64, rng *rand.Rand) {
+	k := m.K
+	vBeta := float64(len(m.Vocab)) * m.Beta
+	p := make([]float64, k)
+	for d := from; d < to; d++ {
+		ndk := m.ndk[d]
+		for i, w := range docs[d] {
+			t := z[d][i]
+			ndk[t]--
+			nwk[w][t]--
+			nk[t]--
+
+			var sum float64
+			row := nwk[w]
+			for j := 0; j < k; j++ {
+				sum += (float64(ndk[j]) + m.Alpha) * (float64(row[j]) + m.Beta) / (float64(nk[j]) + vBeta)
+				p[j] = sum
+			}
+			u := rng.Float64() * sum
+			t = int32(sort.SearchFloat64s(p, u))
+			if int(t) >= k {
+				t = int32(k - 1)
+			}
+
+			z[d][i] = t
+			ndk[t]++
+			nwk[w][t]++
+			nk[t]++
+		}
+	}
+}
+
+// counts — локальная копия счётчиков слов потока
+type counts struct {
+	nwk [][]int32
+	nk  []int64
+}
+
+func newCounts(v, k int) *counts {
+	c := &counts{nwk: make([][]int32, v), nk: make([]int64, k)}
+	for w := range c.nwk {
+		c.nwk[w] = make([]int32, k)
+	}
+	return c
+}
+
+func (c *counts) copyFrom(nwk [][]int32, nk []int64) {
+	for w := range nwk {
+		copy(c.nwk[w], nwk[w])
+	}
+	copy(c.nk, nk)
+}
+
+// merge складывает изменения счётчиков всех потоков: global += Σ(local − global)
+func (m *Model) merge(local []*counts) {
+	for w, row := range m.nwk {
+		for t, base := range row {
+			sum := base
+			for _, l := range local {
+				sum += l.nwk[w][t] - base
+			}
+			row[t] = sum
+		}
+	}
+	for t, base := range m.nk {
+		sum := base
+		for _, l := range local {
+			sum += l.nk[t] - base
+		}
+		m.nk[t] = sum
+	}
+}
+
+// Phi возвращает вероятность слова w в теме t
+func (m *Model) Phi(t, w int) float64 {
+	return (float64(m.nwk[w][t]) + m.Beta) / (float64(m.nk[t]) + float64(len(m.Vocab))*m.Beta)
+}
+
+// Theta возвращает распределение тем документа d обучающего корпуса
+func (m *Model) Theta(d int) []float64 {
+	theta := make([]float64, m.K)
+	total := float64(m.nd[d]) + float64(m.K)*m.Alpha
+	for t := range theta {
+		theta[t] = (float64(m.ndk[d][t]) + m.Alpha) / total
+	}
+	return theta
+}
+
+// WordProb — слово темы и его вероятность
+type WordProb struct {
+	Word string  `json:"word"`
+	ID   int     `json:"-"`
+	Prob float64 `json:"prob"`
+}
+
+// TopWords возвращает n самых вероятных слов темы t
+func (m *Model) TopWords(t, n int) []WordProb {
+	words := make([]WordProb, len(m.Vocab))
+	for w := range words {
+		words[w] = WordProb{Word: m.Vocab[w], ID: w, Prob: m.Phi(t, w)}
+	}
+	sort.SliceStable(words, func(i, j int) bool { return words[i].Prob > words[j].Prob })
+	if len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// Size возвращает долю слов корпуса, отнесённых к теме t
+func (m *Model) Size(t int) float64 {
+	var total int64
+	for _, n := range m.nk {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(m.nk[t]) / float64(total)
+}
+
+// Infer оценивает распределение тем нового документа сэмплированием
+// с фиксированными распределениями слов тем
+func (m *Model) Infer(doc []int32, iterations int, rng *rand.Rand) []float64 {
+	k := m.K
+	phi := make([][]float64, len(doc))
+	for i, w := range doc {
+		phi[i] = make([]float64, k)
+		for t := 0; t < k; t++ {
+			phi[i][t] = m.Phi(t, int(w))
+		}
+	}
+	z := make([]int, len(doc))
+	ndk := make([]float64, k)
+	for i := range doc {
+		z[i] = rng.Intn(k)
+		ndk[z[i]]++
+	}
+	p := make([]float64, k)
+	for it := 0; it < iterations; it++ {
+		for i := range doc {
+			ndk[z[i]]--
+			var sum float64
+			for t := 0; t < k; t++ {
+				sum += (ndk[t] + m.Alpha) * phi[i][t]
+				p[t] = sum
+			}
+			t := min(sort.SearchFloat64s(p, rng.Float64()*sum), k-1)
+			z[i] = t
+			ndk[t]++
+		}
+	}
+	theta := make([]float64, k)
+	total := float64(len(doc)) + float64(k)*m.Alpha
+	for t := range theta {
+		theta[t] = (ndk[t] + m.Alpha) / total
+	}
+	return theta
+}
+
+// trainPerplexity — перплексия обучающего корпуса по текущим оценкам θ и φ
+func (m *Model) trainPerplexity(c *Corpus) float64 {
+	var logLik float64
+	var n int
+	for d, doc := range c.Docs {
+		theta := m.Theta(d)
+		for _, w := range doc {
+			logLik += math.Log(m.wordProb(theta, int(w)))
+		}
+		n += len(doc)
+	}
+	return math.Exp(-logLik / float64(n))
+}
+
+// wordProb — вероятность слова w в документе с распределением тем theta
+func (m *Model) wordProb(theta []float64, w int) float64 {
+	var p float64
+	for t, th := range theta {
+		p += th * m.Phi(t, w)
+	}
+	return p
+}
+
+// Perplexity оценивает перплексию на отложенных документах дополнением
+// документа: темы документа выводятся по словам на чётных позициях,
+// а вероятность считается для слов на нечётных
+func (m *Model) Perplexity(c *Corpus, iterations int, seed int64) float64 {
+	rng := rand.New(rand.NewSource(seed))
+	var logLik float64
+	var n int
+	for _, doc := range c.Docs {
+		var observed, held []int32
+		for i, w := range doc {
+			if i%2 == 0 {
+				observed = append(observed, w)
+			} else {
+				held = append(held, w)
+			}
+		}
+		theta := m.Infer(observed, iterations, rng)
+		for _, w := range held {
+			logLik += math.Log(m.wordProb(theta, int(w)))
+		}
+		n += len(held)
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Exp(-logLik / float64(n))
+}
@@ -0,0 +1,173 @@
+package lda
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// twoTopics возвращает корпус из документов двух тем с непересекающимися словарями
+func twoTopics(docs int, seed int64) *Corpus {
+	vocab := []string{"мяч", "гол", "матч", "тренер", "атом", "ядро", "заряд", "поле"}
+	c := NewCorpus(vocab)
+	rng := rand.New(rand.NewSource(seed))
+	for d := 0; d < docs; d++ {
+		offset := 4 * (d % 2)
+		words := make([]string, 20)
+		for i := range words {
+			words[i] = vocab[offset+rng.Intn(4)]
+		}
+		c.Add(words)
+	}
+	return c
+}
+
+// checkCounts проверяет согласованность счётчиков модели с корпусом
+func checkCounts(t *testing.T, m *Model, c *Corpus) {
+	t.Helper()
+	wordTotals := make([]int64, len(c.Vocab))
+	for _, doc := range c.Docs {
+		for _, w := range doc {
+			wordTotals[w]++
+		}
+	}
+	for d, doc := range c.Docs {
+		var sum int32
+		for _, n := range m.ndk[d] {
+			if n < 0 {
+				t.Fatalf("документ %d: отрицательный счётчик темы", d)
+			}
+			sum += n
+		}
+		if int(sum) != len(doc) || m.nd[d] != len(doc) {
+			t.Fatalf("документ %d: сумма по темам %d, длина %d, want %d", d, sum, m.nd[d], len(doc))
+		}
+	}
+	topicTotals := make([]int64, m.K)
+	for w, row := range m.nwk {
+		var sum int64
+		for k, n := range row {
+			if n < 0 {
+				t.Fatalf("слово %d: отрицательный счётчик темы %d", w, k)
+			}
+			sum += int64(n)
+			topicTotals[k] += int64(n)
+		}
+		if sum != wordTotals[w] {
+			t.Fatalf("слово %s: сумма по темам %d, в корпусе %d", c.Vocab[w], sum, wordTotals[w])
+		}
+	}
+	var tokens int64
+	for k, n := range m.nk {
+		if n != topicTotals[k] {
+			t.Fatalf("тема %d: nk = %d, сумма по словам %d", k, n, topicTotals[k])
+		}
+		tokens += n
+	}
+	if tokens != int64(c.Tokens()) {
+		t.Fatalf("слов во всех темах %d, в корпусе %d", tokens, c.Tokens())
+	}
+}
+
+func TestTrainCounts(t *testing.T) {
+	c := twoTopics(40, 1)
+	tests := []struct {
+		name    string
+		threads int
+	}{
+		{"один поток", 1},
+		{"несколько потоков", 4},
+		{"потоков больше, чем документов", 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Train(c, Options{K: 2, Alpha: 0.1, Iterations: 50, Threads: tt.threads, Seed: 1}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkCounts(t, m, c)
+
+			for k := 0; k < m.K; k++ {
+				var sum float64
+				for w := range m.Vocab {
+					sum += m.Phi(k, w)
+				}
+				if math.Abs(sum-1) > 1e-9 {
+					t.Errorf("тема %d: сумма φ = %v, want 1", k, sum)
+				}
+			}
+			for d := range c.Docs {
+				var sum float64
+				for _, v := range m.Theta(d) {
+					sum += v
+				}
+				if math.Abs(sum-1) > 1e-9 {
+					t.Fatalf("документ %d: сумма θ = %v, want 1", d, sum)
+				}
+			}
+		})
+	}
+}
+
+func TestTrainSeparatesTopics(t *testing.T) {
+	c := twoTopics(60, 2)
+	m, err := Train(c, Options{K: 2, Alpha: 0.1, Iterations: 100, Threads: 1, Seed: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < m.K; k++ {
+		top := m.TopWords(k, 4)
+		group := top[0].ID / 4
+		for _, wp := range top {
+			if wp.ID/4 != group {
+				t.Errorf("тема %d смешивает словари: %v", k, top)
+				break
+			}
+		}
+	}
+	test := twoTopics(10, 3)
+	if p := m.Perplexity(test, 20, 1); p <= 0 || p > 5 {
+		t.Errorf("перплексия на отложенных документах %v, want ≤ 5 при 4 равновероятных словах темы", p)
+	}
+}
+
+func TestTrainErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Corpus
+		opts Options
+	}{
+		{"одна тема", twoTopics(4, 1), Options{K: 1}},
+		{"пустой корпус", NewCorpus([]string{"a"}), Options{K: 2}},
+	}
+	for _, tt := range tests {
+		if _, err := Train(tt.c, tt.opts, nil); err == nil {
+			t.Errorf("%s: Train должен вернуть ошибку", tt.name)
+		}
+	}
+}
+
+func TestCoherence(t *testing.T) {
+	c := NewCorpus([]string{"a", "b", "c"})
+	c.Add([]string{"a", "b"})
+	c.Add([]string{"a", "b"})
+	c.Add([]string{"c", "c"})
+	co := NewCooccurrence(c, []int32{0, 1, 2})
+	tests := []struct {
+		name  string
+		words []int32
+		umass float64
+		npmi  float64
+	}{
+		{"всегда вместе", []int32{0, 1}, math.Log(3.0 / 2), math.Log((2.0/3)/(4.0/9)) / -math.Log(2.0/3)},
+		{"никогда вместе", []int32{0, 2}, math.Log(1.0 / 2), -1},
+	}
+	for _, tt := range tests {
+		if got := co.UMass(tt.words); math.Abs(got-tt.umass) > 1e-12 {
+			t.Errorf("%s: UMass = %v, want %v", tt.name, got, tt.umass)
+		}
+		if got := co.NPMI(tt.words); math.Abs(got-tt.npmi) > 1e-12 {
+			t.Errorf("%s: NPMI = %v, want %v", tt.name, got, tt.npmi)
+		}
+	}
+}
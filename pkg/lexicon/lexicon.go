@@ -0,0 +1,385 @@
+package lexicon
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// Method — способ распространения полярности по графу соседей
+type Method string
+
+const (
+	// Propagation — распространение меток (Zhou et al.): F = αSF + (1−α)Y
+	// с симметрично нормированной матрицей весов S
+	Propagation Method = "propagation"
+	// SentProp — случайные блуждания с возвратом к затравкам (Hamilton et al., 2016):
+	// персонализированный PageRank отдельно от положительных и отрицательных затравок
+	SentProp Method = "sentprop"
+)
+
+// ParseMethod разбирает название способа распространения
+func ParseMethod(name string) (Method, error) {
+	switch Method(name) {
+	case Propagation:
+		return Propagation, nil
+	case SentProp:
+		return SentProp, nil
+	}
+	return "", fmt.Errorf("неизвестный способ распространения %q (ожидается propagation или sentprop)", name)
+}
+
+// Options задаёт параметры построения словаря
+type Options struct {
+	Method     Method
+	K          int     // Число соседей слова в графе
+	Limit      int     // Сколько первых (самых частых) слов модели размечать (0 — все); затравки добавляются всегда
+	Alpha      float64 // Доля продолжения блуждания (1 − вероятность возврата к затравкам)
+	Iterations int     // Максимальное число итераций
+	Bootstrap  int     // Число повторов на случайных подвыборках затравок (0 — без оценки устойчивости)
+	Sample     float64 // Доля затравок каждого полюса в подвыборке
+	Seed       int64   // Зерно подвыборок
+	Exact      bool    // Точный поиск соседей полным перебором вместо HNSW
+	ANN        ann.Config
+}
+
+// DefaultOptions возвращает параметры по умолчанию
+func DefaultOptions() Options {
+	return Options{Method: SentProp, K: 10, Limit: 20000, Alpha: 0.9, Iterations: 100, Bootstrap: 20, Sample: 0.8, Seed: 1, ANN: ann.DefaultConfig()}
+}
+
+// Entry — слово словаря и его полярность
+type Entry struct {
+	Word       string  `json:"word"`
+	Score      float64 `json:"score"`      // Полярность от −1 (отрицательный полюс) до 1 (положительный)
+	Confidence float64 `json:"confidence"` // Близость к затравкам: доля дошедшей до слова вероятности блуждания относительно максимума
+	Stability  float64 `json:"stability"`  // Доля повторов на подвыборках затравок, в которых знак полярности тот же
+	Std        float64 `json:"std"`        // Стандартное отклонение полярности по повторам
+	Seed       bool    `json:"seed,omitempty"`
+}
+
+// Lexicon — словарь полярности, упорядоченный по убыванию Score
+type Lexicon struct {
+	Method    Method   `json:"method"`
+	Positive  []string `json:"positive"` // Затравки, найденные в модели
+	Negative  []string `json:"negative"`
+	Missing   []string `json:"missing,omitempty"` // Затравки, которых нет в модели
+	Bootstrap int      `json:"bootstrap"`
+	Entries   []Entry  `json:"entries"`
+}
+
+// graph — симметричный взвешенный граф соседей
+type graph struct {
+	neighbors [][]int
+	weights   [][]float64
+	degree    []float64
+}
+
+// Induce строит словарь полярности по спискам положительных и отрицательных затравок:
+// полярность распространяется от затравок по графу k ближайших соседей словаря.
+func Induce(model *vectors.Model, positive, negative []string, opts Options) (*Lexicon, error) {
+	def := DefaultOptions()
+	if opts.K <= 0 {
+		opts.K = def.K
+	}
+	if opts.Alpha <= 0 || opts.Alpha >= 1 {
+		opts.Alpha = def.Alpha
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = def.Iterations
+	}
+	if opts.Sample <= 0 || opts.Sample > 1 {
+		opts.Sample = def.Sample
+	}
+	if opts.Method == "" {
+		opts.Method = def.Method
+	}
+
+	lex := &Lexicon{Method: opts.Method, Bootstrap: opts.Bootstrap}
+	var words []string
+	var vecs [][]float64
+	index := make(map[string]int)
+	add := func(word string) {
+		if _, ok := index[word]; ok {
+			return
+		}
+		vec, _ := model.Vector(word)
+		index[word] = len(words)
+		words = append(words, word)
+		vecs = append(vecs, vec)
+	}
+	for i, word := range model.Words {
+		if opts.Limit > 0 && i >= opts.Limit {
+			break
+		}
+		add(word)
+	}
+	seeds := func(list []string, found *[]string) []int {
+		var ids []int
+		seen := make(map[string]bool)
+		for _, word := range list {
+			if seen[word] {
+				continue
+			}
+			seen[word] = true
+			if !model.Has(word) {
+				lex.Missing = append(lex.Missing, word)
+				continue
+			}
+			add(word)
+			*found = append(*found, word)
+			ids = append(ids, index[word])
+		}
+		return ids
+	}
+	pos := seeds(positive, &lex.Positive)
+	neg := seeds(negative, &lex.Negative)
+	if len(pos) == 0 || len(neg) == 0 {
+		return nil, fmt.Errorf("нужны затравки обоих полюсов из модели: положительных %d, отрицательных %d", len(pos), len(neg))
+	}
+	for _, id := range pos {
+		for _, other := range neg {
+			if id == other {
+				return nil, fmt.Errorf("слово %q указано затравкой обоих полюсов", words[id])
+			}
+		}
+	}
+
+	var knn ann.Graph
+	var err error
+	if opts.Exact {
+		knn, err = ann.ExactKNN(vecs, opts.K, 0)
+	} else {
+		var ix *ann.Index
+		if ix, err = ann.Build(vecs, opts.ANN); err != nil {
+			return nil, err
+		}
+		knn, err = ann.KNN(ix, opts.K, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	g := newGraph(knn)
+
+	scores, reach := propagate(g, pos, neg, opts)
+	maxReach := 0.0
+	isSeed := make([]bool, len(words))
+	for _, id := range append(append([]int(nil), pos...), neg...) {
+		isSeed[id] = true
+	}
+	for i, r := range reach {
+		if !isSeed[i] {
+			maxReach = math.Max(maxReach, r)
+		}
+	}
+
+	// Повторы на подвыборках затравок показывают, насколько полярность слова
+	// зависит от выбора конкретных затравок
+	runs := make([][]float64, opts.Bootstrap)
+	parallel.For(len(runs), func(b int) {
+		rng := rand.New(rand.NewSource(opts.Seed + int64(b)))
+		p, n := sample(pos, opts.Sample, rng), sample(neg, opts.Sample, rng)
+		runs[b], _ = propagate(g, p, n, opts)
+	})
+
+	lex.Entries = make([]Entry, len(words))
+	for i, word := range words {
+		e := Entry{Word: word, Score: scores[i], Stability: 1, Seed: isSeed[i]}
+		if e.Seed {
+			e.Confidence = 1
+		} else if maxReach > 0 {
+			e.Confidence = math.Min(reach[i]/maxReach, 1)
+		}
+		if len(runs) > 0 && !e.Seed {
+			var sum, sumSq float64
+			same := 0
+			for _, run := range runs {
+				sum += run[i]
+				sumSq += run[i] * run[i]
+				if (run[i] > 0) == (scores[i] > 0) {
+					same++
+				}
+			}
+			n := float64(len(runs))
+			mean := sum / n
+			e.Std = math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+			e.Stability = float64(same) / n
+			if scores[i] == 0 {
+				e.Stability = 0 // До слова не дошло блуждание ни от одной затравки
+			}
+		}
+		lex.Entries[i] = e
+	}
+	sort.SliceStable(lex.Entries, func(a, b int) bool { return lex.Entries[a].Score > lex.Entries[b].Score })
+	return lex, nil
+}
+
+// newGraph делает граф соседей симметричным. Вес ребра — arccos(−cos):
+// от 0 для противоположных векторов до π для совпадающих, как в SentProp.
+func newGraph(knn ann.Graph) *graph {
+	n := len(knn)
+	edges := make([]map[int]float64, n)
+	for i := range edges {
+		edges[i] = make(map[int]float64)
+	}
+	for i, results := range knn {
+		for _, r := range results {
+			w := math.Acos(math.Max(-1, math.Min(1, -r.Similarity)))
+			edges[i][r.ID] = math.Max(edges[i][r.ID], w)
+			edges[r.ID][i] = math.Max(edges[r.ID][i], w)
+		}
+	}
+	g := &graph{neighbors: make([][]int, n), weights: make([][]float64, n), degree: make([]float64, n)}
+	for i, e := range edges {
+		ids := make([]int, 0, len(e))
+		for j := range e {
+			ids = append(ids, j)
+		}
+		sort.Ints(ids) // Порядок суммирования не зависит от обхода map
+		for _, j := range ids {
+			g.neighbors[i] = append(g.neighbors[i], j)
+			g.weights[i] = append(g.weights[i], e[j])
+			g.degree[i] += e[j]
+		}
+	}
+	return g
+}
+
+// propagate распространяет по графу вероятность от положительных и отрицательных
+// затравок и возвращает полярность (p⁺ − p⁻)/(p⁺ + p⁻) и суммарную дошедшую вероятность
+func propagate(g *graph, pos, neg []int, opts Options) (scores, reach []float64) {
+	p := g.spread(pos, opts)
+	q := g.spread(neg, opts)
+	scores = make([]float64, len(p))
+	reach = make([]float64, len(p))
+	for i := range p {
+		reach[i] = p[i] + q[i]
+		if reach[i] > 0 {
+			scores[i] = (p[i] - q[i]) / reach[i]
+		}
+	}
+	for _, id := range pos {
+		scores[id] = 1
+	}
+	for _, id := range neg {
+		scores[id] = -1
+	}
+	return scores, reach
+}
+
+// spread итеративно вычисляет распределение вероятности, возвращающейся
+// к затравкам seeds (каждой затравке — равная доля), до сходимости
+func (g *graph) spread(seeds []int, opts Options) []float64 {
+	n := len(g.neighbors)
+	restart := make([]float64, n)
+	for _, id := range seeds {
+		restart[id] = 1 / float64(len(seeds))
+	}
+	cur := append([]float64(nil), restart...)
+	next := make([]float64, n)
+	for it := 0; it < opts.Iterations; it++ {
+		for i := range next {
+			next[i] = (1 - opts.Alpha) * restart[i]
+		}
+		for i, neighbors := range g.neighbors {
+			if cur[i] == 0 || g.degree[i] == 0 {
+				continue
+			}
+			for k, j := range neighbors {
+				w := g.weights[i][k]
+				if opts.Method == Propagation {
+					// Симметричная нормировка D^−1/2 W D^−1/2
+					next[j] += opts.Alpha * cur[i] * w / math.Sqrt(g.degree[i]*g.degree[j])
+				} else {
+					// Переход случайного блуждания по строке D^−1 W
+					next[j] += opts.Alpha * cur[i] * w / g.degree[i]
+				}
+			}
+		}
+		var diff float64
+		for i := range next {
+			diff += math.Abs(next[i] - cur[i])
+		}
+		cur, next = next, cur
+		if diff < 1e-9 {
+			break
+		}
+	}
+	return cur
+}
+
+// sample выбирает случайную долю share затравок (хотя бы одну)
+func sample(ids []int, share float64, rng *rand.Rand) []int {
+	n := max(1, int(math.Round(share*float64(len(ids)))))
+	perm := rng.Perm(len(ids))
+	out := make([]int, n)
+	for i := range out {
+		out[i] = ids[perm[i]]
+	}
+	return out
+}
+
+// Filter возвращает слова полюса (positive — положительного) с |Score| не ниже
+// minScore, уверенностью и устойчивостью не ниже порогов, не больше top слов (0 — все).
+// Затравки входят в результат всегда.
+func (l *Lexicon) Filter(positive bool, minScore, minConfidence, minStability float64, top int) []Entry {
+	var out []Entry
+	for i := range l.Entries {
+		e := l.Entries[i]
+		if !positive {
+			e = l.Entries[len(l.Entries)-1-i]
+		}
+		if (e.Score > 0) != positive || e.Score == 0 {
+			continue
+		}
+		if !e.Seed && (math.Abs(e.Score) < minScore || e.Confidence < minConfidence || e.Stability < minStability) {
+			continue
+		}
+		out = append(out, e)
+		if top > 0 && len(out) >= top {
+			break
+		}
+	}
+	return out
+}
+
+// SaveTSV сохраняет словарь: слово, полярность, уверенность, устойчивость, отклонение, признак затравки
+func (l *Lexicon) SaveTSV(filename string, entries []Entry) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	fmt.Fprintln(writer, "word\tscore\tconfidence\tstability\tstd\tseed")
+	for _, e := range entries {
+		seed := 0
+		if e.Seed {
+			seed = 1
+		}
+		fmt.Fprintf(writer, "%s\t%.4f\t%.4f\t%.4f\t%.4f\t%d\n", e.Word, e.Score, e.Confidence, e.Stability, e.Std, seed)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// SaveJSON сохраняет словарь целиком в формате JSON
+func (l *Lexicon) SaveJSON(filename string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи словаря: %v", err)
+	}
+	return nil
+}
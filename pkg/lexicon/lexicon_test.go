@@ -0,0 +1,119 @@
+package lexicon
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// twoPoles возвращает модель из двух групп по n слов: pos0… вокруг одного
+// направления и neg0… вокруг другого
+func twoPoles(n int) *vectors.Model {
+	rng := rand.New(rand.NewSource(1))
+	var words []string
+	var vecs [][]float64
+	for _, pole := range []struct {
+		prefix string
+		center []float64
+	}{{"pos", []float64{1, 0.3, 0}}, {"neg", []float64{0, 0.3, 1}}} {
+		for i := 0; i < n; i++ {
+			vec := make([]float64, len(pole.center))
+			for d, v := range pole.center {
+				vec[d] = v + 0.1*rng.NormFloat64()
+			}
+			words = append(words, fmt.Sprintf("%s%d", pole.prefix, i))
+			vecs = append(vecs, vec)
+		}
+	}
+	return vectors.New(words, vecs)
+}
+
+func TestInduce(t *testing.T) {
+	model := twoPoles(30)
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"sentprop", Options{Method: SentProp, K: 5, Exact: true, Bootstrap: 5, Seed: 1}},
+		{"propagation", Options{Method: Propagation, K: 5, Exact: true, Bootstrap: 5, Seed: 1}},
+		{"hnsw", Options{Method: SentProp, K: 5, Bootstrap: 0, ANN: ann.DefaultConfig()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lex, err := Induce(model, []string{"pos0", "pos1", "нет"}, []string{"neg0", "neg1"}, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lex.Missing) != 1 || lex.Missing[0] != "нет" {
+				t.Errorf("Missing = %v, want [нет]", lex.Missing)
+			}
+			if len(lex.Entries) != model.Len() {
+				t.Fatalf("слов в словаре %d, want %d", len(lex.Entries), model.Len())
+			}
+			for i, e := range lex.Entries {
+				if i > 0 && e.Score > lex.Entries[i-1].Score {
+					t.Fatalf("словарь не упорядочен по полярности на позиции %d", i)
+				}
+				positive := e.Word[:3] == "pos"
+				if (e.Score > 0) != positive {
+					t.Errorf("%s: полярность %.3f", e.Word, e.Score)
+				}
+				if e.Score < -1 || e.Score > 1 || e.Confidence < 0 || e.Confidence > 1 || e.Stability < 0 || e.Stability > 1 {
+					t.Errorf("%s: значения вне диапазона: %+v", e.Word, e)
+				}
+				if e.Seed && math.Abs(e.Score) != 1 {
+					t.Errorf("затравка %s: полярность %.3f, want ±1", e.Word, e.Score)
+				}
+			}
+			if top := lex.Filter(true, 0, 0, 0, 3); len(top) != 3 || top[0].Score <= 0 {
+				t.Errorf("Filter положительного полюса = %v", top)
+			}
+			if bottom := lex.Filter(false, 0, 0, 0, 1); len(bottom) != 1 || bottom[0].Score >= 0 {
+				t.Errorf("Filter отрицательного полюса = %v", bottom)
+			}
+		})
+	}
+}
+
+func TestInduceErrors(t *testing.T) {
+	model := twoPoles(5)
+	tests := []struct {
+		name               string
+		positive, negative []string
+	}{
+		{"нет отрицательных затравок", []string{"pos0"}, []string{"нет"}},
+		{"нет положительных затравок", nil, []string{"neg0"}},
+		{"затравка обоих полюсов", []string{"pos0", "neg0"}, []string{"neg0"}},
+	}
+	for _, tt := range tests {
+		if _, err := Induce(model, tt.positive, tt.negative, Options{Exact: true}); err == nil {
+			t.Errorf("%s: Induce должен вернуть ошибку", tt.name)
+		}
+	}
+}
+
+func TestSpreadConservesProbability(t *testing.T) {
+	model := twoPoles(20)
+	knn, err := ann.ExactKNN(model.Vectors, 4, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newGraph(knn)
+	for _, seeds := range [][]int{{0}, {0, 1, 2}, {0, 25}} {
+		p := g.spread(seeds, Options{Method: SentProp, Alpha: 0.9, Iterations: 1000})
+		var sum float64
+		for _, v := range p {
+			if v < 0 {
+				t.Fatalf("затравки %v: отрицательная вероятность %v", seeds, v)
+			}
+			sum += v
+		}
+		// Блуждание по строкам D⁻¹W не теряет вероятность: каждый узел графа имеет соседей
+		if math.Abs(sum-1) > 1e-6 {
+			t.Errorf("затравки %v: сумма вероятностей %v, want 1", seeds, sum)
+		}
+	}
+}
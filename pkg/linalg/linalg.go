@@ -0,0 +1,233 @@
+package linalg
+
+import (
+	"math"
+	"sort"
+)
+
+// Точность и максимальное число проходов для метода Якоби
+const (
+	jacobiEpsilon   = 1e-12
+	jacobiMaxSweeps = 100
+)
+
+// Zeros создаёт матрицу rows×cols из нулей
+func Zeros(rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
+
+// Identity создаёт единичную матрицу n×n
+func Identity(n int) [][]float64 {
+	m := Zeros(n, n)
+	for i := range m {
+		m[i][i] = 1
+	}
+	return m
+}
+
+// Transpose возвращает транспонированную матрицу
+func Transpose(a [][]float64) [][]float64 {
+	if len(a) == 0 {
+		return nil
+	}
+	t := Zeros(len(a[0]), len(a))
+	for i, row := range a {
+		for j, v := range row {
+			t[j][i] = v
+		}
+	}
+	return t
+}
+
+// Multiply возвращает произведение матриц a (m×k) и b (k×n)
+func Multiply(a, b [][]float64) [][]float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	result := Zeros(len(a), len(b[0]))
+	for i, row := range a {
+		out := result[i]
+		for k, v := range row {
+			if v == 0 {
+				continue
+			}
+			for j, w := range b[k] {
+				out[j] += v * w
+			}
+		}
+	}
+	return result
+}
+
+// MultiplyVector возвращает произведение вектора-строки v (1×k) на матрицу a (k×n)
+func MultiplyVector(v []float64, a [][]float64) []float64 {
+	if len(a) == 0 {
+		return nil
+	}
+	result := make([]float64, len(a[0]))
+	for k, x := range v {
+		for j, w := range a[k] {
+			result[j] += x * w
+		}
+	}
+	return result
+}
+
+// CrossProduct возвращает матрицу xᵀ·y для наборов векторов-строк одинаковой длины
+func CrossProduct(x, y [][]float64) [][]float64 {
+	if len(x) == 0 {
+		return nil
+	}
+	result := Zeros(len(x[0]), len(y[0]))
+	for r := range x {
+		for i, xi := range x[r] {
+			if xi == 0 {
+				continue
+			}
+			out := result[i]
+			for j, yj := range y[r] {
+				out[j] += xi * yj
+			}
+		}
+	}
+	return result
+}
+
+// Dot возвращает скалярное произведение векторов
+func Dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Norm возвращает евклидову длину вектора
+func Norm(v []float64) float64 {
+	return math.Sqrt(Dot(v, v))
+}
+
+// SVD вычисляет сингулярное разложение a = u·diag(s)·vᵀ односторонним методом Якоби.
+// Матрица a имеет размер m×n, m ≥ n; u — m×n, s — n значений по убыванию, v — n×n.
+func SVD(a [][]float64) (u [][]float64, s []float64, v [][]float64) {
+	m := len(a)
+	if m == 0 {
+		return nil, nil, nil
+	}
+	n := len(a[0])
+
+	// Работаем со столбцами: w[j] — j-й столбец a
+	w := Transpose(a)
+	vt := Identity(n) // vt[j] — j-й столбец v
+
+	for sweep := 0; sweep < jacobiMaxSweeps; sweep++ {
+		rotated := false
+		for i := 0; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				alpha := Dot(w[i], w[i])
+				beta := Dot(w[j], w[j])
+				gamma := Dot(w[i], w[j])
+				if math.Abs(gamma) <= jacobiEpsilon*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+
+				zeta := (beta - alpha) / (2 * gamma)
+				t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				c := 1 / math.Sqrt(1+t*t)
+				sn := c * t
+
+				rotate(w[i], w[j], c, sn)
+				rotate(vt[i], vt[j], c, sn)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+
+	// Сингулярные значения — длины столбцов, левые векторы — нормированные столбцы
+	s = make([]float64, n)
+	for j := range w {
+		s[j] = Norm(w[j])
+	}
+
+	// Сортировка по убыванию сингулярных значений
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return s[order[i]] > s[order[j]] })
+
+	sorted := make([]float64, n)
+	uCols := make([][]float64, n)
+	vCols := make([][]float64, n)
+	for k, j := range order {
+		sorted[k] = s[j]
+		vCols[k] = vt[j]
+		col := make([]float64, m)
+		if s[j] > jacobiEpsilon {
+			for i := range col {
+				col[i] = w[j][i] / s[j]
+			}
+		}
+		uCols[k] = col
+	}
+	completeBasis(uCols, sorted)
+
+	return Transpose(uCols), sorted, Transpose(vCols)
+}
+
+// rotate применяет вращение Якоби к паре векторов
+func rotate(x, y []float64, c, s float64) {
+	for k := range x {
+		xk, yk := x[k], y[k]
+		x[k] = c*xk - s*yk
+		y[k] = s*xk + c*yk
+	}
+}
+
+// completeBasis достраивает нулевые левые сингулярные векторы (для вырожденной матрицы)
+// до ортонормированного набора методом Грама — Шмидта
+func completeBasis(cols [][]float64, s []float64) {
+	m := len(cols[0])
+	next := 0
+	for k := range cols {
+		if s[k] > jacobiEpsilon {
+			continue
+		}
+		for ; next < m; next++ {
+			candidate := make([]float64, m)
+			candidate[next] = 1
+			for j := range cols {
+				if j == k || (s[j] <= jacobiEpsilon && j > k) {
+					continue
+				}
+				proj := Dot(candidate, cols[j])
+				for i := range candidate {
+					candidate[i] -= proj * cols[j][i]
+				}
+			}
+			if norm := Norm(candidate); norm > 1e-6 {
+				for i := range candidate {
+					candidate[i] /= norm
+				}
+				cols[k] = candidate
+				next++
+				break
+			}
+		}
+	}
+}
+
+// OrthogonalProcrustes находит ортогональную матрицу w, минимизирующую ‖x·w − y‖,
+// где x и y — наборы векторов-строк одинаковой размерности (строки соответствуют друг другу)
+func OrthogonalProcrustes(x, y [][]float64) [][]float64 {
+	// m = xᵀ·y = u·Σ·vᵀ, тогда w = u·vᵀ
+	u, _, v := SVD(CrossProduct(x, y))
+	return Multiply(u, Transpose(v))
+}
@@ -0,0 +1,116 @@
+package linalg
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomMatrix возвращает матрицу rows×cols со случайными значениями из N(0, 1)
+func randomMatrix(rng *rand.Rand, rows, cols int) [][]float64 {
+	m := Zeros(rows, cols)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = rng.NormFloat64()
+		}
+	}
+	return m
+}
+
+// randomRotation возвращает случайную ортогональную матрицу n×n (Грам — Шмидт по строкам)
+func randomRotation(rng *rand.Rand, n int) [][]float64 {
+	q := randomMatrix(rng, n, n)
+	for i := range q {
+		for j := 0; j < i; j++ {
+			proj := Dot(q[i], q[j])
+			for k := range q[i] {
+				q[i][k] -= proj * q[j][k]
+			}
+		}
+		norm := Norm(q[i])
+		for k := range q[i] {
+			q[i][k] /= norm
+		}
+	}
+	return q
+}
+
+func maxDiff(a, b [][]float64) float64 {
+	var diff float64
+	for i := range a {
+		for j := range a[i] {
+			diff = math.Max(diff, math.Abs(a[i][j]-b[i][j]))
+		}
+	}
+	return diff
+}
+
+func TestMultiplyTranspose(t *testing.T) {
+	a := [][]float64{{1, 2, 3}, {4, 5, 6}}
+	b := [][]float64{{1, 0}, {0, 1}, {1, 1}}
+	want := [][]float64{{4, 5}, {10, 11}}
+	if d := maxDiff(Multiply(a, b), want); d != 0 {
+		t.Errorf("Multiply = %v, ожидалось %v", Multiply(a, b), want)
+	}
+	if d := maxDiff(Transpose(Transpose(a)), a); d != 0 {
+		t.Errorf("двойное транспонирование изменило матрицу")
+	}
+	if got := MultiplyVector([]float64{1, 2, 3}, b); got[0] != 4 || got[1] != 5 {
+		t.Errorf("MultiplyVector = %v", got)
+	}
+	if d := maxDiff(CrossProduct(a, a), Multiply(Transpose(a), a)); d > 1e-12 {
+		t.Errorf("CrossProduct отличается от aᵀ·a на %g", d)
+	}
+}
+
+func TestSVD(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a := randomMatrix(rng, 12, 5)
+	u, s, v := SVD(a)
+	for i := 1; i < len(s); i++ {
+		if s[i] > s[i-1] {
+			t.Fatalf("сингулярные значения не по убыванию: %v", s)
+		}
+	}
+	// a = u·diag(s)·vᵀ
+	us := Zeros(len(u), len(s))
+	for i := range u {
+		for j := range s {
+			us[i][j] = u[i][j] * s[j]
+		}
+	}
+	if d := maxDiff(Multiply(us, Transpose(v)), a); d > 1e-9 {
+		t.Errorf("разложение восстанавливает матрицу с ошибкой %g", d)
+	}
+	if d := maxDiff(CrossProduct(v, v), Identity(len(s))); d > 1e-9 {
+		t.Errorf("v не ортогональна: ошибка %g", d)
+	}
+}
+
+func TestSVDRankDeficient(t *testing.T) {
+	// Третий столбец — сумма первых двух
+	a := [][]float64{{1, 0, 1}, {0, 1, 1}, {1, 1, 2}, {2, 0, 2}}
+	u, s, _ := SVD(a)
+	if s[2] > 1e-9 {
+		t.Errorf("наименьшее сингулярное значение %g, ожидался 0", s[2])
+	}
+	if d := maxDiff(CrossProduct(u, u), Identity(3)); d > 1e-9 {
+		t.Errorf("столбцы u не ортонормированы: ошибка %g", d)
+	}
+}
+
+func TestOrthogonalProcrustes(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	const dim = 6
+	x := randomMatrix(rng, 40, dim)
+	rotation := randomRotation(rng, dim)
+	y := Multiply(x, rotation)
+
+	w := OrthogonalProcrustes(x, y)
+	if d := maxDiff(w, rotation); d > 1e-9 {
+		t.Errorf("найденное преобразование отличается от поворота на %g", d)
+	}
+	if d := maxDiff(CrossProduct(w, w), Identity(dim)); d > 1e-9 {
+		t.Errorf("преобразование не ортогонально: ошибка %g", d)
+	}
+}
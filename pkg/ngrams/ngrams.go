@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -42,7 +43,7 @@ func ExtractNGrams(cooccurrenceFile string, vocabFile string, stopwordsFile stri
 	// Загрузка стоп-слов (если нужно)
 	var stopwords map[string]struct{}
 	if useStopwords {
-		stopwords, err = loadStopwords(stopwordsFile)
+		stopwords, err = LoadStopwords(stopwordsFile)
 		if err != nil {
 			return nil, nil, fmt.Errorf("ошибка при загрузке стоп-слов: %v", err)
 		}
@@ -173,8 +174,8 @@ func loadVocab(vocabFile string) (map[int32]string, error) {
 	return vocab, nil
 }
 
-// loadStopwords загружает стоп-слова из файла
-func loadStopwords(stopwordsFile string) (map[string]struct{}, error) {
+// LoadStopwords загружает стоп-слова из файла
+func LoadStopwords(stopwordsFile string) (map[string]struct{}, error) {
 	file, err := os.Open(stopwordsFile)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при открытии файла стоп-слов: %v", err)
@@ -214,3 +215,33 @@ func SaveNGrams(ngrams []Pair, outputFile string) error {
 
 	return nil
 }
+
+// LoadNGrams загружает n-граммы из файла, сохранённого SaveNGrams (строки вида "[слово слово]: частота")
+func LoadNGrams(inputFile string) ([]Pair, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла n-грамм: %v", err)
+	}
+	defer file.Close()
+
+	var pairs []Pair
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		sep := strings.LastIndex(line, ":")
+		if !strings.HasPrefix(line, "[") || sep < 0 {
+			continue
+		}
+		words := strings.Fields(strings.Trim(line[:sep], "[]"))
+		freq, err := strconv.ParseFloat(strings.TrimSpace(line[sep+1:]), 64)
+		if err != nil || len(words) == 0 {
+			continue
+		}
+		pairs = append(pairs, Pair{Words: words, Frequency: freq})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла n-грамм: %v", err)
+	}
+	return pairs, nil
+}
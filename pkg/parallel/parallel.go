@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// For вызывает fn для 0..n-1 на всех ядрах процессора и ждёт завершения.
+// Вызовы для разных i могут выполняться одновременно, поэтому fn должна
+// записывать результат только в свою ячейку.
+func For(n int, fn func(i int)) {
+	workers := min(runtime.NumCPU(), n)
+	if workers <= 0 {
+		return
+	}
+	jobs := make(chan int, n)
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
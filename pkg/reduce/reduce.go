@@ -0,0 +1,175 @@
+package reduce
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/parallel"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"math/rand"
+)
+
+// PCA — главные компоненты векторов слов
+type PCA struct {
+	Mean       []float64
+	Components [][]float64 // Главные направления по убыванию дисперсии (векторы единичной длины)
+	Variance   []float64   // Дисперсия вдоль каждого направления
+}
+
+// FitPCA находит главные компоненты по первым limit (самым частым) словам модели
+// (limit ≤ 0 — по всем): собственные векторы ковариационной матрицы размера d×d
+func FitPCA(model *vectors.Model, limit int) (*PCA, error) {
+	n, dim := model.Len(), model.Dim()
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	if n < 2 || dim == 0 {
+		return nil, fmt.Errorf("недостаточно векторов для PCA: %d", n)
+	}
+
+	mean := make([]float64, dim)
+	for _, vec := range model.Vectors[:n] {
+		for j, v := range vec {
+			mean[j] += v / float64(n)
+		}
+	}
+	cov := linalg.Zeros(dim, dim)
+	centered := make([]float64, dim)
+	for _, vec := range model.Vectors[:n] {
+		for j, v := range vec {
+			centered[j] = v - mean[j]
+		}
+		for a := 0; a < dim; a++ {
+			row, ca := cov[a], centered[a]
+			for b := a; b < dim; b++ {
+				row[b] += ca * centered[b]
+			}
+		}
+	}
+	for a := 0; a < dim; a++ {
+		for b := a; b < dim; b++ {
+			cov[a][b] /= float64(n - 1)
+			cov[b][a] = cov[a][b]
+		}
+	}
+
+	// Для симметричной неотрицательно определённой матрицы сингулярное разложение
+	// совпадает со спектральным: столбцы v — собственные векторы, s — собственные значения
+	_, s, v := linalg.SVD(cov)
+	return &PCA{Mean: mean, Components: linalg.Transpose(v), Variance: s}, nil
+}
+
+// Explained возвращает долю дисперсии, приходящуюся на первые k компонент
+func (p *PCA) Explained(k int) float64 {
+	var total, top float64
+	for i, v := range p.Variance {
+		total += v
+		if i < k {
+			top += v
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return top / total
+}
+
+// Transform проецирует векторы модели на первые k главных компонент. Векторы не
+// центрируются, поэтому при k, равном размерности, косинусные сходства не меняются;
+// среднее вычитает AllButTheTop.
+func (p *PCA) Transform(model *vectors.Model, k int) *vectors.Model {
+	k = min(k, len(p.Components))
+	return mapVectors(model, func(vec []float64) []float64 {
+		out := make([]float64, k)
+		for c := 0; c < k; c++ {
+			out[c] = linalg.Dot(vec, p.Components[c])
+		}
+		return out
+	})
+}
+
+// RemoveTop вычитает из векторов среднее и их проекции на первые d главных
+// направлений, сохраняя размерность
+func (p *PCA) RemoveTop(model *vectors.Model, d int) *vectors.Model {
+	d = min(d, len(p.Components))
+	return mapVectors(model, func(vec []float64) []float64 {
+		out := make([]float64, len(vec))
+		for j, v := range vec {
+			out[j] = v - p.Mean[j]
+		}
+		for c := 0; c < d; c++ {
+			proj := linalg.Dot(out, p.Components[c])
+			for j, u := range p.Components[c] {
+				out[j] -= proj * u
+			}
+		}
+		return out
+	})
+}
+
+// AllButTheTop — постобработка по Mu, Viswanath: у векторов GloVe есть общее
+// смещение и несколько доминирующих направлений, связанных в основном с частотой
+// слов; их удаление улучшает оценки сходства. Главные направления ищутся по
+// первым limit словам.
+func AllButTheTop(model *vectors.Model, d, limit int) (*vectors.Model, error) {
+	pca, err := FitPCA(model, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pca.RemoveTop(model, d), nil
+}
+
+// ReducePCA понижает размерность до k методом главных компонент. При abtt > 0
+// доминирующие направления удаляются до и после проекции (PPA-PCA-PPA по Raunak).
+// Возвращается и найденное разложение — по нему видна доля сохранённой дисперсии.
+func ReducePCA(model *vectors.Model, k, abtt, limit int) (*vectors.Model, *PCA, error) {
+	var err error
+	if abtt > 0 {
+		if model, err = AllButTheTop(model, abtt, limit); err != nil {
+			return nil, nil, err
+		}
+	}
+	pca, err := FitPCA(model, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	reduced := pca.Transform(model, k)
+	if abtt > 0 {
+		if reduced, err = AllButTheTop(reduced, abtt, limit); err != nil {
+			return nil, nil, err
+		}
+	}
+	return reduced, pca, nil
+}
+
+// RandomProjection понижает размерность до k умножением на разреженную случайную
+// матрицу Achlioptas: элементы √(3/k)·{+1, 0, −1} с вероятностями 1/6, 2/3, 1/6.
+// Попарные расстояния сохраняются приближённо (лемма Джонсона — Линденштраусса);
+// обучение не нужно.
+func RandomProjection(model *vectors.Model, k int, seed int64) *vectors.Model {
+	rng := rand.New(rand.NewSource(seed))
+	scale := math.Sqrt(3 / float64(k))
+	matrix := linalg.Zeros(model.Dim(), k)
+	for _, row := range matrix {
+		for c := range row {
+			switch r := rng.Intn(6); {
+			case r == 0:
+				row[c] = scale
+			case r == 1:
+				row[c] = -scale
+			}
+		}
+	}
+	return mapVectors(model, func(vec []float64) []float64 {
+		return linalg.MultiplyVector(vec, matrix)
+	})
+}
+
+// mapVectors строит новую модель с теми же словами, преобразуя векторы параллельно
+func mapVectors(model *vectors.Model, fn func([]float64) []float64) *vectors.Model {
+	vecs := make([][]float64, model.Len())
+	parallel.For(len(vecs), func(i int) {
+		vecs[i] = fn(model.Vectors[i])
+	})
+	return vectors.New(model.Words, vecs)
+}
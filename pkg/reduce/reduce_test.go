@@ -0,0 +1,163 @@
+package reduce
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomModel возвращает n случайных векторов с разной дисперсией по осям
+// (ось j масштабируется в scale[j] раз) и общим смещением shift
+func randomModel(n int, scale []float64, shift float64, seed int64) *vectors.Model {
+	rng := rand.New(rand.NewSource(seed))
+	words := make([]string, n)
+	vecs := make([][]float64, n)
+	for i := range vecs {
+		words[i] = fmt.Sprintf("w%d", i)
+		vecs[i] = make([]float64, len(scale))
+		for j, s := range scale {
+			vecs[i][j] = s*rng.NormFloat64() + shift
+		}
+	}
+	return vectors.New(words, vecs)
+}
+
+func TestFitPCA(t *testing.T) {
+	model := randomModel(2000, []float64{1, 5, 0.5, 3}, 2, 1)
+	pca, err := FitPCA(model, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Главные направления — оси в порядке убывания масштаба
+	axes := []int{1, 3, 0, 2}
+	for c, comp := range pca.Components {
+		if math.Abs(linalg.Norm(comp)-1) > 1e-9 {
+			t.Errorf("компонента %d не единичной длины", c)
+		}
+		for other := c + 1; other < len(pca.Components); other++ {
+			if d := linalg.Dot(comp, pca.Components[other]); math.Abs(d) > 1e-9 {
+				t.Errorf("компоненты %d и %d не ортогональны: %v", c, other, d)
+			}
+		}
+		if math.Abs(comp[axes[c]]) < 0.99 {
+			t.Errorf("компонента %d = %v, want ось %d", c, comp, axes[c])
+		}
+		if c > 0 && pca.Variance[c] > pca.Variance[c-1] {
+			t.Errorf("дисперсии не упорядочены: %v", pca.Variance)
+		}
+	}
+	if math.Abs(pca.Variance[0]-25) > 2.5 {
+		t.Errorf("наибольшая дисперсия %v, want около 25", pca.Variance[0])
+	}
+	if e := pca.Explained(len(pca.Variance)); math.Abs(e-1) > 1e-12 {
+		t.Errorf("Explained по всем компонентам = %v, want 1", e)
+	}
+	for j, m := range pca.Mean {
+		if math.Abs(m-2) > 0.3 {
+			t.Errorf("среднее по оси %d = %v, want около 2", j, m)
+		}
+	}
+
+	if _, err := FitPCA(randomModel(1, []float64{1}, 0, 1), 0); err == nil {
+		t.Error("FitPCA по одному вектору должен вернуть ошибку")
+	}
+}
+
+func TestTransformPreservesSimilarities(t *testing.T) {
+	model := randomModel(200, []float64{1, 2, 3, 4, 5, 6}, 0.5, 2)
+	tests := []struct {
+		name string
+		k    int
+		tol  float64
+	}{
+		{"полная размерность без потерь", 6, 1e-9},
+		{"больше размерности", 10, 1e-9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reduced, pca, err := ReducePCA(model, tt.k, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reduced.Dim() != min(tt.k, model.Dim()) || pca.Explained(tt.k) < 1-1e-12 {
+				t.Fatalf("размерность %d, доля дисперсии %v", reduced.Dim(), pca.Explained(tt.k))
+			}
+			for i := 0; i < 20; i++ {
+				for j := i + 1; j < 20; j++ {
+					want := vectors.CosineSimilarity(model.Vectors[i], model.Vectors[j])
+					got := vectors.CosineSimilarity(reduced.Vectors[i], reduced.Vectors[j])
+					if math.Abs(got-want) > tt.tol {
+						t.Fatalf("сходство %d и %d: %v, want %v", i, j, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAllButTheTop(t *testing.T) {
+	model := randomModel(500, []float64{10, 1, 1, 1, 1}, 3, 3)
+	tests := []int{1, 2}
+	for _, d := range tests {
+		pca, err := FitPCA(model, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		processed, err := AllButTheTop(model, d, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mean := vectors.Mean(processed.Vectors)
+		if n := linalg.Norm(mean); n > 1e-9 {
+			t.Errorf("d = %d: среднее после обработки %v, want 0", d, mean)
+		}
+		for _, vec := range processed.Vectors {
+			for c := 0; c < d; c++ {
+				if p := linalg.Dot(vec, pca.Components[c]); math.Abs(p) > 1e-9 {
+					t.Fatalf("d = %d: проекция на компоненту %d = %v, want 0", d, c, p)
+				}
+			}
+		}
+	}
+}
+
+func TestRandomProjection(t *testing.T) {
+	scale := make([]float64, 300)
+	for j := range scale {
+		scale[j] = 1
+	}
+	model := randomModel(50, scale, 0, 4)
+	projected := RandomProjection(model, 150, 1)
+	if projected.Dim() != 150 || projected.Len() != model.Len() {
+		t.Fatalf("размер %d×%d, want %d×150", projected.Len(), projected.Dim(), model.Len())
+	}
+	// Квадраты расстояний сохраняются в среднем; отдельные пары — с погрешностью
+	var ratios float64
+	pairs := 0
+	for i := 0; i < model.Len(); i++ {
+		for j := i + 1; j < model.Len(); j++ {
+			want := squaredDistance(model.Vectors[i], model.Vectors[j])
+			got := squaredDistance(projected.Vectors[i], projected.Vectors[j])
+			if r := got / want; r < 0.5 || r > 1.5 {
+				t.Errorf("пара %d, %d: отношение квадратов расстояний %v", i, j, r)
+			}
+			ratios += got / want
+			pairs++
+		}
+	}
+	if mean := ratios / float64(pairs); math.Abs(mean-1) > 0.1 {
+		t.Errorf("среднее отношение квадратов расстояний %v, want около 1", mean)
+	}
+}
+
+func squaredDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return sum
+}
@@ -0,0 +1,217 @@
+package semshift
+
+import (
+	"encoding/json"
+	"fmt"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Options задаёт параметры поиска семантических сдвигов
+type Options struct {
+	MinCount  int      // Минимальная частота слова в каждом срезе
+	TopN      int      // Число слов с наибольшим сдвигом в отчёте
+	Neighbors int      // Число ближайших соседей слова в каждом периоде
+	Words     []string // Если задано, отчёт строится только по этим словам
+}
+
+// PeriodNeighbors — ближайшие соседи слова в одном периоде
+type PeriodNeighbors struct {
+	Period    string             `json:"period"`
+	Neighbors []vectors.Neighbor `json:"neighbors"`
+}
+
+// WordShift описывает сдвиг значения слова между периодами
+type WordShift struct {
+	Word    string            `json:"word"`
+	Drift   float64           `json:"drift"` // Косинусное расстояние между первым и последним периодом
+	Steps   []float64         `json:"steps"` // Расстояния между соседними периодами
+	Periods []PeriodNeighbors `json:"periods"`
+}
+
+// Report — результат поиска семантических сдвигов
+type Report struct {
+	Periods     []string    `json:"periods"`
+	SharedVocab int         `json:"shared_vocab"`
+	Words       []WordShift `json:"words"`
+}
+
+// Detect выравнивает модели срезов ортогональным преобразованием Прокруста
+// по общему словарю и ранжирует слова по величине сдвига значения
+func Detect(slices []Slice, opts Options) (*Report, error) {
+	if len(slices) < 2 {
+		return nil, fmt.Errorf("для поиска сдвигов нужно как минимум два среза, найдено %d", len(slices))
+	}
+
+	// Загрузка моделей и словарей срезов
+	models := make([]*vectors.Model, len(slices))
+	counts := make([]map[string]int, len(slices))
+	for i, s := range slices {
+		cfg := s.Config()
+		model, err := vectors.Load(cfg.VectorsTextFile())
+		if err != nil {
+			return nil, fmt.Errorf("срез %s: %v", s.Label, err)
+		}
+		models[i] = model.Normalized()
+		if opts.MinCount > 0 {
+			counts[i], err = glove.VocabCounts(cfg.VocabFile)
+			if err != nil {
+				return nil, fmt.Errorf("срез %s: %v", s.Label, err)
+			}
+		}
+	}
+
+	shared := sharedVocabulary(models, counts, opts.MinCount)
+	if len(shared) < models[0].Dim() {
+		log.Printf("Общий словарь (%d слов) меньше размерности векторов, выравнивание может быть неточным", len(shared))
+	}
+	if len(shared) == 0 {
+		return nil, fmt.Errorf("у срезов нет общих слов")
+	}
+
+	// Выравнивание всех срезов по последнему с центрированием по общему словарю
+	aligned := make([][][]float64, len(models))
+	last := len(models) - 1
+	aligned[last] = centered(models[last], shared)
+	for i := 0; i < last; i++ {
+		model, _, err := vectors.Align(models[last], models[i], vectors.AlignOptions{Anchors: shared, Center: true})
+		if err != nil {
+			return nil, fmt.Errorf("срез %s: %v", slices[i].Label, err)
+		}
+		aligned[i] = make([][]float64, len(shared))
+		for j, word := range shared {
+			aligned[i][j], _ = model.Vector(word)
+		}
+	}
+
+	// Оценка сдвига для каждого слова общего словаря
+	var shifts []WordShift
+	for j, word := range shared {
+		shift := WordShift{Word: word, Steps: make([]float64, last)}
+		for i := 0; i < last; i++ {
+			shift.Steps[i] = 1 - vectors.CosineSimilarity(aligned[i][j], aligned[i+1][j])
+		}
+		shift.Drift = 1 - vectors.CosineSimilarity(aligned[0][j], aligned[last][j])
+		shifts = append(shifts, shift)
+	}
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].Drift > shifts[j].Drift })
+
+	shifts = selectWords(shifts, opts)
+
+	// Соседи слова в собственном пространстве каждого периода
+	report := &Report{SharedVocab: len(shared), Words: shifts}
+	for _, s := range slices {
+		report.Periods = append(report.Periods, s.Label)
+	}
+	if opts.Neighbors > 0 {
+		for k := range report.Words {
+			word := report.Words[k].Word
+			for i, model := range models {
+				neighbors, _ := model.NearestWords(word, opts.Neighbors)
+				report.Words[k].Periods = append(report.Words[k].Periods, PeriodNeighbors{
+					Period:    slices[i].Label,
+					Neighbors: neighbors,
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// sharedVocabulary возвращает слова, присутствующие во всех моделях
+// (и встречающиеся не реже minCount раз в каждом срезе, если частоты известны)
+func sharedVocabulary(models []*vectors.Model, counts []map[string]int, minCount int) []string {
+	var shared []string
+	for _, word := range models[0].Words {
+		ok := true
+		for i, model := range models {
+			if !model.Has(word) || (counts[i] != nil && counts[i][word] < minCount) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			shared = append(shared, word)
+		}
+	}
+	return shared
+}
+
+// centered возвращает векторы слов общего словаря, центрированные и приведённые к единичной длине
+func centered(model *vectors.Model, words []string) [][]float64 {
+	rows := make([][]float64, len(words))
+	for i, word := range words {
+		rows[i], _ = model.Vector(word)
+	}
+	mean := vectors.Mean(rows)
+	result := make([][]float64, len(rows))
+	for i, row := range rows {
+		centeredRow := make([]float64, len(row))
+		for k := range row {
+			centeredRow[k] = row[k] - mean[k]
+		}
+		result[i] = vectors.Normalize(centeredRow)
+	}
+	return result
+}
+
+// selectWords оставляет в отчёте заданные слова или topN слов с наибольшим сдвигом
+func selectWords(shifts []WordShift, opts Options) []WordShift {
+	if len(opts.Words) > 0 {
+		byWord := make(map[string]WordShift, len(shifts))
+		for _, s := range shifts {
+			byWord[s.Word] = s
+		}
+		var selected []WordShift
+		for _, word := range opts.Words {
+			if s, ok := byWord[word]; ok {
+				selected = append(selected, s)
+			} else {
+				log.Printf("Слово '%s' отсутствует в общем словаре срезов", word)
+			}
+		}
+		sort.Slice(selected, func(i, j int) bool { return selected[i].Drift > selected[j].Drift })
+		return selected
+	}
+	if opts.TopN > 0 && opts.TopN < len(shifts) {
+		return shifts[:opts.TopN]
+	}
+	return shifts
+}
+
+// SaveJSON сохраняет отчёт в формате JSON
+func (r *Report) SaveJSON(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// Print выводит отчёт в читаемом виде
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Периоды: %s (общий словарь: %d слов)\n", strings.Join(r.Periods, ", "), r.SharedVocab)
+	for i, s := range r.Words {
+		fmt.Fprintf(w, "%d. %s: сдвиг %.4f\n", i+1, s.Word, s.Drift)
+		for _, p := range s.Periods {
+			words := make([]string, len(p.Neighbors))
+			for k, n := range p.Neighbors {
+				words[k] = n.Word
+			}
+			fmt.Fprintf(w, "   %s: %s\n", p.Period, strings.Join(words, ", "))
+		}
+	}
+}
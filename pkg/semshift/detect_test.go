@@ -0,0 +1,95 @@
+package semshift
+
+import (
+	"glove-pipeline/pkg/vectors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeSlice сохраняет модель в каталог среза так, как её сохраняет обучение GloVe
+func writeSlice(t *testing.T, dir, label string, model *vectors.Model) Slice {
+	t.Helper()
+	s := Slice{Label: label, Dir: filepath.Join(dir, label)}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.Save(s.Config().VectorsTextFile()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDetect(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n, dim = 40, 6
+	words := make([]string, n)
+	first := make([][]float64, n)
+	for i := range words {
+		words[i] = "w" + strconv.Itoa(i)
+		first[i] = make([]float64, dim)
+		for k := range first[i] {
+			first[i][k] = rng.NormFloat64()
+		}
+	}
+	words[7] = "сдвиг"
+
+	// Второй период — тот же словарь в повёрнутом пространстве (оси переставлены
+	// со сменой знака), но слово «сдвиг» получило значение другого слова
+	second := make([][]float64, n)
+	for i, vec := range first {
+		src := vec
+		if i == 7 {
+			src = first[20]
+		}
+		second[i] = make([]float64, dim)
+		for k := range src {
+			second[i][(k+1)%dim] = -src[k]
+		}
+	}
+
+	dir := t.TempDir()
+	slices := []Slice{
+		writeSlice(t, dir, "2023", vectors.New(words, first)),
+		writeSlice(t, dir, "2024", vectors.New(words, second)),
+	}
+	report, err := Detect(slices, Options{TopN: 3, Neighbors: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SharedVocab != n || len(report.Words) != 3 {
+		t.Fatalf("общий словарь %d, слов в отчёте %d", report.SharedVocab, len(report.Words))
+	}
+	top := report.Words[0]
+	if top.Word != "сдвиг" {
+		t.Errorf("наибольший сдвиг у слова %s (%.3f), ожидалось «сдвиг»", top.Word, top.Drift)
+	}
+	if top.Drift < 10*report.Words[1].Drift {
+		t.Errorf("сдвиг слова %.3f не выделяется на фоне %.3f", top.Drift, report.Words[1].Drift)
+	}
+	if len(top.Steps) != 1 || len(top.Periods) != 2 || top.Periods[1].Neighbors[0].Word != "w20" {
+		t.Errorf("соседи по периодам %+v", top.Periods)
+	}
+
+	report, err = Detect(slices, Options{Words: []string{"w3", "нет"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Words) != 1 || report.Words[0].Word != "w3" {
+		t.Errorf("отчёт по заданным словам %+v", report.Words)
+	}
+}
+
+func TestDetectErrors(t *testing.T) {
+	dir := t.TempDir()
+	a := writeSlice(t, dir, "a", vectors.New([]string{"x"}, [][]float64{{1, 0}}))
+	b := writeSlice(t, dir, "b", vectors.New([]string{"y"}, [][]float64{{0, 1}}))
+	if _, err := Detect([]Slice{a}, Options{}); err == nil {
+		t.Error("ожидалась ошибка для одного среза")
+	}
+	if _, err := Detect([]Slice{a, b}, Options{}); err == nil {
+		t.Error("ожидалась ошибка для срезов без общих слов")
+	}
+}
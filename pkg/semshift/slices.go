@@ -0,0 +1,256 @@
+package semshift
+
+import (
+	"bufio"
+	"fmt"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/glove"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period задаёт длину временного среза: единицу (day, week, month, quarter, year) и их количество
+type Period struct {
+	Unit  string
+	Count int
+}
+
+// Понедельник, от которого отсчитываются недели
+var weekEpoch = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// ParsePeriod разбирает период вида "month", "3month", "quarter", "week", "2year"
+func ParsePeriod(value string) (Period, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	i := 0
+	for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+		i++
+	}
+	p := Period{Unit: value[i:], Count: 1}
+	if i > 0 {
+		count, err := strconv.Atoi(value[:i])
+		if err != nil || count <= 0 {
+			return Period{}, fmt.Errorf("некорректный период: %q", value)
+		}
+		p.Count = count
+	}
+	switch p.Unit {
+	case "day", "week", "month", "year":
+	case "quarter":
+		p.Unit = "month"
+		p.Count *= 3
+	default:
+		return Period{}, fmt.Errorf("неизвестная единица периода: %q (day, week, month, quarter, year)", p.Unit)
+	}
+	return p, nil
+}
+
+// Start возвращает начало среза, в который попадает момент t
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	switch p.Unit {
+	case "day":
+		days := int(t.Sub(time.Unix(0, 0).UTC()).Hours()) / 24
+		days -= days % p.Count
+		return time.Unix(0, 0).UTC().AddDate(0, 0, days)
+	case "week":
+		weeks := int(t.Sub(weekEpoch).Hours()) / (24 * 7)
+		if t.Before(weekEpoch) {
+			weeks--
+		}
+		weeks -= weeks % p.Count
+		return weekEpoch.AddDate(0, 0, weeks*7)
+	case "year":
+		year := t.Year() - t.Year()%p.Count
+		return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		months := t.Year()*12 + int(t.Month()) - 1
+		months -= months % p.Count
+		return time.Date(months/12, time.Month(months%12+1), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// End возвращает начало следующего среза
+func (p Period) End(start time.Time) time.Time {
+	switch p.Unit {
+	case "day":
+		return start.AddDate(0, 0, p.Count)
+	case "week":
+		return start.AddDate(0, 0, 7*p.Count)
+	case "year":
+		return start.AddDate(p.Count, 0, 0)
+	default:
+		return start.AddDate(0, p.Count, 0)
+	}
+}
+
+// Label возвращает имя среза; имена срезов сортируются в хронологическом порядке
+func (p Period) Label(start time.Time) string {
+	switch p.Unit {
+	case "day", "week":
+		return start.Format("2006-01-02")
+	case "year":
+		return start.Format("2006")
+	default:
+		if p.Count == 3 {
+			return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+		}
+		return start.Format("2006-01")
+	}
+}
+
+// Name возвращает имя длины периода («month», «3month»); под этим именем
+// хранятся срезы периода, чтобы срезы разной длины не смешивались
+func (p Period) Name() string {
+	if p.Count == 1 {
+		return p.Unit
+	}
+	return fmt.Sprintf("%d%s", p.Count, p.Unit)
+}
+
+// Matches сообщает, является ли label именем среза этого периода
+func (p Period) Matches(label string) bool {
+	layouts := map[string]string{"day": "2006-01-02", "week": "2006-01-02", "year": "2006", "month": "2006-01"}
+	var start time.Time
+	var err error
+	if p.Unit == "month" && p.Count == 3 {
+		var year, quarter int
+		if _, err = fmt.Sscanf(label, "%d-Q%d", &year, &quarter); err == nil {
+			start = time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+		}
+	} else {
+		start, err = time.Parse(layouts[p.Unit], label)
+	}
+	return err == nil && p.Label(p.Start(start)) == label
+}
+
+// Slice — временной срез корпуса и каталог с его файлами
+type Slice struct {
+	Label string
+	Dir   string
+	Docs  int
+}
+
+// Config возвращает конфигурацию GloVe для среза
+func (s Slice) Config() glove.Config {
+	return glove.DirConfig(s.Dir)
+}
+
+// Split раскладывает корпус с датами (JSONL) по временным срезам:
+// тексты каждого среза сохраняются в outDir/<срез>/cleaned_corpus.txt.
+// Документы без распознаваемой даты пропускаются.
+func Split(corpusFile, outDir string, period Period) ([]Slice, error) {
+	reader, err := corpus.Open(corpusFile)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	if !reader.IsJSONL() {
+		return nil, fmt.Errorf("для разбиения по периодам нужен корпус в формате JSONL с датами")
+	}
+
+	type sliceWriter struct {
+		file   *os.File
+		writer *bufio.Writer
+		slice  *Slice
+	}
+	writers := make(map[string]*sliceWriter)
+	defer func() {
+		for _, w := range writers {
+			w.file.Close()
+		}
+	}()
+
+	skipped := 0
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := doc.Time()
+		if err != nil || doc.Text == "" {
+			skipped++
+			continue
+		}
+
+		label := period.Label(period.Start(t))
+		w, ok := writers[label]
+		if !ok {
+			dir := filepath.Join(outDir, label)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("ошибка при создании каталога: %v", err)
+			}
+			file, err := os.Create(glove.DirConfig(dir).InputFile)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка при создании файла: %v", err)
+			}
+			w = &sliceWriter{file: file, writer: bufio.NewWriter(file), slice: &Slice{Label: label, Dir: dir}}
+			writers[label] = w
+		}
+		if _, err := w.writer.WriteString(doc.Text + "\n"); err != nil {
+			return nil, fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+		w.slice.Docs++
+	}
+
+	var slices []Slice
+	for _, w := range writers {
+		if err := w.writer.Flush(); err != nil {
+			return nil, fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+		slices = append(slices, *w.slice)
+	}
+	sort.Slice(slices, func(i, j int) bool { return slices[i].Label < slices[j].Label })
+
+	if skipped > 0 {
+		log.Printf("Пропущено документов без даты: %d", skipped)
+	}
+	for _, s := range slices {
+		log.Printf("Срез %s: %d документов", s.Label, s.Docs)
+	}
+	return slices, nil
+}
+
+// LoadSlices находит ранее созданные срезы периода period в каталоге dir
+// (подкаталоги с cleaned_corpus.txt, имена которых — имена срезов этого периода)
+func LoadSlices(dir string, period Period) ([]Slice, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении каталога срезов: %v", err)
+	}
+	var slices []Slice
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if !period.Matches(entry.Name()) {
+			continue
+		}
+		sliceDir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(glove.DirConfig(sliceDir).InputFile); err != nil {
+			continue
+		}
+		slices = append(slices, Slice{Label: entry.Name(), Dir: sliceDir})
+	}
+	sort.Slice(slices, func(i, j int) bool { return slices[i].Label < slices[j].Label })
+	return slices, nil
+}
+
+// Train обучает отдельную модель GloVe для каждого среза
+func Train(slices []Slice) error {
+	for _, s := range slices {
+		log.Printf("Обучение GloVe для среза %s...", s.Label)
+		if err := glove.RunWithConfig(s.Config()); err != nil {
+			return fmt.Errorf("срез %s: %v", s.Label, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package semshift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Period
+	}{
+		{"month", Period{Unit: "month", Count: 1}},
+		{"3Month", Period{Unit: "month", Count: 3}},
+		{"quarter", Period{Unit: "month", Count: 3}},
+		{"2quarter", Period{Unit: "month", Count: 6}},
+		{" week ", Period{Unit: "week", Count: 1}},
+		{"2year", Period{Unit: "year", Count: 2}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePeriod(tt.value)
+		if err != nil {
+			t.Errorf("ParsePeriod(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePeriod(%q) = %+v, ожидалось %+v", tt.value, got, tt.want)
+		}
+	}
+	for _, value := range []string{"", "0month", "fortnight", "3"} {
+		if _, err := ParsePeriod(value); err == nil {
+			t.Errorf("ParsePeriod(%q): ожидалась ошибка", value)
+		}
+	}
+}
+
+func TestPeriodLabel(t *testing.T) {
+	moment := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC) // Среда
+	tests := []struct {
+		period string
+		label  string
+		end    string
+	}{
+		{"day", "2024-08-14", "2024-08-15"},
+		{"week", "2024-08-12", "2024-08-19"},
+		{"month", "2024-08", "2024-09"},
+		{"quarter", "2024-Q3", "2024-Q4"},
+		{"6month", "2024-07", "2025-01"},
+		{"year", "2024", "2025"},
+		{"2year", "2024", "2026"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeriod(tt.period)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := p.Start(moment)
+		if got := p.Label(start); got != tt.label {
+			t.Errorf("%s: срез %s, ожидался %s", tt.period, got, tt.label)
+		}
+		if start.After(moment) || !p.End(start).After(moment) {
+			t.Errorf("%s: момент %v вне среза [%v, %v)", tt.period, moment, start, p.End(start))
+		}
+		if got := p.Label(p.End(start)); got != tt.end {
+			t.Errorf("%s: следующий срез %s, ожидался %s", tt.period, got, tt.end)
+		}
+	}
+}
+
+func TestPeriodMatches(t *testing.T) {
+	month, _ := ParsePeriod("month")
+	quarter, _ := ParsePeriod("quarter")
+	week, _ := ParsePeriod("week")
+	tests := []struct {
+		period Period
+		label  string
+		want   bool
+	}{
+		{month, "2024-08", true},
+		{month, "2024-Q3", false},
+		{quarter, "2024-Q3", true},
+		{quarter, "2024-08", false},
+		{week, "2024-08-12", true},
+		{week, "2024-08-14", false}, // Не понедельник
+		{month, "каталог", false},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Matches(tt.label); got != tt.want {
+			t.Errorf("%s.Matches(%q) = %v", tt.period.Name(), tt.label, got)
+		}
+	}
+	if quarter.Name() != "3month" || month.Name() != "month" {
+		t.Errorf("имена периодов %q, %q", quarter.Name(), month.Name())
+	}
+}
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+	corpusFile := filepath.Join(dir, "corpus.jsonl")
+	content := `{"id":"1","timestamp":"2024-01-05T10:00:00Z","text":"январь один"}
+{"id":"2","timestamp":"2024-02-10T10:00:00Z","text":"февраль"}
+{"id":"3","text":"без даты"}
+{"id":"4","timestamp":"2024-01-20T10:00:00Z","text":"январь два"}
+`
+	if err := os.WriteFile(corpusFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	month, _ := ParsePeriod("month")
+	slices, err := Split(corpusFile, filepath.Join(dir, "slices"), month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slices) != 2 || slices[0].Label != "2024-01" || slices[0].Docs != 2 || slices[1].Docs != 1 {
+		t.Fatalf("срезы %+v", slices)
+	}
+	data, err := os.ReadFile(slices[0].Config().InputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "январь один\nянварь два\n" {
+		t.Errorf("корпус среза %q", data)
+	}
+
+	loaded, err := LoadSlices(filepath.Join(dir, "slices"), month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 || loaded[0].Label != "2024-01" || loaded[1].Label != "2024-02" {
+		t.Errorf("LoadSlices = %+v", loaded)
+	}
+	quarter, _ := ParsePeriod("quarter")
+	if loaded, _ := LoadSlices(filepath.Join(dir, "slices"), quarter); len(loaded) != 0 {
+		t.Errorf("срезы другого периода загружены: %+v", loaded)
+	}
+}
+
+func TestSplitPlainText(t *testing.T) {
+	corpusFile := filepath.Join(t.TempDir(), "corpus.txt")
+	if err := os.WriteFile(corpusFile, []byte("просто текст\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	month, _ := ParsePeriod("month")
+	if _, err := Split(corpusFile, t.TempDir(), month); err == nil {
+		t.Error("ожидалась ошибка для корпуса без дат")
+	}
+}
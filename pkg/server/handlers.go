@@ -0,0 +1,403 @@
+package server
+
+import (
+	"fmt"
+	"glove-pipeline/pkg/docindex"
+	"glove-pipeline/pkg/expand"
+	"glove-pipeline/pkg/spell"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleModels возвращает список загруженных моделей
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	type modelInfo struct {
+		Name    string `json:"name"`
+		Words   int    `json:"words"`
+		Dim     int    `json:"dim"`
+		Default bool   `json:"default"`
+	}
+	var result []modelInfo
+	for _, name := range s.modelNames {
+		model := s.models[name]
+		result = append(result, modelInfo{Name: name, Words: model.Len(), Dim: model.Dim(), Default: name == s.defaultModel})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleNeighbors: /neighbors?word=слово&top=10&model=имя
+func (s *Server) handleNeighbors(w http.ResponseWriter, r *http.Request) {
+	model, err := s.model(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	word, err := required(r, "word")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	n, err := topN(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	word = strings.ToLower(word)
+	vec, ok := model.Vector(word)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("слово '%s' не найдено в векторах", word))
+		return
+	}
+	neighbors, err := model.NearestContext(r.Context(), vec, n, map[string]bool{word: true})
+	if err != nil {
+		return // Ответ об истечении времени уже отправлен TimeoutHandler
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "neighbors": neighbors})
+}
+
+// handleSimilarity: /similarity?word1=слово&word2=слово&model=имя
+func (s *Server) handleSimilarity(w http.ResponseWriter, r *http.Request) {
+	model, err := s.model(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	word1, err := required(r, "word1")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	word2, err := required(r, "word2")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sim, err := model.Similarity(strings.ToLower(word1), strings.ToLower(word2))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word1": word1, "word2": word2, "similarity": sim})
+}
+
+// handleAnalogy: /analogy?expr=москва - россия + франция&method=3cosadd&top=10
+// или /analogy?positive=москва,франция&negative=россия
+func (s *Server) handleAnalogy(w http.ResponseWriter, r *http.Request) {
+	model, err := s.model(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	n, err := topN(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	method, err := vectors.ParseAnalogyMethod(r.URL.Query().Get("method"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query := r.URL.Query()
+	var positive, negative []string
+	if expr := query.Get("expr"); expr != "" {
+		positive, negative, err = vectors.ParseAnalogy(strings.ToLower(expr))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		positive = splitWords(query.Get("positive"))
+		negative = splitWords(query.Get("negative"))
+		if len(positive) == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("не задан параметр expr или positive"))
+			return
+		}
+	}
+
+	answers, err := model.AnalogyContext(r.Context(), positive, negative, method, n)
+	if r.Context().Err() != nil {
+		return // Ответ об истечении времени уже отправлен TimeoutHandler
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"positive": positive,
+		"negative": negative,
+		"method":   method,
+		"answers":  answers,
+	})
+}
+
+// handleVector: /vector?word=слово&model=имя
+func (s *Server) handleVector(w http.ResponseWriter, r *http.Request) {
+	model, err := s.model(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	word, err := required(r, "word")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	vec, ok := model.Vector(strings.ToLower(word))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("слово '%s' не найдено в векторах", word))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "vector": vec})
+}
+
+// handlePhraseVector: /phrase-vector?text=фраза&neighbors=10&model=имя.
+// Фраза очищается и переводится в вектор так же, как в режиме phrase примера
+// dialog: нижний регистр, удаление пунктуации и стоп-слов, затем среднее
+// векторов найденных слов, взвешенное по SIF (serve -vocab).
+func (s *Server) handlePhraseVector(w http.ResponseWriter, r *http.Request) {
+	model, err := s.model(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	text, err := required(r, "text")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cleaned := textprocessor.CleanPhrase(text, s.stopWords)
+	tokens := strings.Fields(cleaned)
+	vec, stats := s.embedder(model).Embed(tokens)
+	if vec == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("фраза не содержит слов из векторов"))
+		return
+	}
+	missing := make(map[string]bool, len(stats.Missing))
+	for _, word := range stats.Missing {
+		missing[word] = true
+	}
+	var found []string
+	for _, word := range tokens {
+		if !missing[word] {
+			found = append(found, word)
+		}
+	}
+
+	response := map[string]interface{}{
+		"text":     text,
+		"cleaned":  cleaned,
+		"found":    found,
+		"missing":  stats.Missing,
+		"coverage": stats.Coverage(),
+		"vector":   vec,
+	}
+	if value := r.URL.Query().Get("neighbors"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxTopN {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("параметр neighbors должен быть числом от 1 до %d", maxTopN))
+			return
+		}
+		neighbors, err := model.NearestContext(r.Context(), vec, n, nil)
+		if err != nil {
+			return // Ответ об истечении времени уже отправлен TimeoutHandler
+		}
+		response["neighbors"] = neighbors
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleNGrams: /ngrams?n=2&word=песков&top=10 — самые частые n-граммы, содержащие слово
+func (s *Server) handleNGrams(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	order := 2
+	if value := query.Get("n"); value != "" {
+		var err error
+		if order, err = strconv.Atoi(value); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("параметр n должен быть числом"))
+			return
+		}
+	}
+	pairs, ok := s.ngrams[order]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%d-граммы не загружены", order))
+		return
+	}
+	n, err := topN(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	type ngram struct {
+		Words     []string `json:"words"`
+		Frequency float64  `json:"frequency"`
+	}
+	word := strings.ToLower(strings.TrimSpace(query.Get("word")))
+	result := []ngram{}
+	for _, p := range pairs {
+		if len(result) >= n {
+			break
+		}
+		if word != "" && !containsWord(p.Words, word) {
+			continue
+		}
+		result = append(result, ngram{Words: p.Words, Frequency: p.Frequency})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"n": order, "word": word, "ngrams": result})
+}
+
+// handleExpand: /expand?q=запрос&max=5&phrase=10&min=0.6&model=имя — расширение поискового
+// запроса: соседи для каждого слова или известного словосочетания и для запроса в целом
+func (s *Server) handleExpand(w http.ResponseWriter, r *http.Request) {
+	model, err := s.model(r)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	q, err := required(r, "q")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	opts := expand.DefaultOptions()
+	query := r.URL.Query()
+	if value := query.Get("max"); value != "" {
+		if opts.MaxPerTerm, err = strconv.Atoi(value); err != nil || opts.MaxPerTerm < 0 || opts.MaxPerTerm > maxTopN {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("параметр max должен быть числом от 0 до %d", maxTopN))
+			return
+		}
+	}
+	if value := query.Get("phrase"); value != "" {
+		if opts.MaxPhrase, err = strconv.Atoi(value); err != nil || opts.MaxPhrase < 0 || opts.MaxPhrase > maxTopN {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("параметр phrase должен быть числом от 0 до %d", maxTopN))
+			return
+		}
+	}
+	if value := query.Get("min"); value != "" {
+		if opts.MinSimilarity, err = strconv.ParseFloat(value, 64); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("параметр min должен быть числом"))
+			return
+		}
+	}
+
+	expansion, err := s.expander(model).ExpandContext(r.Context(), q, opts)
+	if err != nil {
+		return // Ответ об истечении времени уже отправлен TimeoutHandler
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"query":   expansion.Query,
+		"cleaned": expansion.Cleaned,
+		"tokens":  expansion.Tokens,
+		"phrase":  expansion.Phrase,
+		"terms":   expansion.Terms(),
+	})
+}
+
+// handleSearch: /search?q=текст&top=10 или /search?id=идентификатор&top=10 — документы
+// корпуса, похожие на текст запроса или на документ индекса
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if s.index == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("индекс документов не загружен (serve -index)"))
+		return
+	}
+	n, err := topN(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	query := r.URL.Query()
+	q, id := strings.TrimSpace(query.Get("q")), strings.TrimSpace(query.Get("id"))
+	if (q == "") == (id == "") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("нужно задать либо параметр q, либо id"))
+		return
+	}
+
+	if id != "" {
+		hits, err := s.index.Similar(id, n)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "hits": hits})
+		return
+	}
+	hits, stats, err := s.index.SearchContext(r.Context(), q, n)
+	if err != nil {
+		return // Ответ об истечении времени уже отправлен TimeoutHandler
+	}
+	if hits == nil {
+		hits = []docindex.Hit{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"query":    q,
+		"cleaned":  docindex.Clean(q),
+		"coverage": stats.Coverage(),
+		"missing":  stats.Missing,
+		"hits":     hits,
+	})
+}
+
+// handleSpell: /spell?word=пескав&context=дмитрий&top=5&model=имя.
+// С параметром context исправления ранжируются с учётом сходства с контекстом по векторам модели.
+func (s *Server) handleSpell(w http.ResponseWriter, r *http.Request) {
+	if s.speller == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("словарь исправлений не загружен (serve -spell)"))
+		return
+	}
+	word, err := required(r, "word")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	n, err := topN(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var suggestions []spell.Suggestion
+	if context := r.URL.Query().Get("context"); context != "" {
+		model, err := s.model(r)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		suggestions = s.speller.SuggestInContext(word, strings.Fields(docindex.Clean(context)), model, n)
+	} else {
+		suggestions = s.speller.Suggest(word, n)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"word": word, "known": s.speller.Has(word), "suggestions": suggestions})
+}
+
+// splitWords разбирает список слов через запятую
+func splitWords(value string) []string {
+	var words []string
+	for _, word := range strings.Split(value, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"glove-pipeline/pkg/docindex"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/expand"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/spell"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Значения по умолчанию для параметров запросов
+const (
+	defaultTopN = 10
+	maxTopN     = 1000
+)
+
+// Config задаёт параметры HTTP-сервера
+type Config struct {
+	Addr            string        // Адрес для прослушивания, например ":8080"
+	RequestTimeout  time.Duration // Максимальное время обработки запроса
+	ShutdownTimeout time.Duration // Время на завершение активных запросов при остановке
+	MaxPhrases      int           // Сколько самых частых n-грамм каждого порядка /expand считает словосочетаниями
+}
+
+// Server отдаёт результаты запросов к векторным моделям в формате JSON
+type Server struct {
+	cfg          Config
+	models       map[string]*vectors.Model
+	modelNames   []string
+	defaultModel string
+	stopWords    map[string]struct{}
+	ngrams       map[int][]ngrams.Pair
+
+	expandMu  sync.Mutex
+	expanders map[*vectors.Model]*expand.Expander
+
+	counts    map[string]int
+	embedMu   sync.Mutex
+	embedders map[*vectors.Model]*docvec.Embedder
+
+	index   *docindex.Index
+	speller *spell.Checker
+}
+
+// New создаёт сервер без моделей
+func New(cfg Config) *Server {
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 30 * time.Second
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 10 * time.Second
+	}
+	if cfg.MaxPhrases <= 0 {
+		cfg.MaxPhrases = 5000
+	}
+	return &Server{
+		cfg:       cfg,
+		models:    make(map[string]*vectors.Model),
+		stopWords: make(map[string]struct{}),
+		ngrams:    make(map[int][]ngrams.Pair),
+		expanders: make(map[*vectors.Model]*expand.Expander),
+		embedders: make(map[*vectors.Model]*docvec.Embedder),
+	}
+}
+
+// AddModel регистрирует модель под именем; первая модель используется по умолчанию
+func (s *Server) AddModel(name string, model *vectors.Model) {
+	if _, ok := s.models[name]; !ok {
+		s.modelNames = append(s.modelNames, name)
+	}
+	s.models[name] = model
+	if s.defaultModel == "" {
+		s.defaultModel = name
+	}
+}
+
+// SetStopWords задаёт стоп-слова для очистки фраз
+func (s *Server) SetStopWords(stopWords map[string]struct{}) {
+	s.stopWords = stopWords
+}
+
+// SetVocabCounts задаёт частоты слов для взвешивания SIF в /phrase-vector;
+// без них вектор фразы — простое среднее векторов слов
+func (s *Server) SetVocabCounts(counts map[string]int) {
+	s.counts = counts
+}
+
+// SetIndex задаёт индекс документов для поиска похожих
+func (s *Server) SetIndex(ix *docindex.Index) {
+	s.index = ix
+}
+
+// SetSpeller задаёт словарь исправления опечаток для /spell
+func (s *Server) SetSpeller(c *spell.Checker) {
+	s.speller = c
+}
+
+// AddNGrams регистрирует n-граммы порядка n (по убыванию частоты)
+func (s *Server) AddNGrams(n int, pairs []ngrams.Pair) {
+	sorted := append([]ngrams.Pair(nil), pairs...)
+	sort.Sort(ngrams.ByFrequency(sorted))
+	s.ngrams[n] = sorted
+}
+
+// Handler возвращает обработчик всех маршрутов с ограничением времени запроса
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/models", s.handleModels)
+	mux.HandleFunc("/neighbors", s.handleNeighbors)
+	mux.HandleFunc("/similarity", s.handleSimilarity)
+	mux.HandleFunc("/analogy", s.handleAnalogy)
+	mux.HandleFunc("/vector", s.handleVector)
+	mux.HandleFunc("/phrase-vector", s.handlePhraseVector)
+	mux.HandleFunc("/ngrams", s.handleNGrams)
+	mux.HandleFunc("/expand", s.handleExpand)
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/spell", s.handleSpell)
+	return http.TimeoutHandler(mux, s.cfg.RequestTimeout, `{"error":"превышено время обработки запроса"}`)
+}
+
+// ListenAndServe запускает сервер и корректно останавливает его при отмене контекста:
+// новые соединения не принимаются, активные запросы завершаются в течение ShutdownTimeout
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.cfg.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       s.cfg.RequestTimeout,
+		WriteTimeout:      s.cfg.RequestTimeout + 5*time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("HTTP-сервер слушает %s", s.cfg.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Остановка HTTP-сервера...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("ошибка при остановке сервера: %v", err)
+	}
+	return nil
+}
+
+// model возвращает модель из параметра model (или модель по умолчанию)
+func (s *Server) model(r *http.Request) (*vectors.Model, error) {
+	name := r.URL.Query().Get("model")
+	if name == "" {
+		name = s.defaultModel
+	}
+	model, ok := s.models[name]
+	if !ok {
+		return nil, fmt.Errorf("модель '%s' не найдена", name)
+	}
+	return model, nil
+}
+
+// expander возвращает расширитель запросов для модели; он создаётся при первом обращении
+// и использует стоп-слова и самые частые n-граммы сервера (не больше MaxPhrases каждого порядка):
+// редкие n-граммы — в основном случайные сочетания, их не стоит расширять как единое целое
+func (s *Server) expander(model *vectors.Model) *expand.Expander {
+	s.expandMu.Lock()
+	defer s.expandMu.Unlock()
+	if e, ok := s.expanders[model]; ok {
+		return e
+	}
+	e := expand.New(model, s.stopWords)
+	for _, pairs := range s.ngrams {
+		pairs = pairs[:min(len(pairs), s.cfg.MaxPhrases)]
+		phrases := make([][]string, len(pairs))
+		for i, p := range pairs {
+			phrases[i] = p.Words
+		}
+		e.AddPhrases(phrases)
+	}
+	s.expanders[model] = e
+	return e
+}
+
+// embedder возвращает построитель векторов фраз для модели; он создаётся при
+// первом обращении и, как режим phrase примера dialog, взвешивает слова по SIF
+func (s *Server) embedder(model *vectors.Model) *docvec.Embedder {
+	s.embedMu.Lock()
+	defer s.embedMu.Unlock()
+	if e, ok := s.embedders[model]; ok {
+		return e
+	}
+	e := docvec.New(model, s.counts, docvec.DefaultOptions())
+	s.embedders[model] = e
+	return e
+}
+
+// topN разбирает параметр top
+func topN(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("top")
+	if value == "" {
+		return defaultTopN, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 || n > maxTopN {
+		return 0, fmt.Errorf("параметр top должен быть числом от 1 до %d", maxTopN)
+	}
+	return n, nil
+}
+
+// required возвращает обязательный параметр запроса
+func required(r *http.Request, name string) (string, error) {
+	value := strings.TrimSpace(r.URL.Query().Get(name))
+	if value == "" {
+		return "", fmt.Errorf("не задан параметр %s", name)
+	}
+	return value, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Ошибка при отправке ответа: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
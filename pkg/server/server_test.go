@@ -0,0 +1,321 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/vectors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testServer() *Server {
+	s := New(Config{})
+	s.AddModel("default", vectors.New(
+		[]string{"король", "королева", "мужчина", "женщина", "москва", "париж", "россия", "франция", "яблоко"},
+		[][]float64{
+			{1, 1, 0, 0, 0},
+			{1, 0, 1, 0, 0},
+			{0, 1, 0, 0, 0.1},
+			{0, 0, 1, 0, 0.1},
+			{0, 0, 0, 1, 1},
+			{0, 0, 0, -1, 1},
+			{0, 0, 0, 1, 0},
+			{0, 0, 0, -1, 0},
+			{0.2, 0.2, 0.2, 0, 0},
+		},
+	))
+	s.AddModel("small", vectors.New([]string{"кот", "пёс"}, [][]float64{{1, 0}, {0.9, 0.1}}))
+	s.SetStopWords(map[string]struct{}{"и": {}})
+	s.AddNGrams(2, []ngrams.Pair{
+		{Words: []string{"москва", "париж"}, Frequency: 3},
+		{Words: []string{"король", "и"}, Frequency: 7},
+	})
+	return s
+}
+
+// get выполняет запрос к серверу и разбирает JSON-ответ
+func get(t *testing.T, s *Server, path string, params url.Values) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+params.Encode(), nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		var list []interface{}
+		if json.Unmarshal(rec.Body.Bytes(), &list) == nil {
+			return rec.Code, map[string]interface{}{"list": list}
+		}
+		t.Fatalf("%s: ответ не JSON: %q", path, rec.Body.String())
+	}
+	return rec.Code, body
+}
+
+// words возвращает слова из списка соседей ответа
+func words(t *testing.T, value interface{}) []string {
+	t.Helper()
+	list, ok := value.([]interface{})
+	if !ok {
+		t.Fatalf("ожидался список соседей, получено %v", value)
+	}
+	var result []string
+	for _, item := range list {
+		result = append(result, item.(map[string]interface{})["word"].(string))
+	}
+	return result
+}
+
+func TestHealthAndModels(t *testing.T) {
+	s := testServer()
+	if code, body := get(t, s, "/health", nil); code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("/health: %d %v", code, body)
+	}
+	_, body := get(t, s, "/models", nil)
+	models := body["list"].([]interface{})
+	if len(models) != 2 {
+		t.Fatalf("/models: %v", models)
+	}
+	first := models[0].(map[string]interface{})
+	if first["name"] != "default" || first["default"] != true || first["words"] != 9.0 || first["dim"] != 5.0 {
+		t.Errorf("/models: %v", first)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	s := testServer()
+	code, body := get(t, s, "/neighbors", url.Values{"word": {"Король"}, "top": {"2"}})
+	if code != http.StatusOK {
+		t.Fatalf("/neighbors: %d %v", code, body)
+	}
+	got := words(t, body["neighbors"])
+	if len(got) != 2 || got[0] == "король" {
+		t.Errorf("/neighbors: %v", got)
+	}
+
+	code, body = get(t, s, "/neighbors", url.Values{"word": {"кот"}, "model": {"small"}})
+	if code != http.StatusOK || words(t, body["neighbors"])[0] != "пёс" {
+		t.Errorf("/neighbors в модели small: %d %v", code, body)
+	}
+
+	tests := []struct {
+		params url.Values
+		status int
+	}{
+		{url.Values{}, http.StatusBadRequest},
+		{url.Values{"word": {"король"}, "top": {"0"}}, http.StatusBadRequest},
+		{url.Values{"word": {"король"}, "top": {"abc"}}, http.StatusBadRequest},
+		{url.Values{"word": {"нет"}}, http.StatusNotFound},
+		{url.Values{"word": {"король"}, "model": {"нет"}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code, body := get(t, s, "/neighbors", tt.params); code != tt.status || body["error"] == nil {
+			t.Errorf("/neighbors?%s: %d %v, ожидался код %d", tt.params.Encode(), code, body, tt.status)
+		}
+	}
+}
+
+func TestSimilarityAndVector(t *testing.T) {
+	s := testServer()
+	code, body := get(t, s, "/similarity", url.Values{"word1": {"москва"}, "word2": {"россия"}})
+	if code != http.StatusOK {
+		t.Fatalf("/similarity: %d %v", code, body)
+	}
+	if sim := body["similarity"].(float64); sim < 0.7 || sim > 0.71 {
+		t.Errorf("/similarity = %v, ожидалось 1/√2", sim)
+	}
+	if code, _ := get(t, s, "/similarity", url.Values{"word1": {"москва"}}); code != http.StatusBadRequest {
+		t.Errorf("/similarity без word2: %d", code)
+	}
+
+	code, body = get(t, s, "/vector", url.Values{"word": {"яблоко"}})
+	if code != http.StatusOK || len(body["vector"].([]interface{})) != 5 {
+		t.Errorf("/vector: %d %v", code, body)
+	}
+}
+
+func TestAnalogy(t *testing.T) {
+	s := testServer()
+	for _, params := range []url.Values{
+		{"expr": {"Король - мужчина + женщина"}, "top": {"1"}},
+		{"positive": {"король, женщина"}, "negative": {"мужчина"}, "method": {"3cosmul"}, "top": {"1"}},
+	} {
+		code, body := get(t, s, "/analogy", params)
+		if code != http.StatusOK {
+			t.Fatalf("/analogy?%s: %d %v", params.Encode(), code, body)
+		}
+		if got := words(t, body["answers"]); len(got) != 1 || got[0] != "королева" {
+			t.Errorf("/analogy?%s: %v", params.Encode(), got)
+		}
+	}
+	tests := []struct {
+		params url.Values
+		status int
+	}{
+		{url.Values{}, http.StatusBadRequest},
+		{url.Values{"expr": {"- россия"}}, http.StatusBadRequest},
+		{url.Values{"expr": {"москва"}, "method": {"div"}}, http.StatusBadRequest},
+		{url.Values{"expr": {"москва - нет"}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code, _ := get(t, s, "/analogy", tt.params); code != tt.status {
+			t.Errorf("/analogy?%s: %d, ожидался %d", tt.params.Encode(), code, tt.status)
+		}
+	}
+}
+
+func TestPhraseVector(t *testing.T) {
+	s := testServer()
+	code, body := get(t, s, "/phrase-vector", url.Values{"text": {"Москва и Париж!"}, "neighbors": {"3"}})
+	if code != http.StatusOK {
+		t.Fatalf("/phrase-vector: %d %v", code, body)
+	}
+	if body["cleaned"] != "москва париж" {
+		t.Errorf("очищенная фраза %q", body["cleaned"])
+	}
+	if vec := body["vector"].([]interface{}); len(vec) != 5 || vec[3].(float64) != 0 || vec[4].(float64) != 1 {
+		t.Errorf("вектор фразы %v", vec)
+	}
+	if got := words(t, body["neighbors"]); len(got) != 3 {
+		t.Errorf("соседи фразы %v", got)
+	}
+
+	if cov := body["coverage"].(float64); cov != 1 || body["missing"] != nil {
+		t.Errorf("покрытие фразы %v, нет в векторах %v", cov, body["missing"])
+	}
+
+	// С частотами слов вектор фразы взвешивается по SIF, как в примере dialog:
+	// частая «москва» весит меньше редкого «парижа»
+	s = testServer()
+	s.SetVocabCounts(map[string]int{"москва": 1000, "париж": 10})
+	code, body = get(t, s, "/phrase-vector", url.Values{"text": {"Москва, Париж и Лондон"}})
+	if code != http.StatusOK {
+		t.Fatalf("/phrase-vector с частотами: %d %v", code, body)
+	}
+	if vec := body["vector"].([]interface{}); vec[3].(float64) >= 0 {
+		t.Errorf("вектор фразы с весами SIF %v: у «парижа» вес должен быть больше", vec)
+	}
+	if missing := body["missing"].([]interface{}); len(missing) != 1 || missing[0] != "лондон" || body["coverage"].(float64) != 2.0/3 {
+		t.Errorf("нет в векторах %v, покрытие %v", missing, body["coverage"])
+	}
+
+	if code, _ := get(t, s, "/phrase-vector", url.Values{"text": {"неизвестные слова"}}); code != http.StatusNotFound {
+		t.Errorf("фраза без известных слов: %d", code)
+	}
+	if code, _ := get(t, s, "/phrase-vector", url.Values{"text": {"москва"}, "neighbors": {"-1"}}); code != http.StatusBadRequest {
+		t.Errorf("некорректный neighbors: %d", code)
+	}
+}
+
+func TestNGrams(t *testing.T) {
+	s := testServer()
+	code, body := get(t, s, "/ngrams", url.Values{"n": {"2"}})
+	if code != http.StatusOK {
+		t.Fatalf("/ngrams: %d %v", code, body)
+	}
+	list := body["ngrams"].([]interface{})
+	if len(list) != 2 || list[0].(map[string]interface{})["frequency"] != 7.0 {
+		t.Errorf("n-граммы не упорядочены по частоте: %v", list)
+	}
+	_, body = get(t, s, "/ngrams", url.Values{"word": {"Париж"}})
+	if list := body["ngrams"].([]interface{}); len(list) != 1 {
+		t.Errorf("n-граммы со словом: %v", list)
+	}
+	if code, _ := get(t, s, "/ngrams", url.Values{"n": {"3"}}); code != http.StatusNotFound {
+		t.Errorf("незагруженные 3-граммы: %d", code)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	// Большая модель, перебор которой не укладывается в ограничение времени
+	const n = 200000
+	vocab := make([]string, n)
+	vecs := make([][]float64, n)
+	for i := range vocab {
+		vocab[i] = "w" + strconv.Itoa(i)
+		vecs[i] = []float64{float64(i%7 + 1), float64(i%5 + 1), float64(i%3 + 1)}
+	}
+	s := New(Config{RequestTimeout: time.Microsecond})
+	s.AddModel("default", vectors.New(vocab, vecs))
+
+	code, body := get(t, s, "/neighbors", url.Values{"word": {"w1"}})
+	if code != http.StatusServiceUnavailable || body["error"] == nil {
+		t.Errorf("ответ по истечении времени: %d %v", code, body)
+	}
+}
+
+func TestCanceledRequest(t *testing.T) {
+	// Обработчики перебора словаря прекращают работу по отмене контекста запроса
+	// и ничего не пишут: ответ об истечении времени отправляет TimeoutHandler
+	s := testServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tt := range []struct {
+		handler http.HandlerFunc
+		params  url.Values
+	}{
+		{s.handleNeighbors, url.Values{"word": {"король"}}},
+		{s.handleAnalogy, url.Values{"expr": {"король - мужчина + женщина"}}},
+		{s.handleAnalogy, url.Values{"expr": {"король - мужчина + женщина"}, "method": {"3cosmul"}}},
+		{s.handlePhraseVector, url.Values{"text": {"москва париж"}, "neighbors": {"3"}}},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/?"+tt.params.Encode(), nil).WithContext(ctx)
+		tt.handler(rec, req)
+		if rec.Body.Len() != 0 {
+			t.Errorf("?%s: после отмены запроса отправлен ответ %q", tt.params.Encode(), rec.Body.String())
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	// Слова вне модели получают векторы Fallback во всех маршрутах, которые ищут слова
+	s := testServer()
+	model := s.models["default"]
+	forms := map[string]string{"королевы": "королева", "москвы": "москва"}
+	model.SetFallback(func(word string) ([]float64, bool) {
+		i, ok := model.Index(forms[word])
+		if !ok {
+			return nil, false
+		}
+		return model.Vectors[i], true
+	})
+
+	if code, body := get(t, s, "/neighbors", url.Values{"word": {"королевы"}, "top": {"1"}}); code != http.StatusOK {
+		t.Errorf("/neighbors: %d %v", code, body)
+	} else if got := words(t, body["neighbors"]); len(got) != 1 || got[0] != "королева" {
+		t.Errorf("/neighbors: %v", got)
+	}
+	if code, body := get(t, s, "/similarity", url.Values{"word1": {"королевы"}, "word2": {"королева"}}); code != http.StatusOK || body["similarity"].(float64) < 1-1e-9 {
+		t.Errorf("/similarity: %d %v", code, body)
+	}
+
+	code, body := get(t, s, "/analogy", url.Values{"expr": {"королевы - женщина + мужчина"}, "top": {"1"}})
+	if code != http.StatusOK {
+		t.Fatalf("/analogy: %d %v", code, body)
+	}
+	if got := words(t, body["answers"]); len(got) != 1 || got[0] != "король" {
+		t.Errorf("/analogy: %v", got)
+	}
+
+	code, body = get(t, s, "/phrase-vector", url.Values{"text": {"Москвы и Париж"}})
+	if code != http.StatusOK {
+		t.Fatalf("/phrase-vector: %d %v", code, body)
+	}
+	if body["coverage"].(float64) != 1 || body["missing"] != nil {
+		t.Errorf("/phrase-vector: покрытие %v, нет в векторах %v", body["coverage"], body["missing"])
+	}
+	if vec := body["vector"].([]interface{}); vec[3].(float64) != 0 || vec[4].(float64) != 1 {
+		t.Errorf("/phrase-vector: вектор %v", vec)
+	}
+
+	code, body = get(t, s, "/expand", url.Values{"q": {"королевы"}})
+	if code != http.StatusOK {
+		t.Fatalf("/expand: %d %v", code, body)
+	}
+	if tokens := body["tokens"].([]interface{}); len(tokens) != 1 || tokens[0].(map[string]interface{})["found"] != true {
+		t.Errorf("/expand: %v", tokens)
+	}
+}
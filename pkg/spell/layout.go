@@ -0,0 +1,57 @@
+package spell
+
+import "unicode"
+
+// Клавиши раскладки QWERTY и символы ЙЦУКЕН на тех же местах
+const (
+	latinKeys    = "`qwertyuiop[]asdfghjkl;'zxcvbnm,."
+	cyrillicKeys = "ёйцукенгшщзхъфывапролджэячсмитьбю"
+)
+
+var latinToCyrillic, cyrillicToLatin = layoutMaps()
+
+func layoutMaps() (map[rune]rune, map[rune]rune) {
+	lat, cyr := []rune(latinKeys), []rune(cyrillicKeys)
+	toCyr := make(map[rune]rune, len(lat))
+	toLat := make(map[rune]rune, len(lat))
+	for i := range lat {
+		toCyr[lat[i]] = cyr[i]
+		toLat[cyr[i]] = lat[i]
+	}
+	return toCyr, toLat
+}
+
+// SwapLayout переводит слово, набранное не в той раскладке, в другую: «ghbdtn» → «привет»,
+// «cnfnec» → «статус», и обратно «шзрщту» → «iphone». ok равно false, если слово
+// содержит и латинские, и кириллические буквы или символы вне раскладки.
+// Слово должно быть в нижнем регистре.
+func SwapLayout(word string) (string, bool) {
+	var table map[rune]rune
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if _, ok := latinToCyrillic[r]; ok {
+			table = latinToCyrillic
+		} else {
+			table = cyrillicToLatin
+		}
+		break
+	}
+	if table == nil {
+		return "", false
+	}
+	out := make([]rune, 0, len(word))
+	for _, r := range word {
+		swapped, ok := table[r]
+		if !ok {
+			if unicode.IsDigit(r) || r == '-' {
+				out = append(out, r)
+				continue
+			}
+			return "", false
+		}
+		out = append(out, swapped)
+	}
+	return string(out), true
+}
@@ -0,0 +1,333 @@
+package spell
+
+import (
+	"cmp"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/vectors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Options задаёт параметры поиска исправлений
+type Options struct {
+	MaxDistance     int     // Максимальное расстояние Дамерау — Левенштейна до исправления
+	PrefixLength    int     // Длина префикса слова, по которому строятся удаления SymSpell
+	MinCount        int     // Слова словаря с меньшей частотой не предлагаются
+	FrequencyWeight float64 // Вес частоты слова в оценке (меньше 1, чтобы расстояние было важнее)
+	ContextWeight   float64 // Вес сходства с контекстом в оценке
+	// FallbackMinCount — минимальная частота исправления, вектор которого Fallback
+	// отдаёт слову вне модели: редкие слова словаря чаще сами оказываются опечатками
+	FallbackMinCount int
+}
+
+// DefaultOptions возвращает параметры по умолчанию
+func DefaultOptions() Options {
+	return Options{MaxDistance: 2, PrefixLength: 7, MinCount: 1, FrequencyWeight: 0.5, ContextWeight: 1, FallbackMinCount: 5}
+}
+
+// Suggestion — вариант исправления слова
+type Suggestion struct {
+	Word       string  `json:"word"`
+	Distance   int     `json:"distance"`
+	Count      int     `json:"count"`
+	Layout     bool    `json:"layout,omitempty"`     // Слово набрано в другой раскладке клавиатуры
+	Similarity float64 `json:"similarity,omitempty"` // Косинусное сходство с контекстом
+	Score      float64 `json:"score"`
+}
+
+// Checker ищет исправления по словарю методом SymSpell: для каждого слова
+// заранее строятся все варианты его префикса с удалением до MaxDistance символов,
+// и кандидаты для запроса находятся по совпадению таких вариантов. Буква «ё»
+// приравнивается к «е».
+type Checker struct {
+	opts     Options
+	words    []string
+	counts   []int
+	forms    []string         // Различные свёрнутые формы слов (нижний регистр, «ё» → «е»)
+	formWord [][]int32        // Слова словаря для каждой формы
+	formID   map[string]int32 // Номер формы по её тексту
+	deletes  []deletion       // Варианты с удалениями, упорядоченные по хешу
+	logMax   float64
+}
+
+// Load строит проверку по словарю GloVe vocab.txt
+func Load(vocabFile string, opts Options) (*Checker, error) {
+	entries, err := glove.LoadVocab(vocabFile)
+	if err != nil {
+		return nil, err
+	}
+	return New(entries, opts), nil
+}
+
+// New строит проверку по словам словаря и их частотам
+func New(entries []glove.VocabEntry, opts Options) *Checker {
+	def := DefaultOptions()
+	if opts.MaxDistance < 0 {
+		opts.MaxDistance = def.MaxDistance
+	}
+	if opts.PrefixLength <= opts.MaxDistance {
+		opts.PrefixLength = def.PrefixLength
+	}
+	c := &Checker{opts: opts, formID: make(map[string]int32)}
+	maxCount := 1
+	for _, e := range entries {
+		if e.Count < opts.MinCount || e.Word == "" {
+			continue
+		}
+		id := int32(len(c.words))
+		c.words = append(c.words, e.Word)
+		c.counts = append(c.counts, e.Count)
+		maxCount = max(maxCount, e.Count)
+
+		form := Fold(e.Word)
+		f, ok := c.formID[form]
+		if !ok {
+			f = int32(len(c.forms))
+			c.formID[form] = f
+			c.forms = append(c.forms, form)
+			c.formWord = append(c.formWord, nil)
+			c.variants(form, func(key uint32) {
+				c.deletes = append(c.deletes, deletion{key: key, form: f})
+			})
+		}
+		c.formWord[f] = append(c.formWord[f], id)
+	}
+	c.logMax = math.Log(float64(maxCount) + 1)
+
+	// Совпадения хешей у разных вариантов лишь добавляют кандидатов: расстояние
+	// до каждого всё равно проверяется
+	slices.SortFunc(c.deletes, func(a, b deletion) int {
+		if a.key != b.key {
+			return cmp.Compare(a.key, b.key)
+		}
+		return cmp.Compare(a.form, b.form)
+	})
+	c.deletes = slices.Compact(c.deletes)
+	return c
+}
+
+// deletion — хеш варианта префикса формы с удалёнными символами и номер формы
+type deletion struct {
+	key  uint32
+	form int32
+}
+
+// Len возвращает число слов словаря
+func (c *Checker) Len() int {
+	return len(c.words)
+}
+
+// Has сообщает, есть ли слово в словаре с точностью до регистра и «ё»
+func (c *Checker) Has(word string) bool {
+	_, ok := c.formID[Fold(word)]
+	return ok
+}
+
+// Suggest возвращает до top исправлений слова (top ≤ 0 — все найденные),
+// лучшие первыми: по расстоянию, затем по частоте. Слово, набранное в другой
+// раскладке, проверяется и в исходном, и в переключённом виде.
+func (c *Checker) Suggest(word string, top int) []Suggestion {
+	return c.rank(c.candidates(word), nil, nil, top)
+}
+
+// SuggestInContext ранжирует исправления с учётом контекста: к оценке добавляется
+// косинусное сходство вектора кандидата со средним вектором слов контекста
+func (c *Checker) SuggestInContext(word string, context []string, model *vectors.Model, top int) []Suggestion {
+	var target []float64
+	if model != nil {
+		target, _ = model.MeanVector(context)
+	}
+	return c.rank(c.candidates(word), model, target, top)
+}
+
+// Correct возвращает лучшее исправление слова; ok равно false, если его нет
+func (c *Checker) Correct(word string) (string, bool) {
+	suggestions := c.Suggest(word, 1)
+	if len(suggestions) == 0 {
+		return "", false
+	}
+	return suggestions[0].Word, true
+}
+
+// Fallback возвращает построение векторов для слов вне модели: берётся вектор
+// лучшего исправления, которое есть в модели. Исправление должно встречаться не
+// реже FallbackMinCount раз и быть достаточно близким для длины слова (см.
+// fallbackDistance): у короткого слова почти любая замена даёт другое слово.
+func (c *Checker) Fallback(model *vectors.Model) vectors.Fallback {
+	return func(word string) ([]float64, bool) {
+		maxDistance := fallbackDistance(len([]rune(Fold(word))))
+		for _, s := range c.Suggest(word, 0) {
+			if s.Distance > maxDistance || s.Count < c.opts.FallbackMinCount {
+				continue
+			}
+			if i, ok := model.Index(s.Word); ok {
+				return model.Vectors[i], true
+			}
+		}
+		return nil, false
+	}
+}
+
+// fallbackDistance возвращает допустимое расстояние до исправления для Fallback:
+// для слов до 3 символов — только регистр, «ё» и раскладка, до 7 — одна ошибка,
+// для более длинных — две
+func fallbackDistance(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 7:
+		return 1
+	}
+	return 2
+}
+
+// Attach подключает исправление опечаток к векторам: слово вне словаря получает
+// вектор ближайшего по написанию слова модели
+func (c *Checker) Attach(model *vectors.Model) {
+	model.AddFallback(c.Fallback(model))
+}
+
+// candidates находит слова словаря на расстоянии не больше MaxDistance от слова
+// и от его варианта в другой раскладке
+func (c *Checker) candidates(word string) map[int32]Suggestion {
+	found := make(map[int32]Suggestion)
+	query := Fold(word)
+	if query == "" {
+		return found
+	}
+	c.lookup(query, c.opts.MaxDistance, false, found)
+	if swapped, ok := SwapLayout(query); ok {
+		// Для переключённой раскладки допускается не больше одной опечатки,
+		// иначе находятся случайные слова другого алфавита
+		c.lookup(Fold(swapped), min(c.opts.MaxDistance, 1), true, found)
+	}
+	return found
+}
+
+// lookup добавляет в found слова, свёрнутая форма которых отличается от query
+// не больше чем на maxDistance
+func (c *Checker) lookup(query string, maxDistance int, layout bool, found map[int32]Suggestion) {
+	seen := make(map[int32]bool)
+	c.variants(query, func(key uint32) {
+		i, _ := slices.BinarySearchFunc(c.deletes, key, func(d deletion, key uint32) int { return cmp.Compare(d.key, key) })
+		for ; i < len(c.deletes) && c.deletes[i].key == key; i++ {
+			f := c.deletes[i].form
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			d := Distance(query, c.forms[f])
+			if d > maxDistance {
+				continue
+			}
+			for _, id := range c.formWord[f] {
+				prev, ok := found[id]
+				if ok && (prev.Distance < d || prev.Distance == d && !prev.Layout) {
+					continue
+				}
+				found[id] = Suggestion{Word: c.words[id], Distance: d, Count: c.counts[id], Layout: layout}
+			}
+		}
+	})
+}
+
+// rank оценивает кандидатов и возвращает top лучших
+func (c *Checker) rank(found map[int32]Suggestion, model *vectors.Model, target []float64, top int) []Suggestion {
+	result := make([]Suggestion, 0, len(found))
+	for _, s := range found {
+		s.Score = -float64(s.Distance) + c.opts.FrequencyWeight*math.Log(float64(s.Count)+1)/c.logMax
+		if target != nil {
+			if i, ok := model.Index(s.Word); ok {
+				s.Similarity = vectors.CosineSimilarity(model.Vectors[i], target)
+				s.Score += c.opts.ContextWeight * s.Similarity
+			}
+		}
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Word < result[j].Word
+	})
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+// variants вызывает fn для хеша префикса слова длиной PrefixLength и хешей всех
+// его вариантов с удалением от одного до MaxDistance символов. Удалить можно и все
+// символы: пустой вариант связывает слова из одного-двух символов между собой.
+func (c *Checker) variants(word string, fn func(key uint32)) {
+	runes := []rune(word)
+	if len(runes) > c.opts.PrefixLength {
+		runes = runes[:c.opts.PrefixLength]
+	}
+	removed := make([]bool, len(runes))
+	var visit func(from, left int)
+	visit = func(from, left int) {
+		fn(hashKept(runes, removed))
+		if left == 0 {
+			return
+		}
+		for i := from; i < len(runes); i++ {
+			removed[i] = true
+			visit(i+1, left-1)
+			removed[i] = false
+		}
+	}
+	visit(0, min(c.opts.MaxDistance, len(runes)))
+}
+
+// hashKept — хеш FNV-1a символов, которые не удалены
+func hashKept(runes []rune, removed []bool) uint32 {
+	h := uint32(2166136261)
+	for i, r := range runes {
+		if removed[i] {
+			continue
+		}
+		for r != 0 {
+			h ^= uint32(r & 0xff)
+			h *= 16777619
+			r >>= 8
+		}
+		h ^= 0xff // Разделитель символов
+		h *= 16777619
+	}
+	return h
+}
+
+// Fold приводит слово к нижнему регистру и заменяет «ё» на «е»
+func Fold(word string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(word)), "ё", "е")
+}
+
+// Distance — расстояние Дамерау — Левенштейна (вставки, удаления, замены
+// и перестановки соседних символов) между строками в символах
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
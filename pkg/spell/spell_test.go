@@ -0,0 +1,170 @@
+package spell
+
+import (
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/vectors"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"кот", "кот", 0},
+		{"кот", "", 3},
+		{"кот", "кто", 1},       // Перестановка соседних букв
+		{"кот", "крот", 1},      // Вставка
+		{"кошка", "кшка", 1},    // Удаление
+		{"кошка", "мошка", 1},   // Замена
+		{"привет", "пирвте", 2}, // Две перестановки
+		{"ёж", "еж", 1},         // Distance не сворачивает «ё»
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSwapLayout(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+		ok   bool
+	}{
+		{"ghbdtn", "привет", true},
+		{"cnfnec", "статус", true},
+		{"шзрщту", "iphone", true},
+		{"b2b-vfhrtn", "и2и-маркет", true},
+		{"ghbвет", "", false}, // Смешаны алфавиты
+		{"123", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := SwapLayout(tt.word)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("SwapLayout(%q) = %q, %v, want %q, %v", tt.word, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+var testVocab = []glove.VocabEntry{
+	{Word: "привет", Count: 100},
+	{Word: "приветы", Count: 3},
+	{Word: "ёлка", Count: 40},
+	{Word: "кошка", Count: 50},
+	{Word: "мошка", Count: 4}, // Реже FallbackMinCount
+	{Word: "я", Count: 500},
+	{Word: "и", Count: 900},
+	{Word: "документация", Count: 20},
+	{Word: "редкий", Count: 0},
+}
+
+func TestSuggest(t *testing.T) {
+	c := New(testVocab, Options{MaxDistance: 2, PrefixLength: 7, MinCount: 1, FrequencyWeight: 0.5})
+	tests := []struct {
+		name     string
+		word     string
+		want     string
+		distance int
+		layout   bool
+	}{
+		{"замена", "кожка", "кошка", 1, false},
+		{"перестановка", "пирвет", "привет", 1, false},
+		{"удаление", "прмвет", "привет", 1, false},
+		{"две ошибки", "пирвт", "привет", 2, false},
+		{"частота решает при равном расстоянии", "ношка", "кошка", 1, false},
+		{"однобуквенное слово", "ы", "и", 1, false},
+		{"ё приравнивается к е", "елка", "ёлка", 0, false},
+		{"регистр не важен", "Кошка", "кошка", 0, false},
+		{"другая раскладка", "ghbdtn", "привет", 0, true},
+		{"ошибка за пределами префикса", "документациа", "документация", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Suggest(tt.word, 1)
+			if len(got) == 0 {
+				t.Fatalf("Suggest(%q) ничего не нашёл", tt.word)
+			}
+			if got[0].Word != tt.want || got[0].Distance != tt.distance || got[0].Layout != tt.layout {
+				t.Errorf("Suggest(%q) = %+v, want %q на расстоянии %d (раскладка %v)", tt.word, got[0], tt.want, tt.distance, tt.layout)
+			}
+		})
+	}
+
+	if got := c.Suggest("абвгдеёж", 0); len(got) != 0 {
+		t.Errorf("Suggest для далёкого слова = %v, want пусто", got)
+	}
+	if c.Has("редкий") {
+		t.Error("слово с частотой ниже MinCount попало в словарь")
+	}
+	all := c.Suggest("ношка", 0)
+	for i := 1; i < len(all); i++ {
+		if all[i].Score > all[i-1].Score {
+			t.Fatalf("исправления не упорядочены по оценке: %v", all)
+		}
+	}
+}
+
+func TestSuggestInContext(t *testing.T) {
+	c := New(testVocab, DefaultOptions())
+	model := vectors.New(
+		[]string{"кошка", "мошка", "мурлычет", "летает"},
+		[][]float64{{1, 0}, {0, 1}, {1, 0.1}, {0.1, 1}},
+	)
+	tests := []struct {
+		context []string
+		want    string
+	}{
+		{[]string{"мурлычет"}, "кошка"},
+		{[]string{"летает"}, "мошка"},
+	}
+	for _, tt := range tests {
+		got := c.SuggestInContext("ношка", tt.context, model, 1)
+		if len(got) == 0 || got[0].Word != tt.want {
+			t.Errorf("SuggestInContext(ношка, %v) = %v, want %q", tt.context, got, tt.want)
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	c := New(testVocab, DefaultOptions())
+	model := vectors.New(
+		[]string{"привет", "приветы", "кошка", "мошка", "я", "и"},
+		[][]float64{{1, 0}, {0.9, 0.1}, {0, 1}, {0.1, 0.9}, {0.5, 0.5}, {0.6, 0.4}},
+	)
+	c.Attach(model)
+	tests := []struct {
+		name string
+		word string
+		want string // Пусто — вектор не строится
+	}{
+		{"одна ошибка в слове средней длины", "кожка", "кошка"},
+		{"другая раскладка", "ghbdtn", "привет"},
+		{"две ошибки в слове до 7 символов", "пирвт", ""},
+		{"короткое слово не исправляется", "ы", ""},
+		{"редкое исправление не используется", "мошкк", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if model.Has(tt.word) {
+				t.Fatalf("Has(%q) сообщает о слове вне модели", tt.word)
+			}
+			vec, ok := model.Vector(tt.word)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("Vector(%q) = %v, want нет вектора", tt.word, vec)
+				}
+				return
+			}
+			want, _ := model.Vector(tt.want)
+			if !ok || vec[0] != want[0] || vec[1] != want[1] {
+				t.Errorf("Vector(%q) = %v, want вектор %q %v", tt.word, vec, tt.want, want)
+			}
+		})
+	}
+}
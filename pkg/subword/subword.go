@@ -0,0 +1,237 @@
+package subword
+
+import (
+	"encoding/gob"
+	"fmt"
+	"glove-pipeline/pkg/vectors"
+	"hash/fnv"
+	"math/rand"
+	"os"
+)
+
+// Options задаёт параметры модели n-грамм и её обучения
+type Options struct {
+	MinN         int     // Минимальная длина n-граммы в символах
+	MaxN         int     // Максимальная длина n-граммы в символах
+	Buckets      int     // Число корзин хеширования n-грамм
+	Epochs       int     // Число проходов по словам
+	LearningRate float64 // Начальный шаг SGD (линейно убывает до нуля)
+	Limit        int     // Сколько первых (самых частых) слов модели использовать (0 — все)
+	HeldOut      float64 // Доля слов, отложенных для оценки качества восстановления
+	Seed         int64
+}
+
+// DefaultOptions возвращает параметры по умолчанию
+func DefaultOptions() Options {
+	return Options{MinN: 3, MaxN: 6, Buckets: 200000, Epochs: 10, LearningRate: 0.5, Limit: 100000, HeldOut: 0.05, Seed: 1}
+}
+
+// Model — векторы n-грамм символов. Вектор слова — среднее векторов n-грамм
+// слова, обрамлённого маркерами «<» и «>». N-граммы хешируются в Buckets корзин,
+// поэтому модель строит вектор любого непустого слова.
+type Model struct {
+	VectorsFile string // Файл векторов, которые восстанавливает модель
+	MinN        int
+	MaxN        int
+	Buckets     int
+	Dim         int
+	Weights     []float32 // Векторы корзин подряд: корзина b занимает Weights[b·Dim : (b+1)·Dim]
+}
+
+// Report — качество восстановления векторов слов
+type Report struct {
+	Train   float64 // Среднее косинусное сходство восстановленного и исходного вектора на обучающих словах
+	HeldOut float64 // То же на отложенных словах — оценка качества для незнакомых слов
+	Words   int
+	Held    int
+}
+
+// Train обучает векторы n-грамм так, чтобы среднее n-грамм слова восстанавливало
+// его нормированный вектор GloVe (квадратичная ошибка, SGD). progress получает
+// номер эпохи и среднюю ошибку.
+func Train(model *vectors.Model, opts Options, progress func(epoch int, loss float64)) (*Model, *Report, error) {
+	def := DefaultOptions()
+	if opts.MinN <= 0 {
+		opts.MinN = def.MinN
+	}
+	if opts.MaxN < opts.MinN {
+		opts.MaxN = opts.MinN
+	}
+	if opts.Buckets <= 0 {
+		opts.Buckets = def.Buckets
+	}
+	if opts.Epochs <= 0 {
+		opts.Epochs = def.Epochs
+	}
+	if opts.LearningRate <= 0 {
+		opts.LearningRate = def.LearningRate
+	}
+	if model.Len() == 0 {
+		return nil, nil, fmt.Errorf("модель векторов пуста")
+	}
+
+	m := &Model{MinN: opts.MinN, MaxN: opts.MaxN, Buckets: opts.Buckets, Dim: model.Dim()}
+	m.Weights = make([]float32, m.Buckets*m.Dim)
+
+	// Слова делятся на обучающие и отложенные; n-граммы считаются один раз
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var train, held []int
+	for i := range model.Words {
+		if opts.Limit > 0 && i >= opts.Limit {
+			break
+		}
+		if rng.Float64() < opts.HeldOut {
+			held = append(held, i)
+		} else {
+			train = append(train, i)
+		}
+	}
+	if len(train) == 0 {
+		return nil, nil, fmt.Errorf("нет слов для обучения")
+	}
+	grams := make(map[int][]int, len(train))
+	targets := make(map[int][]float64, len(train))
+	for _, i := range train {
+		grams[i] = m.buckets(model.Words[i])
+		targets[i] = vectors.Normalize(model.Vectors[i])
+	}
+
+	pred := make([]float64, m.Dim)
+	total := opts.Epochs * len(train)
+	step := 0
+	for epoch := 1; epoch <= opts.Epochs; epoch++ {
+		rng.Shuffle(len(train), func(a, b int) { train[a], train[b] = train[b], train[a] })
+		var loss float64
+		for _, i := range train {
+			lr := opts.LearningRate * (1 - float64(step)/float64(total))
+			step++
+			bs, target := grams[i], targets[i]
+			if len(bs) == 0 {
+				continue
+			}
+			m.compose(bs, pred)
+			var l float64
+			for d := range pred {
+				pred[d] -= target[d] // Теперь pred — градиент ошибки по среднему
+				l += pred[d] * pred[d]
+			}
+			loss += l / 2
+			scale := lr / float64(len(bs))
+			for _, b := range bs {
+				row := m.Weights[b*m.Dim : (b+1)*m.Dim]
+				for d, g := range pred {
+					row[d] -= float32(scale * g)
+				}
+			}
+		}
+		if progress != nil {
+			progress(epoch, loss/float64(len(train)))
+		}
+	}
+
+	report := &Report{Train: m.reconstruction(model, train), HeldOut: m.reconstruction(model, held), Words: len(train), Held: len(held)}
+	return m, report, nil
+}
+
+// reconstruction — среднее косинусное сходство восстановленных и исходных векторов слов ids
+func (m *Model) reconstruction(model *vectors.Model, ids []int) float64 {
+	if len(ids) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, i := range ids {
+		if vec, ok := m.Vector(model.Words[i]); ok {
+			sum += vectors.CosineSimilarity(vec, model.Vectors[i])
+		}
+	}
+	return sum / float64(len(ids))
+}
+
+// Vector строит вектор слова по его n-граммам. ok равно false для пустого слова.
+func (m *Model) Vector(word string) ([]float64, bool) {
+	bs := m.buckets(word)
+	if len(bs) == 0 {
+		return nil, false
+	}
+	vec := make([]float64, m.Dim)
+	m.compose(bs, vec)
+	return vec, true
+}
+
+// Attach подключает модель к векторам: слова вне словаря, для которых не нашлось
+// вектора другим способом (например, исправлением опечатки), получают векторы по n-граммам
+func (m *Model) Attach(model *vectors.Model) error {
+	if model.Dim() != m.Dim {
+		return fmt.Errorf("размерность векторов %d не совпадает с размерностью модели n-грамм %d", model.Dim(), m.Dim)
+	}
+	model.AddFallback(m.Vector)
+	return nil
+}
+
+// compose записывает в out среднее векторов корзин bs
+func (m *Model) compose(bs []int, out []float64) {
+	clear(out)
+	for _, b := range bs {
+		row := m.Weights[b*m.Dim : (b+1)*m.Dim]
+		for d, v := range row {
+			out[d] += float64(v)
+		}
+	}
+	for d := range out {
+		out[d] /= float64(len(bs))
+	}
+}
+
+// buckets возвращает номера корзин n-грамм слова «<слово>» длиной от MinN до MaxN символов
+func (m *Model) buckets(word string) []int {
+	if word == "" {
+		return nil
+	}
+	runes := []rune("<" + word + ">")
+	var bs []int
+	h := fnv.New32a()
+	for n := m.MinN; n <= m.MaxN; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			h.Reset()
+			h.Write([]byte(string(runes[i : i+n])))
+			bs = append(bs, int(h.Sum32()%uint32(m.Buckets)))
+		}
+	}
+	if len(bs) == 0 {
+		// Слово короче MinN − 2 символов: используется вся строка с маркерами
+		h.Reset()
+		h.Write([]byte(string(runes)))
+		bs = append(bs, int(h.Sum32()%uint32(m.Buckets)))
+	}
+	return bs
+}
+
+// Save сохраняет модель в двоичном формате gob
+func (m *Model) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	if err := gob.NewEncoder(file).Encode(m); err != nil {
+		return fmt.Errorf("ошибка при записи модели n-грамм: %v", err)
+	}
+	return nil
+}
+
+// Load загружает модель, сохранённую Save
+func Load(filename string) (*Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии модели n-грамм: %v", err)
+	}
+	defer file.Close()
+	m := &Model{}
+	if err := gob.NewDecoder(file).Decode(m); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении модели n-грамм: %v", err)
+	}
+	if m.Dim <= 0 || m.Buckets <= 0 || len(m.Weights) != m.Dim*m.Buckets {
+		return nil, fmt.Errorf("файл %s не содержит корректной модели n-грамм", filename)
+	}
+	return m, nil
+}
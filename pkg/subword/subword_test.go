@@ -0,0 +1,126 @@
+package subword
+
+import (
+	"glove-pipeline/pkg/vectors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuckets(t *testing.T) {
+	tests := []struct {
+		name       string
+		word       string
+		minN, maxN int
+		want       int
+	}{
+		{"триграммы", "кот", 3, 3, 3}, // <ко, кот, от>
+		{"от 3 до 6 символов", "кот", 3, 6, 3 + 2 + 1},
+		{"слово короче n-граммы", "я", 5, 6, 1},
+		{"пустое слово", "", 3, 6, 0},
+	}
+	for _, tt := range tests {
+		m := &Model{MinN: tt.minN, MaxN: tt.maxN, Buckets: 1000}
+		bs := m.buckets(tt.word)
+		if len(bs) != tt.want {
+			t.Errorf("%s: buckets(%q) = %d корзин, want %d", tt.name, tt.word, len(bs), tt.want)
+		}
+		for _, b := range bs {
+			if b < 0 || b >= m.Buckets {
+				t.Fatalf("%s: корзина %d вне диапазона", tt.name, b)
+			}
+		}
+	}
+}
+
+// families возвращает модель, в которой слова с общей основой имеют близкие векторы
+func families() *vectors.Model {
+	var words []string
+	var vecs [][]float64
+	for i, family := range [][]string{
+		{"стол", "стола", "столу", "столом", "столе", "столы", "столик", "столики"},
+		{"кошка", "кошки", "кошке", "кошку", "кошкой", "кошек", "кошечка", "кошечки"},
+		{"река", "реки", "реке", "реку", "рекой", "рек", "речка", "речки"},
+	} {
+		for j, word := range family {
+			vec := make([]float64, 3)
+			vec[i] = 1
+			vec[(i+1)%3] = 0.05 * float64(j)
+			words = append(words, word)
+			vecs = append(vecs, vec)
+		}
+	}
+	return vectors.New(words, vecs)
+}
+
+func TestTrain(t *testing.T) {
+	model := families()
+	m, report, err := Train(model, Options{MinN: 3, MaxN: 5, Buckets: 5000, Epochs: 200, LearningRate: 0.5, Seed: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Train < 0.9 {
+		t.Errorf("восстановление обучающих слов %.3f, want ≥ 0.9", report.Train)
+	}
+
+	tests := []struct {
+		word    string
+		nearest string
+	}{
+		{"столов", "стол"},
+		{"кошками", "кошка"},
+		{"речкой", "река"},
+	}
+	for _, tt := range tests {
+		vec, ok := m.Vector(tt.word)
+		if !ok {
+			t.Fatalf("нет вектора для %q", tt.word)
+		}
+		if got := model.Nearest(vec, 1, nil); len(got) == 0 || !strings.HasPrefix(got[0].Word, tt.nearest[:4]) {
+			t.Errorf("ближайшее к %q слово %v, want из семьи %q", tt.word, got, tt.nearest)
+		}
+	}
+	if _, ok := m.Vector(""); ok {
+		t.Error("Vector для пустого слова должен вернуть ok = false")
+	}
+}
+
+func TestAttachAndLoad(t *testing.T) {
+	model := families()
+	m, _, err := Train(model, Options{MinN: 3, MaxN: 4, Buckets: 1000, Epochs: 20, Seed: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "subword.bin")
+	if err := m.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := m.Vector("столов")
+	got, _ := loaded.Vector("столов")
+	for d := range want {
+		if got[d] != want[d] {
+			t.Fatalf("вектор после Load %v, want %v", got, want)
+		}
+	}
+
+	if err := loaded.Attach(model); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := model.Vector("столов"); !ok {
+		t.Error("Vector не построил вектор по n-граммам")
+	}
+	if model.Has("столов") {
+		t.Error("Has сообщает о слове вне модели")
+	}
+	if _, err := model.NearestWords("столов", 3); err != nil {
+		t.Errorf("NearestWords для слова вне модели: %v", err)
+	}
+	other := vectors.New([]string{"a"}, [][]float64{{1, 2}})
+	if err := loaded.Attach(other); err == nil {
+		t.Error("Attach к векторам другой размерности должен вернуть ошибку")
+	}
+}
@@ -0,0 +1,128 @@
+package synonyms
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Format — формат словаря синонимов
+type Format string
+
+// Поддерживаемые форматы
+const (
+	Wordforms     Format = "wordforms"     // Manticore/Sphinx wordforms: "слово > главное_слово"
+	Exceptions    Format = "exceptions"    // Manticore/Sphinx exceptions: "слово => главное_слово"
+	Elasticsearch Format = "elasticsearch" // Настройки фильтра synonym_graph в формате JSON
+	Solr          Format = "solr"          // synonyms.txt для SynonymGraphFilter (Solr, Elasticsearch synonyms_path)
+)
+
+// ParseFormat разбирает название формата
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "wordforms", "manticore", "sphinx":
+		return Wordforms, nil
+	case "exceptions":
+		return Exceptions, nil
+	case "elasticsearch", "es":
+		return Elasticsearch, nil
+	case "solr", "synonyms.txt":
+		return Solr, nil
+	}
+	return "", fmt.Errorf("неизвестный формат словаря: %s (wordforms, exceptions, elasticsearch, solr)", name)
+}
+
+// Extension возвращает расширение файла для формата
+func (f Format) Extension() string {
+	if f == Elasticsearch {
+		return ".json"
+	}
+	return ".txt"
+}
+
+// Write записывает группы в заданном формате. Для Elasticsearch и Solr при equivalent
+// группа записывается как список равнозначных слов ("а, б, в"), иначе — как явное
+// расширение главного слова ("а => а, б, в"). Для wordforms и exceptions группы
+// предварительно делаются непересекающимися.
+func Write(w io.Writer, groups []Group, format Format, equivalent bool) error {
+	switch format {
+	case Wordforms:
+		return writeMapping(w, Disjoint(groups), " > ")
+	case Exceptions:
+		return writeMapping(w, Disjoint(groups), " => ")
+	case Elasticsearch:
+		filter := map[string]interface{}{
+			"type":     "synonym_graph",
+			"lenient":  true,
+			"synonyms": synonymRules(groups, equivalent),
+		}
+		// Без экранирования HTML, иначе "=>" превращается в "=\u003e"
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(filter)
+	case Solr:
+		writer := bufio.NewWriter(w)
+		fmt.Fprintln(writer, "# Синонимы, полученные по соседям в векторной модели GloVe")
+		for _, rule := range synonymRules(groups, equivalent) {
+			fmt.Fprintln(writer, rule)
+		}
+		return writer.Flush()
+	}
+	return fmt.Errorf("неизвестный формат словаря: %s", format)
+}
+
+// Save записывает группы в файл
+func Save(filename string, groups []Group, format Format, equivalent bool) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	if err := Write(file, groups, format, equivalent); err != nil {
+		return fmt.Errorf("ошибка при записи словаря: %v", err)
+	}
+	return nil
+}
+
+// writeMapping записывает отображения "слово<sep>главное_слово"
+func writeMapping(w io.Writer, groups []Group, sep string) error {
+	writer := bufio.NewWriter(w)
+	for _, g := range groups {
+		for _, word := range g.Words {
+			if word == g.Head {
+				continue
+			}
+			writer.WriteString(word + sep + g.Head + "\n")
+		}
+	}
+	return writer.Flush()
+}
+
+// synonymRules формирует правила в синтаксисе Solr; одинаковые наборы равнозначных слов пропускаются
+func synonymRules(groups []Group, equivalent bool) []string {
+	rules := []string{}
+	seen := make(map[string]bool)
+	for _, g := range groups {
+		if len(g.Words) < 2 {
+			continue
+		}
+		if !equivalent {
+			rules = append(rules, g.Head+" => "+strings.Join(g.Words, ", "))
+			continue
+		}
+		key := append([]string(nil), g.Words...)
+		sort.Strings(key)
+		id := strings.Join(key, ",")
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		rules = append(rules, strings.Join(g.Words, ", "))
+	}
+	return rules
+}
@@ -0,0 +1,70 @@
+package synonyms
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var exportGroups = []Group{
+	{Head: "авто", Words: []string{"авто", "машина", "тачка"}},
+	{Head: "машина", Words: []string{"машина", "тачка", "авто"}},
+	{Head: "кот", Words: []string{"кот", "кошка"}},
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format     Format
+		equivalent bool
+		want       string
+	}{
+		{Wordforms, false, "машина > авто\nтачка > авто\nкошка > кот\n"},
+		{Exceptions, false, "машина => авто\nтачка => авто\nкошка => кот\n"},
+		{Solr, true, "# Синонимы, полученные по соседям в векторной модели GloVe\nавто, машина, тачка\nкот, кошка\n"},
+		{Solr, false, "# Синонимы, полученные по соседям в векторной модели GloVe\n" +
+			"авто => авто, машина, тачка\nмашина => машина, тачка, авто\nкот => кот, кошка\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, exportGroups, tt.format, tt.equivalent); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s (equivalent=%v):\n%s\nожидалось:\n%s", tt.format, tt.equivalent, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestWriteElasticsearch(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, exportGroups, Elasticsearch, false); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`\u003e`)) {
+		t.Errorf("правило экранировано: %s", buf.String())
+	}
+	var filter struct {
+		Type     string   `json:"type"`
+		Synonyms []string `json:"synonyms"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &filter); err != nil {
+		t.Fatal(err)
+	}
+	if filter.Type != "synonym_graph" || len(filter.Synonyms) != 3 || filter.Synonyms[2] != "кот => кот, кошка" {
+		t.Errorf("фильтр %+v", filter)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"Manticore": Wordforms, "es": Elasticsearch, "synonyms.txt": Solr, "exceptions": Exceptions} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного формата")
+	}
+	if Elasticsearch.Extension() != ".json" || Solr.Extension() != ".txt" {
+		t.Error("неверные расширения файлов")
+	}
+}
@@ -0,0 +1,198 @@
+package synonyms
+
+import (
+	"bufio"
+	"fmt"
+	"glove-pipeline/pkg/ann"
+	"glove-pipeline/pkg/vectors"
+	"os"
+	"strings"
+)
+
+// Group — группа синонимов: главное слово и близкие к нему слова (главное слово идёт первым)
+type Group struct {
+	Head   string    `json:"head"`
+	Words  []string  `json:"words"`
+	Scores []float64 `json:"scores,omitempty"` // Сходство слов с главным (если известно)
+}
+
+// Options задаёт правила отбора синонимов
+type Options struct {
+	TopK         int             // Число соседей, рассматриваемых для каждого слова
+	Threshold    float64         // Минимальное косинусное сходство соседа
+	MaxGroupSize int             // Максимальный размер группы вместе с главным словом (0 — без ограничения)
+	Mutual       bool            // Оставлять только взаимных соседей
+	Deny         map[string]bool // Слова, которые не попадают в словарь
+	Limit        int             // Сколько первых (самых частых) слов модели рассматривать (0 — все)
+	Exact        bool            // Точный поиск соседей полным перебором вместо HNSW
+	ANN          ann.Config      // Параметры индекса HNSW
+}
+
+// DefaultOptions возвращает параметры по умолчанию
+func DefaultOptions() Options {
+	return Options{TopK: 10, Threshold: 0.7, MaxGroupSize: 10, Limit: 50000, ANN: ann.DefaultConfig()}
+}
+
+// Build строит группы синонимов по соседям слов в векторной модели.
+// Для каждого слова словаря группа содержит его соседей со сходством не ниже порога.
+func Build(model *vectors.Model, opts Options) ([]Group, error) {
+	var words []string
+	var vecs [][]float64
+	for i, word := range model.Words {
+		if opts.Limit > 0 && len(words) >= opts.Limit {
+			break
+		}
+		if opts.Deny[word] {
+			continue
+		}
+		words = append(words, word)
+		vecs = append(vecs, model.Vectors[i])
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("нет слов для построения синонимов")
+	}
+
+	var graph ann.Graph
+	var err error
+	if opts.Exact {
+		graph, err = ann.ExactKNN(vecs, opts.TopK, opts.Threshold)
+	} else {
+		var ix *ann.Index
+		if ix, err = ann.Build(vecs, opts.ANN); err != nil {
+			return nil, err
+		}
+		graph, err = ann.KNN(ix, opts.TopK, opts.Threshold)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mutual {
+		graph = graph.Mutual()
+	}
+
+	var groups []Group
+	for i, neighbors := range graph {
+		if len(neighbors) == 0 {
+			continue
+		}
+		group := Group{Head: words[i], Words: []string{words[i]}, Scores: []float64{1}}
+		for _, n := range neighbors {
+			if opts.MaxGroupSize > 0 && len(group.Words) >= opts.MaxGroupSize {
+				break
+			}
+			group.Words = append(group.Words, words[n.ID])
+			group.Scores = append(group.Scores, n.Similarity)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// Filter применяет к готовым группам (например, из word_groups.txt) список запрещённых слов,
+// порог сходства, фильтр взаимных соседей и ограничение размера. Модель нужна только
+// для порога и фильтра взаимных соседей; без неё эти правила не применяются.
+func Filter(groups []Group, model *vectors.Model, opts Options) []Group {
+	neighbors := make(map[string]map[string]bool)
+	isNeighbor := func(a, b string) bool {
+		if _, ok := neighbors[a]; !ok {
+			neighbors[a] = make(map[string]bool)
+			list, _ := model.NearestWords(a, opts.TopK)
+			for _, n := range list {
+				neighbors[a][n.Word] = true
+			}
+		}
+		return neighbors[a][b]
+	}
+
+	var result []Group
+	for _, g := range groups {
+		head := g.Head
+		if opts.Deny[head] {
+			continue
+		}
+		filtered := Group{Head: head, Words: []string{head}}
+		for _, word := range g.Words {
+			if word == head || opts.Deny[word] {
+				continue
+			}
+			if opts.MaxGroupSize > 0 && len(filtered.Words) >= opts.MaxGroupSize {
+				break
+			}
+			if model != nil {
+				sim, err := model.Similarity(head, word)
+				if err != nil || sim < opts.Threshold {
+					continue
+				}
+				if opts.Mutual && (!isNeighbor(head, word) || !isNeighbor(word, head)) {
+					continue
+				}
+			}
+			filtered.Words = append(filtered.Words, word)
+		}
+		if len(filtered.Words) > 1 {
+			result = append(result, filtered)
+		}
+	}
+	return result
+}
+
+// Disjoint делает группы непересекающимися: каждое слово остаётся только в первой
+// группе, где оно встретилось. Нужно для форматов, где слово отображается
+// ровно в одну нормальную форму (wordforms, exceptions).
+func Disjoint(groups []Group) []Group {
+	used := make(map[string]bool)
+	var result []Group
+	for _, g := range groups {
+		if used[g.Head] {
+			continue
+		}
+		group := Group{Head: g.Head, Words: []string{g.Head}}
+		for _, word := range g.Words {
+			if word == g.Head || used[word] {
+				continue
+			}
+			group.Words = append(group.Words, word)
+		}
+		if len(group.Words) < 2 {
+			continue
+		}
+		for _, word := range group.Words {
+			used[word] = true
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+// LoadGroups читает группы слов в формате word_groups.txt: слова группы через запятую,
+// первое слово считается главным. Группы из одного слова пропускаются.
+func LoadGroups(filename string) ([]Group, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла групп: %v", err)
+	}
+	defer file.Close()
+
+	var groups []Group
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var words []string
+		seen := make(map[string]bool)
+		for _, word := range strings.Split(scanner.Text(), ",") {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if word == "" || seen[word] {
+				continue
+			}
+			seen[word] = true
+			words = append(words, word)
+		}
+		if len(words) > 1 {
+			groups = append(groups, Group{Head: words[0], Words: words})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла групп: %v", err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,136 @@
+package synonyms
+
+import (
+	"glove-pipeline/pkg/vectors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testModel — две группы близких слов и одно слово без близких соседей
+func testModel() *vectors.Model {
+	return vectors.New(
+		[]string{"авто", "машина", "автомобиль", "кот", "кошка", "стол"},
+		[][]float64{
+			{1, 0.1, 0},
+			{1, 0.2, 0},
+			{1, 0, 0.1},
+			{0, 1, 0.1},
+			{0.1, 1, 0},
+			{0, 0, 1},
+		},
+	)
+}
+
+func TestBuild(t *testing.T) {
+	for _, exact := range []bool{true, false} {
+		opts := DefaultOptions()
+		opts.Exact = exact
+		opts.Threshold = 0.9
+		opts.Deny = map[string]bool{"автомобиль": true}
+		groups, err := Build(testModel(), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string][]string)
+		for _, g := range groups {
+			got[g.Head] = g.Words
+			if len(g.Scores) != len(g.Words) || g.Scores[0] != 1 {
+				t.Errorf("exact=%v: оценки группы %s: %v", exact, g.Head, g.Scores)
+			}
+		}
+		want := map[string][]string{
+			"авто":   {"авто", "машина"},
+			"машина": {"машина", "авто"},
+			"кот":    {"кот", "кошка"},
+			"кошка":  {"кошка", "кот"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("exact=%v: группы %v, ожидалось %v", exact, got, want)
+		}
+	}
+}
+
+func TestBuildLimits(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Exact = true
+	opts.Threshold = 0.5
+	opts.MaxGroupSize = 2
+	opts.Limit = 3 // Только авто, машина, автомобиль
+	groups, err := Build(testModel(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("групп %d: %+v", len(groups), groups)
+	}
+	for _, g := range groups {
+		if len(g.Words) != 2 {
+			t.Errorf("размер группы %s: %v", g.Head, g.Words)
+		}
+	}
+
+	opts.Deny = map[string]bool{"кот": true}
+	if _, err := Build(vectors.New([]string{"кот"}, [][]float64{{1}}), opts); err == nil {
+		t.Error("ожидалась ошибка для пустого словаря")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	groups := []Group{
+		{Head: "авто", Words: []string{"авто", "машина", "стол", "автомобиль"}},
+		{Head: "кот", Words: []string{"кот", "нет_в_модели"}},
+		{Head: "стол", Words: []string{"стол", "кошка"}},
+	}
+	opts := DefaultOptions()
+	opts.Threshold = 0.9
+	opts.Deny = map[string]bool{"автомобиль": true}
+
+	got := Filter(groups, testModel(), opts)
+	want := []Group{{Head: "авто", Words: []string{"авто", "машина"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter = %+v, ожидалось %+v", got, want)
+	}
+
+	// Без модели действуют только запреты и размер групп
+	opts.MaxGroupSize = 2
+	got = Filter(groups, nil, opts)
+	if len(got) != 3 || !reflect.DeepEqual(got[0].Words, []string{"авто", "машина"}) {
+		t.Errorf("Filter без модели = %+v", got)
+	}
+}
+
+func TestDisjoint(t *testing.T) {
+	groups := []Group{
+		{Head: "авто", Words: []string{"авто", "машина"}},
+		{Head: "машина", Words: []string{"машина", "авто"}},
+		{Head: "тачка", Words: []string{"тачка", "авто", "машина", "колымага"}},
+	}
+	got := Disjoint(groups)
+	want := []Group{
+		{Head: "авто", Words: []string{"авто", "машина"}},
+		{Head: "тачка", Words: []string{"тачка", "колымага"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Disjoint = %+v, ожидалось %+v", got, want)
+	}
+}
+
+func TestLoadGroups(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "word_groups.txt")
+	if err := os.WriteFile(filename, []byte("Авто, машина, авто\nодно\n\nкот,кошка\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := LoadGroups(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Group{
+		{Head: "авто", Words: []string{"авто", "машина"}},
+		{Head: "кот", Words: []string{"кот", "кошка"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("LoadGroups = %+v, ожидалось %+v", groups, want)
+	}
+}
@@ -0,0 +1,99 @@
+package textprocessor
+
+import (
+	"encoding/csv"
+	"fmt"
+	"glove-pipeline/pkg/corpus"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CSVReader читает CSV-файл построчно и возвращает очищенные документы с метаданными
+type CSVReader struct {
+	file    *os.File
+	reader  *csv.Reader
+	columns columnIndexes
+	row     int
+}
+
+// OpenCSV открывает CSV-файл и определяет столбцы по заголовку
+func OpenCSV(inputFile string, columns Columns) (*CSVReader, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла: %v", err)
+	}
+
+	reader := csv.NewReader(file)
+	reader.Comma = ','       // Указываем разделитель
+	reader.LazyQuotes = true // Разрешаем "ленивые" кавычки
+
+	header, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("ошибка при чтении заголовка CSV: %v", err)
+	}
+	return &CSVReader{file: file, reader: reader, columns: resolveColumns(header, columns)}, nil
+}
+
+// Next возвращает следующий документ с непустым очищенным текстом или io.EOF.
+// Если в CSV нет столбца с идентификатором, им служит номер строки данных.
+func (r *CSVReader) Next() (corpus.Document, error) {
+	for {
+		record, err := r.reader.Read()
+		if err == io.EOF {
+			return corpus.Document{}, io.EOF
+		}
+		if err != nil {
+			return corpus.Document{}, fmt.Errorf("ошибка при чтении CSV: %v", err)
+		}
+		r.row++
+
+		// Текст находится в первом столбце, если заголовок не указывает иное
+		if len(record) <= r.columns.text {
+			continue
+		}
+		cleanedText := RemoveExcessNewlines(CleanText(record[r.columns.text]))
+		if cleanedText == "" {
+			continue
+		}
+
+		id := field(record, r.columns.id)
+		if id == "" {
+			id = strconv.Itoa(r.row)
+		}
+		return corpus.Document{
+			ID:        id,
+			Timestamp: corpus.NormalizeTime(field(record, r.columns.timestamp)),
+			Source:    field(record, r.columns.source),
+			Author:    field(record, r.columns.author),
+			Label:     field(record, r.columns.label),
+			Text:      cleanedText,
+		}, nil
+	}
+}
+
+// Row возвращает номер последней прочитанной строки данных (без заголовка)
+func (r *CSVReader) Row() int {
+	return r.row
+}
+
+// Close закрывает CSV-файл
+func (r *CSVReader) Close() error {
+	return r.file.Close()
+}
+
+// DocumentReader — источник документов: CSV-файл или корпус
+type DocumentReader interface {
+	Next() (corpus.Document, error) // Следующий документ или io.EOF
+	Close() error
+}
+
+// OpenDocuments открывает CSV (по расширению .csv; тексты очищаются) или корпус JSONL/текст
+func OpenDocuments(filename string, columns Columns) (DocumentReader, error) {
+	if strings.HasSuffix(strings.ToLower(filename), ".csv") {
+		return OpenCSV(filename, columns)
+	}
+	return corpus.Open(filename)
+}
@@ -1,11 +1,13 @@
 package textprocessor
 
 import (
-	"encoding/csv"
 	"fmt"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/langid"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"unicode"
@@ -34,8 +36,8 @@ func CleanText(text string) string {
 	text = re.ReplaceAllString(text, " ")
 
 	// Удаление пунктуации и специальных символов (оставляем только буквы, цифры и пробелы)
-	// Сохраняем букву ё (Ё) явно в регулярном выражении
-	re = regexp.MustCompile(`[^a-zA-Zа-яА-ЯёЁ0-9\s]`)
+	// Сохраняем букву ё (Ё) и украинские буквы і, ї, є, ґ явно в регулярном выражении
+	re = regexp.MustCompile(`[^a-zA-Zа-яА-ЯёЁіІїЇєЄґҐ0-9\s]`)
 	text = re.ReplaceAllString(text, " ")
 
 	// Приведение текста к нижнему регистру с сохранением буквы ё
@@ -65,19 +67,158 @@ func RemoveExcessNewlines(text string) string {
 	return text
 }
 
+// RemovePunctuation удаляет знаки пунктуации из текста
+func RemovePunctuation(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// RemoveStopWords удаляет стоп-слова из текста
+func RemoveStopWords(text string, stopWords map[string]struct{}) string {
+	words := strings.Fields(text)
+	var filteredWords []string
+	for _, word := range words {
+		if _, isStopWord := stopWords[word]; !isStopWord {
+			filteredWords = append(filteredWords, word)
+		}
+	}
+	return strings.Join(filteredWords, " ")
+}
+
+// CleanPhrase подготавливает поисковую фразу: приводит к нижнему регистру,
+// удаляет пунктуацию и стоп-слова
+func CleanPhrase(text string, stopWords map[string]struct{}) string {
+	text = strings.ToLower(text)
+	text = RemovePunctuation(text)
+	return RemoveStopWords(text, stopWords)
+}
+
+// Truncate обрезает текст до limit символов вместе с многоточием, по возможности
+// по границе слова; при limit ≤ 0 текст возвращается целиком
+func Truncate(text string, limit int) string {
+	runes := []rune(text)
+	if limit <= 0 || len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
+
+// Options задаёт дополнительные режимы обработки CSV-файла
+type Options struct {
+	// Languages — коды языков, которые остаются в корпусе (пусто — все языки)
+	Languages []string
+	// SplitDir — если задан, корпус дополнительно раскладывается по языкам
+	// в файлы SplitDir/<язык>/cleaned_corpus.txt
+	SplitDir string
+	// MinConfidence — минимальная уверенность определения языка;
+	// тексты с меньшей уверенностью считаются текстами неизвестного языка
+	MinConfidence float64
+	// Identifier — классификатор языка; если не задан, создаётся со встроенными профилями
+	Identifier *langid.Identifier
+	// JSONLFile — если задан, очищенные документы дополнительно сохраняются
+	// в формате JSONL вместе с метаданными (id, дата, источник, автор, язык)
+	JSONLFile string
+	// Columns — имена столбцов CSV; незаданные столбцы определяются по заголовку.
+	// Если не задано ни одно имя, текстом, как и прежде, служит первый столбец,
+	// а по заголовку определяются только метаданные
+	Columns Columns
+}
+
+// Columns задаёт имена столбцов CSV с текстом и метаданными документа
+type Columns struct {
+	Text      string
+	ID        string
+	Timestamp string
+	Source    string
+	Author    string
+	Label     string
+}
+
+// Распространённые имена столбцов, по которым метаданные находятся автоматически
+var columnAliases = map[string][]string{
+	"text":      {"text", "message", "content", "body", "текст"},
+	"id":        {"id", "message_id", "post_id", "msg_id"},
+	"timestamp": {"timestamp", "date", "datetime", "created_at", "published_at", "time", "дата"},
+	"source":    {"source", "channel", "chat", "chat_name", "channel_name", "источник"},
+	"author":    {"author", "user", "username", "from", "sender", "автор"},
+	"label":     {"label", "class", "category", "метка", "класс"},
+}
+
+// columnIndexes — номера столбцов CSV (-1, если столбец отсутствует)
+type columnIndexes struct {
+	text, id, timestamp, source, author, label int
+}
+
+// resolveColumns находит номера столбцов по заголовку CSV.
+// Если столбец с текстом не найден, текстом считается первый столбец.
+func resolveColumns(header []string, columns Columns) columnIndexes {
+	find := func(name, field string) int {
+		candidates := columnAliases[field]
+		if name != "" {
+			candidates = []string{name}
+		}
+		for _, candidate := range candidates {
+			for i, h := range header {
+				if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\uFEFF")), candidate) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	idx := columnIndexes{
+		text:      find(columns.Text, "text"),
+		id:        find(columns.ID, "id"),
+		timestamp: find(columns.Timestamp, "timestamp"),
+		source:    find(columns.Source, "source"),
+		author:    find(columns.Author, "author"),
+		label:     find(columns.Label, "label"),
+	}
+	if idx.text < 0 {
+		idx.text = 0
+	}
+	return idx
+}
+
+// field возвращает значение столбца или пустую строку, если столбца нет
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// detectLanguage возвращает true, если для опций требуется определение языка
+func (o Options) detectLanguage() bool {
+	return len(o.Languages) > 0 || o.SplitDir != ""
+}
+
 // ProcessCSV обрабатывает CSV-файл, очищает текст и сохраняет результат в файл
 func ProcessCSV(inputFile, outputFile string) error {
-	// Открытие CSV-файла
-	file, err := os.Open(inputFile)
+	return ProcessCSVWithOptions(inputFile, outputFile, Options{})
+}
+
+// ProcessCSVWithOptions обрабатывает CSV-файл с учётом опций: фильтрует тексты
+// по языку и при необходимости раскладывает корпус по языковым файлам
+func ProcessCSVWithOptions(inputFile, outputFile string, opts Options) error {
+	// Открытие CSV-файла и определение столбцов по заголовку
+	reader, err := OpenCSV(inputFile, opts.Columns)
 	if err != nil {
-		return fmt.Errorf("ошибка при открытии файла: %v", err)
+		return err
+	}
+	defer reader.Close()
+	if opts.Columns == (Columns{}) {
+		reader.columns.text = 0
 	}
-	defer file.Close()
-
-	// Создание CSV-ридера
-	reader := csv.NewReader(file)
-	reader.Comma = ','       // Указываем разделитель
-	reader.LazyQuotes = true // Разрешаем "ленивые" кавычки
 
 	// Открытие файла для записи очищенного текста
 	output, err := os.Create(outputFile)
@@ -86,38 +227,131 @@ func ProcessCSV(inputFile, outputFile string) error {
 	}
 	defer output.Close()
 
-	// Пропуск первой строки (заголовка)
-	_, err = reader.Read()
-	if err != nil {
-		return fmt.Errorf("ошибка при чтении заголовка CSV: %v", err)
+	// Подготовка классификатора языка
+	identifier := opts.Identifier
+	if identifier == nil && opts.detectLanguage() {
+		identifier, err = langid.New()
+		if err != nil {
+			return fmt.Errorf("ошибка при создании классификатора языка: %v", err)
+		}
+	}
+	allowed := make(map[string]bool)
+	for _, lang := range opts.Languages {
+		allowed[lang] = true
+	}
+	splitter := newLanguageSplitter(opts.SplitDir)
+	defer splitter.Close()
+	langStats := make(map[string]int)
+
+	// Открытие файла для записи корпуса с метаданными
+	var jsonl *corpus.Writer
+	if opts.JSONLFile != "" {
+		jsonl, err = corpus.Create(opts.JSONLFile)
+		if err != nil {
+			return err
+		}
+		defer jsonl.Close()
 	}
 
 	// Чтение и обработка данных
 	for {
-		record, err := reader.Read()
+		doc, err := reader.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("ошибка при чтении CSV: %v", err)
+			return err
+		}
+
+		// Определение языка и фильтрация
+		lang := langid.Unknown
+		if identifier != nil {
+			result := identifier.Detect(doc.Text)
+			if result.Confidence >= opts.MinConfidence {
+				lang = result.Lang
+			}
+			langStats[lang]++
+			if len(allowed) > 0 && !allowed[lang] {
+				continue
+			}
 		}
 
-		// Предполагаем, что текст находится в первом столбце
-		if len(record) > 0 {
-			text := record[0]
-			cleanedText := CleanText(text)
-			cleanedText = RemoveExcessNewlines(cleanedText)
+		// Запись в файл
+		if _, err := output.WriteString(doc.Text + "\n"); err != nil {
+			return fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+		if err := splitter.Write(lang, doc.Text); err != nil {
+			return err
+		}
 
-			// Запись в файл, если текст не пустой
-			if cleanedText != "" {
-				_, err := output.WriteString(cleanedText + "\n")
-				if err != nil {
-					return fmt.Errorf("ошибка при записи в файл: %v", err)
-				}
+		// Запись документа с метаданными
+		if jsonl != nil {
+			if lang != langid.Unknown {
+				doc.Lang = lang
+			}
+			if err := jsonl.Write(doc); err != nil {
+				return err
 			}
 		}
 	}
 
+	if jsonl != nil {
+		if err := jsonl.Close(); err != nil {
+			return err
+		}
+		log.Printf("Корпус с метаданными сохранен в файл %s\n", opts.JSONLFile)
+	}
+
+	for lang, count := range langStats {
+		log.Printf("Язык %s: %d текстов\n", lang, count)
+	}
 	log.Printf("Очищенный корпус сохранен в файл %s\n", outputFile)
 	return nil
 }
+
+// languageSplitter раскладывает очищенные тексты по файлам языков
+type languageSplitter struct {
+	dir   string
+	files map[string]*os.File
+}
+
+func newLanguageSplitter(dir string) *languageSplitter {
+	return &languageSplitter{dir: dir, files: make(map[string]*os.File)}
+}
+
+// Write дописывает текст в корпус языка lang, создавая файл при первой записи
+func (s *languageSplitter) Write(lang, text string) error {
+	if s.dir == "" || lang == langid.Unknown {
+		return nil
+	}
+	file, ok := s.files[lang]
+	if !ok {
+		path := LanguageCorpusPath(s.dir, lang)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("ошибка при создании каталога: %v", err)
+		}
+		var err error
+		file, err = os.Create(path)
+		if err != nil {
+			return fmt.Errorf("ошибка при создании файла: %v", err)
+		}
+		s.files[lang] = file
+	}
+	if _, err := file.WriteString(text + "\n"); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// Close закрывает все открытые файлы языков
+func (s *languageSplitter) Close() {
+	for lang, file := range s.files {
+		file.Close()
+		log.Printf("Корпус языка %s сохранен в файл %s\n", lang, file.Name())
+	}
+}
+
+// LanguageCorpusPath возвращает путь к очищенному корпусу языка внутри каталога dir
+func LanguageCorpusPath(dir, lang string) string {
+	return filepath.Join(dir, lang, "cleaned_corpus.txt")
+}
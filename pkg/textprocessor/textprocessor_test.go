@@ -0,0 +1,127 @@
+package textprocessor
+
+import (
+	"glove-pipeline/pkg/corpus"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// processCSV очищает CSV-файл с заданным содержимым и возвращает очищенный
+// корпус и документы корпуса JSONL
+func processCSV(t *testing.T, content string, opts Options) (string, []corpus.Document) {
+	t.Helper()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "cleaned.txt")
+	opts.JSONLFile = filepath.Join(dir, "corpus.jsonl")
+	if err := ProcessCSVWithOptions(input, output, opts); err != nil {
+		t.Fatal(err)
+	}
+	cleaned, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := corpus.ReadAll(opts.JSONLFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(cleaned), docs
+}
+
+func TestProcessCSVMetadata(t *testing.T) {
+	cleaned, docs := processCSV(t, "text,message_id,date,channel,author\n"+
+		"\"Привет, <b>мир</b>! https://example.com\",42,01.03.2024,новости,анна\n"+
+		"\"!!!\",43,2024-03-02,новости,борис\n"+
+		"Второй пост,,,,\n", Options{})
+	if cleaned != "привет мир\nвторой пост\n" {
+		t.Errorf("очищенный корпус %q", cleaned)
+	}
+	want := []corpus.Document{
+		{ID: "42", Timestamp: "2024-03-01T00:00:00Z", Source: "новости", Author: "анна", Text: "привет мир"},
+		{ID: "3", Text: "второй пост"},
+	}
+	if len(docs) != len(want) {
+		t.Fatalf("прочитано %d документов, ожидалось %d: %+v", len(docs), len(want), docs)
+	}
+	for i := range want {
+		if docs[i] != want[i] {
+			t.Errorf("документ %d: %+v, ожидалось %+v", i, docs[i], want[i])
+		}
+	}
+}
+
+func TestProcessCSVFirstColumn(t *testing.T) {
+	// Без Options.Columns текстом служит первый столбец, даже если в заголовке есть message
+	cleaned, docs := processCSV(t, "body,message,author\nпервый столбец,второй столбец,анна\n", Options{})
+	if cleaned != "первый столбец\n" {
+		t.Errorf("очищенный корпус %q", cleaned)
+	}
+	if len(docs) != 1 || docs[0].Author != "анна" {
+		t.Errorf("документы %+v", docs)
+	}
+	// С заданным именем столбца текст берётся из него
+	cleaned, _ = processCSV(t, "body,message\nпервый,второй\n", Options{Columns: Columns{Text: "message"}})
+	if cleaned != "второй\n" {
+		t.Errorf("очищенный корпус с -text-column %q", cleaned)
+	}
+}
+
+func TestProcessCSVExplicitColumns(t *testing.T) {
+	_, docs := processCSV(t, "id,title,body,who\n1,заголовок,Текст поста,автор\n",
+		Options{Columns: Columns{Text: "body", Author: "WHO"}})
+	if len(docs) != 1 {
+		t.Fatalf("прочитано %d документов", len(docs))
+	}
+	if docs[0].Text != "текст поста" || docs[0].Author != "автор" || docs[0].ID != "1" {
+		t.Errorf("документ %+v", docs[0])
+	}
+}
+
+func TestProcessCSVLanguages(t *testing.T) {
+	split := t.TempDir()
+	cleaned, docs := processCSV(t, "text\n"+
+		"Сегодня в городе прошёл большой концерт под открытым небом\n"+
+		"Сьогодні в місті відбувся великий концерт просто неба\n"+
+		"A big open air concert took place in the city today\n",
+		Options{Languages: []string{"ru", "uk"}, SplitDir: split})
+	if len(docs) != 2 || docs[0].Lang != "ru" || docs[1].Lang != "uk" {
+		t.Fatalf("документы корпуса %+v", docs)
+	}
+	if cleaned != docs[0].Text+"\n"+docs[1].Text+"\n" {
+		t.Errorf("очищенный корпус %q", cleaned)
+	}
+	for _, lang := range []string{"ru", "uk"} {
+		data, err := os.ReadFile(LanguageCorpusPath(split, lang))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) == 0 {
+			t.Errorf("пустой корпус языка %s", lang)
+		}
+	}
+	if _, err := os.Stat(LanguageCorpusPath(split, "en")); err == nil {
+		t.Error("отфильтрованный язык en попал в разбиение")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"короткий текст", 20, "короткий текст"},
+		{"длинный текст для обрезки", 0, "длинный текст для обрезки"},
+		{"длинный текст для обрезки", 15, "длинный текст…"},
+		{"оченьдлинноеслово", 6, "очень…"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.text, tt.limit); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, ожидалось %q", tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
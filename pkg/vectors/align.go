@@ -0,0 +1,221 @@
+package vectors
+
+import (
+	"bufio"
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"math"
+	"os"
+	"strings"
+)
+
+// Pair — пара соответствующих слов: слово выравниваемой модели и слово базовой модели
+type Pair struct {
+	Source string
+	Target string
+}
+
+// AlignOptions задаёт якоря для выравнивания моделей
+type AlignOptions struct {
+	// Anchors — слова-якоря, одинаковые в обеих моделях
+	Anchors []string
+	// Dictionary — пары слов (например, двуязычный словарь); используются вместе с Anchors
+	Dictionary []Pair
+	// Holdout — доля пар, которые не участвуют в обучении и служат для оценки ошибки (0..1)
+	Holdout float64
+	// Center — вычесть из нормированных векторов каждой модели средний вектор
+	// обучающих якорей до поворота. Общее для всех слов направление тогда не
+	// влияет на поворот, а возвращаемая модель лежит в центрированном
+	// пространстве base и состоит из единичных векторов
+	Center bool
+}
+
+// AlignReport содержит оценку качества выравнивания
+type AlignReport struct {
+	Pairs        int     `json:"pairs"`         // Число пар, использованных для обучения
+	Missing      int     `json:"missing"`       // Число пар, отсутствующих в одной из моделей
+	HeldOut      int     `json:"held_out"`      // Число отложенных пар
+	CosineBefore float64 `json:"cosine_before"` // Среднее сходство пар до выравнивания
+	CosineAfter  float64 `json:"cosine_after"`  // Среднее сходство пар после выравнивания
+	RMSE         float64 `json:"rmse"`          // Среднеквадратичная ошибка на нормированных векторах
+	HeldOutCos   float64 `json:"held_out_cosine,omitempty"`
+	HeldOutRMSE  float64 `json:"held_out_rmse,omitempty"`
+}
+
+// Align выравнивает модель other по модели base ортогональным преобразованием Прокруста.
+// Если якоря не заданы, якорями служат все общие слова моделей.
+// Возвращает новую модель со словарём other в пространстве base и отчёт об ошибке.
+func Align(base, other *Model, opts AlignOptions) (*Model, *AlignReport, error) {
+	if base.Dim() != other.Dim() {
+		return nil, nil, fmt.Errorf("размерности моделей не совпадают: %d и %d", base.Dim(), other.Dim())
+	}
+
+	pairs := make([]Pair, 0, len(opts.Anchors)+len(opts.Dictionary))
+	for _, word := range opts.Anchors {
+		pairs = append(pairs, Pair{Source: word, Target: word})
+	}
+	pairs = append(pairs, opts.Dictionary...)
+	if len(pairs) == 0 {
+		for _, word := range other.Words {
+			if base.Has(word) {
+				pairs = append(pairs, Pair{Source: word, Target: word})
+			}
+		}
+	}
+
+	// Отбор пар, присутствующих в обеих моделях, и разделение на обучающие и отложенные
+	report := &AlignReport{}
+	var train, test []Pair
+	step := 0
+	if opts.Holdout > 0 && opts.Holdout < 1 {
+		step = int(math.Round(1 / opts.Holdout))
+	}
+	for _, p := range pairs {
+		if !other.Has(p.Source) || !base.Has(p.Target) {
+			report.Missing++
+			continue
+		}
+		if step > 0 && (len(train)+len(test))%step == step-1 {
+			test = append(test, p)
+		} else {
+			train = append(train, p)
+		}
+	}
+	if len(train) == 0 {
+		return nil, nil, fmt.Errorf("нет общих якорных слов для выравнивания")
+	}
+	report.Pairs = len(train)
+	report.HeldOut = len(test)
+
+	var srcMean, dstMean []float64
+	if opts.Center {
+		srcMean, dstMean = anchorMeans(other, base, train)
+	}
+	x, y := pairMatrices(other, base, train, srcMean, dstMean)
+	report.CosineBefore = meanCosine(x, y)
+
+	w := linalg.OrthogonalProcrustes(x, y)
+	rotated := linalg.Multiply(x, w)
+	report.CosineAfter = meanCosine(rotated, y)
+	report.RMSE = rmse(rotated, y)
+
+	if len(test) > 0 {
+		tx, ty := pairMatrices(other, base, test, srcMean, dstMean)
+		tr := linalg.Multiply(tx, w)
+		report.HeldOutCos = meanCosine(tr, ty)
+		report.HeldOutRMSE = rmse(tr, ty)
+	}
+
+	// Поворот переносит все слова other в пространство base; без центрирования
+	// длины векторов сохраняются
+	aligned := make([][]float64, len(other.Vectors))
+	for i, vec := range other.Vectors {
+		if opts.Center {
+			vec = centerVector(vec, srcMean)
+		}
+		aligned[i] = linalg.MultiplyVector(vec, w)
+	}
+	return New(other.Words, aligned), report, nil
+}
+
+// pairMatrices собирает нормированные векторы пар: строки x из src, строки y из dst.
+// Если средние заданы, векторы центрируются ими и нормируются повторно.
+func pairMatrices(src, dst *Model, pairs []Pair, srcMean, dstMean []float64) (x, y [][]float64) {
+	x = make([][]float64, len(pairs))
+	y = make([][]float64, len(pairs))
+	for i, p := range pairs {
+		vx, _ := src.Vector(p.Source)
+		vy, _ := dst.Vector(p.Target)
+		x[i] = centerVector(vx, srcMean)
+		y[i] = centerVector(vy, dstMean)
+	}
+	return x, y
+}
+
+// anchorMeans возвращает средние нормированные векторы якорей в src и dst
+func anchorMeans(src, dst *Model, pairs []Pair) (srcMean, dstMean []float64) {
+	x, y := pairMatrices(src, dst, pairs, nil, nil)
+	return Mean(x), Mean(y)
+}
+
+// centerVector нормирует вектор, вычитает из него mean (если задан) и нормирует снова
+func centerVector(vec, mean []float64) []float64 {
+	result := Normalize(vec)
+	if mean == nil {
+		return result
+	}
+	for k := range result {
+		result[k] -= mean[k]
+	}
+	return Normalize(result)
+}
+
+func meanCosine(x, y [][]float64) float64 {
+	var sum float64
+	for i := range x {
+		sum += CosineSimilarity(x[i], y[i])
+	}
+	return sum / float64(len(x))
+}
+
+func rmse(x, y [][]float64) float64 {
+	var sum float64
+	for i := range x {
+		for k := range x[i] {
+			d := x[i][k] - y[i][k]
+			sum += d * d
+		}
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
+
+// LoadWords загружает список слов (по слову на строку, строки с # пропускаются)
+func LoadWords(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла: %v", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, strings.Fields(word)[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла: %v", err)
+	}
+	return words, nil
+}
+
+// LoadDictionary загружает словарь пар "слово_выравниваемой_модели слово_базовой_модели"
+// (через пробел или табуляцию, по паре на строку)
+func LoadDictionary(filename string) ([]Pair, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла словаря: %v", err)
+	}
+	defer file.Close()
+
+	var pairs []Pair
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			continue
+		}
+		pairs = append(pairs, Pair{Source: parts[0], Target: parts[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла словаря: %v", err)
+	}
+	return pairs, nil
+}
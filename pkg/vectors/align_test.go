@@ -0,0 +1,176 @@
+package vectors
+
+import (
+	"glove-pipeline/pkg/linalg"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// rotatedModels возвращает случайную модель и её копию, повёрнутую известной
+// ортогональной матрицей
+func rotatedModels(n, dim int, seed int64) (base, rotated *Model, rotation [][]float64) {
+	rng := rand.New(rand.NewSource(seed))
+	words := make([]string, n)
+	vecs := make([][]float64, n)
+	for i := range words {
+		words[i] = "w" + strconv.Itoa(i)
+		vecs[i] = make([]float64, dim)
+		for k := range vecs[i] {
+			vecs[i][k] = rng.NormFloat64()
+		}
+	}
+	// Ортогональная матрица — Грам — Шмидт по строкам случайной матрицы
+	rotation = make([][]float64, dim)
+	for i := range rotation {
+		rotation[i] = make([]float64, dim)
+		for k := range rotation[i] {
+			rotation[i][k] = rng.NormFloat64()
+		}
+		for j := 0; j < i; j++ {
+			proj := linalg.Dot(rotation[i], rotation[j])
+			for k := range rotation[i] {
+				rotation[i][k] -= proj * rotation[j][k]
+			}
+		}
+		rotation[i] = Normalize(rotation[i])
+	}
+	return New(words, vecs), New(words, linalg.Multiply(vecs, rotation)), rotation
+}
+
+func TestAlignRecoversRotation(t *testing.T) {
+	base, rotated, _ := rotatedModels(60, 8, 1)
+	aligned, report, err := Align(base, rotated, AlignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pairs != 60 || report.Missing != 0 {
+		t.Errorf("пар %d, отсутствует %d", report.Pairs, report.Missing)
+	}
+	if report.CosineBefore > 0.9 {
+		t.Errorf("сходство до выравнивания %.3f — поворот слишком мал для проверки", report.CosineBefore)
+	}
+	if report.CosineAfter < 1-1e-9 || report.RMSE > 1e-6 {
+		t.Errorf("после выравнивания сходство %.9f, RMSE %g", report.CosineAfter, report.RMSE)
+	}
+	for i, word := range base.Words {
+		got, _ := aligned.Vector(word)
+		for k, v := range base.Vectors[i] {
+			if math.Abs(got[k]-v) > 1e-6 {
+				t.Fatalf("вектор %s после выравнивания %v, ожидался %v", word, got, base.Vectors[i])
+			}
+		}
+	}
+}
+
+func TestAlignAnchorsAndHoldout(t *testing.T) {
+	base, rotated, _ := rotatedModels(50, 5, 2)
+	// Слово вне якорей получает тот же поворот, что и якоря
+	anchors := append([]string{"нет_в_модели"}, base.Words[:40]...)
+	aligned, report, err := Align(base, rotated, AlignOptions{Anchors: anchors, Holdout: 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Missing != 1 || report.Pairs+report.HeldOut != 40 || report.HeldOut != 10 {
+		t.Errorf("отчёт %+v", report)
+	}
+	if report.HeldOutCos < 1-1e-9 {
+		t.Errorf("сходство отложенных пар %.9f", report.HeldOutCos)
+	}
+	got, _ := aligned.Vector("w45")
+	if CosineSimilarity(got, base.Vectors[45]) < 1-1e-9 {
+		t.Errorf("слово вне якорей выровнено неверно")
+	}
+}
+
+func TestAlignCenter(t *testing.T) {
+	base, rotated, _ := rotatedModels(40, 6, 3)
+	aligned, report, err := Align(base, rotated, AlignOptions{Center: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CosineAfter < 1-1e-9 {
+		t.Errorf("сходство после выравнивания %.9f", report.CosineAfter)
+	}
+	// Выровненные векторы совпадают с центрированными единичными векторами базы
+	normed := make([][]float64, base.Len())
+	for i, vec := range base.Vectors {
+		normed[i] = Normalize(vec)
+	}
+	mean := Mean(normed)
+	for i, word := range base.Words {
+		want := make([]float64, base.Dim())
+		for k := range want {
+			want[k] = normed[i][k] - mean[k]
+		}
+		want = Normalize(want)
+		got := mustVector(t, aligned, word)
+		for k := range want {
+			if math.Abs(got[k]-want[k]) > 1e-6 {
+				t.Fatalf("вектор %s после выравнивания %v, ожидался %v", word, got, want)
+			}
+		}
+	}
+}
+
+func TestAlignDictionary(t *testing.T) {
+	base := New([]string{"cat", "dog", "sun"}, [][]float64{{1, 0}, {0, 1}, {1, 1}})
+	// В другой модели оси переставлены, а слова — переводы
+	other := New([]string{"кот", "пёс", "солнце"}, [][]float64{{0, 1}, {1, 0}, {1, 1}})
+	dict := []Pair{{Source: "кот", Target: "cat"}, {Source: "пёс", Target: "dog"}}
+	aligned, report, err := Align(base, other, AlignOptions{Dictionary: dict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CosineAfter < 1-1e-9 {
+		t.Errorf("сходство после выравнивания %.6f", report.CosineAfter)
+	}
+	if sim := CosineSimilarity(mustVector(t, aligned, "солнце"), mustVector(t, base, "sun")); sim < 1-1e-9 {
+		t.Errorf("сходство солнце/sun после выравнивания %.6f", sim)
+	}
+}
+
+func TestAlignErrors(t *testing.T) {
+	a := New([]string{"x"}, [][]float64{{1, 0}})
+	if _, _, err := Align(a, New([]string{"x"}, [][]float64{{1, 0, 0}}), AlignOptions{}); err == nil {
+		t.Error("ожидалась ошибка для разных размерностей")
+	}
+	if _, _, err := Align(a, New([]string{"y"}, [][]float64{{0, 1}}), AlignOptions{}); err == nil {
+		t.Error("ожидалась ошибка для моделей без общих слов")
+	}
+}
+
+func TestLoadDictionary(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dict.txt")
+	content := "# перевод\nкот\tcat\n\nпёс dog лишнее\nодно\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pairs, err := LoadDictionary(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Pair{{"кот", "cat"}, {"пёс", "dog"}}
+	if len(pairs) != len(want) || pairs[0] != want[0] || pairs[1] != want[1] {
+		t.Errorf("LoadDictionary = %v, ожидалось %v", pairs, want)
+	}
+	words, err := LoadWords(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 3 || words[1] != "пёс" {
+		t.Errorf("LoadWords = %v", words)
+	}
+}
+
+func mustVector(t *testing.T, m *Model, word string) []float64 {
+	t.Helper()
+	vec, ok := m.Vector(word)
+	if !ok {
+		t.Fatalf("слова %s нет в модели", word)
+	}
+	return vec
+}
@@ -0,0 +1,155 @@
+package vectors
+
+import (
+	"context"
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"math"
+	"strings"
+)
+
+// AnalogyMethod — способ оценки кандидатов в аналогиях
+type AnalogyMethod string
+
+const (
+	// CosAdd — 3CosAdd: ближайшие слова к сумме положительных и разности отрицательных векторов
+	CosAdd AnalogyMethod = "3cosadd"
+	// CosMul — 3CosMul (Levy, Goldberg): произведение сходств с положительными словами,
+	// делённое на произведение сходств с отрицательными
+	CosMul AnalogyMethod = "3cosmul"
+)
+
+// Защита от деления на ноль в 3CosMul
+const cosMulEpsilon = 1e-3
+
+// ParseAnalogyMethod разбирает название метода (3cosadd или 3cosmul)
+func ParseAnalogyMethod(name string) (AnalogyMethod, error) {
+	switch AnalogyMethod(strings.ToLower(strings.TrimSpace(name))) {
+	case CosAdd, "cosadd", "add", "":
+		return CosAdd, nil
+	case CosMul, "cosmul", "mul":
+		return CosMul, nil
+	}
+	return "", fmt.Errorf("неизвестный метод аналогий: %q (3cosadd, 3cosmul)", name)
+}
+
+// ParseAnalogy разбирает выражение вида "москва - россия + франция"
+// на положительные и отрицательные слова. Знак может стоять отдельно или слитно
+// со словом ("-россия"); слово без знака считается положительным.
+func ParseAnalogy(expr string) (positive, negative []string, err error) {
+	sign := 1
+	for _, token := range strings.Fields(expr) {
+		for token != "" && strings.ContainsAny(token[:1], "+-") {
+			if token[0] == '-' {
+				sign = -1
+			} else {
+				sign = 1
+			}
+			token = token[1:]
+		}
+		if strings.HasPrefix(token, "−") {
+			sign = -1
+			token = strings.TrimPrefix(token, "−")
+		}
+		if token == "" {
+			continue
+		}
+		if sign > 0 {
+			positive = append(positive, token)
+		} else {
+			negative = append(negative, token)
+		}
+		sign = 1
+	}
+	if len(positive) == 0 {
+		return nil, nil, fmt.Errorf("в выражении нет положительных слов: %q", expr)
+	}
+	return positive, negative, nil
+}
+
+// Analogy решает аналогию по положительным и отрицательным словам
+// (например, positive = [москва, франция], negative = [россия] → париж).
+// Входные слова исключаются из результата. Для 3CosMul поле Similarity содержит оценку метода.
+func (m *Model) Analogy(positive, negative []string, method AnalogyMethod, topN int) ([]Neighbor, error) {
+	return m.AnalogyContext(context.Background(), positive, negative, method, topN)
+}
+
+// AnalogyContext работает как Analogy, но прекращает перебор словаря при отмене
+// контекста и возвращает его ошибку
+func (m *Model) AnalogyContext(ctx context.Context, positive, negative []string, method AnalogyMethod, topN int) ([]Neighbor, error) {
+	exclude := make(map[string]bool)
+	pos, err := m.termVectors(positive, exclude)
+	if err != nil {
+		return nil, err
+	}
+	neg, err := m.termVectors(negative, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method {
+	case CosMul:
+		return m.cosMul(ctx, pos, neg, exclude, topN)
+	case CosAdd, "":
+		target := make([]float64, m.Dim())
+		for _, vec := range pos {
+			for i, v := range vec {
+				target[i] += v
+			}
+		}
+		for _, vec := range neg {
+			for i, v := range vec {
+				target[i] -= v
+			}
+		}
+		return m.NearestContext(ctx, target, topN, exclude)
+	}
+	return nil, fmt.Errorf("неизвестный метод аналогий: %q", method)
+}
+
+// termVectors возвращает нормированные векторы слов и добавляет слова в exclude
+func (m *Model) termVectors(words []string, exclude map[string]bool) ([][]float64, error) {
+	vecs := make([][]float64, 0, len(words))
+	for _, word := range words {
+		vec, ok := m.Vector(word)
+		if !ok {
+			return nil, fmt.Errorf("слово '%s' не найдено в векторах", word)
+		}
+		vecs = append(vecs, Normalize(vec))
+		exclude[word] = true
+	}
+	return vecs, nil
+}
+
+// cosMul оценивает кандидатов по формуле 3CosMul со сходствами, сдвинутыми в диапазон [0, 1]
+func (m *Model) cosMul(ctx context.Context, pos, neg [][]float64, exclude map[string]bool, topN int) ([]Neighbor, error) {
+	if topN <= 0 {
+		return nil, nil
+	}
+	var result []Neighbor
+	for i, vec := range m.Vectors {
+		if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if m.norms[i] == 0 || exclude[m.Words[i]] {
+			continue
+		}
+		score := 1.0
+		for _, p := range pos {
+			score *= (linalg.Dot(vec, p)/m.norms[i] + 1) / 2
+		}
+		denominator := 1.0
+		for _, n := range neg {
+			denominator *= (linalg.Dot(vec, n)/m.norms[i] + 1) / 2
+		}
+		score /= denominator + cosMulEpsilon
+		if math.IsNaN(score) {
+			continue
+		}
+		if len(result) == topN && score <= result[topN-1].Similarity {
+			continue
+		}
+		result = insertNeighbor(result, Neighbor{Word: m.Words[i], Similarity: score}, topN)
+	}
+	return result, nil
+}
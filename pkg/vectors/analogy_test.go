@@ -0,0 +1,105 @@
+package vectors
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// analogyModel — модель, где королевский титул и пол — отдельные оси
+func analogyModel() *Model {
+	return New(
+		[]string{"король", "королева", "мужчина", "женщина", "принц", "яблоко", "ноль"},
+		[][]float64{
+			{1, 1, 0, 0.1},   // король = титул + мужской род
+			{1, 0, 1, 0.1},   // королева = титул + женский род
+			{0, 1, 0, 0.1},   // мужчина
+			{0, 0, 1, 0.1},   // женщина
+			{1, 0.9, 0, 0.3}, // принц — близок к королю
+			{0, 0, 0, 1},     // яблоко — не связано
+			{0, 0, 0, 0},     // нулевой вектор не попадает в ответы
+		},
+	)
+}
+
+func TestAnalogy(t *testing.T) {
+	model := analogyModel()
+	for _, method := range []AnalogyMethod{CosAdd, CosMul} {
+		got, err := model.Analogy([]string{"король", "женщина"}, []string{"мужчина"}, method, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 || got[0].Word != "королева" {
+			t.Errorf("%s: король − мужчина + женщина = %v, ожидалась королева", method, got)
+		}
+		for _, n := range got {
+			if n.Word == "король" || n.Word == "женщина" || n.Word == "мужчина" || n.Word == "ноль" {
+				t.Errorf("%s: в ответе слово %s", method, n.Word)
+			}
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i].Similarity > got[i-1].Similarity {
+				t.Errorf("%s: ответы не упорядочены: %v", method, got)
+			}
+		}
+	}
+}
+
+func TestAnalogyErrors(t *testing.T) {
+	model := analogyModel()
+	if _, err := model.Analogy([]string{"король", "нет"}, nil, CosAdd, 1); err == nil {
+		t.Error("ожидалась ошибка для слова вне модели")
+	}
+	if _, err := model.Analogy([]string{"король"}, nil, "3cosdiv", 1); err == nil {
+		t.Error("ожидалась ошибка для неизвестного метода")
+	}
+}
+
+func TestAnalogyContextCanceled(t *testing.T) {
+	model := analogyModel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, method := range []AnalogyMethod{CosAdd, CosMul} {
+		if _, err := model.AnalogyContext(ctx, []string{"король", "женщина"}, []string{"мужчина"}, method, 3); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: ошибка %v, ожидалась context.Canceled", method, err)
+		}
+	}
+}
+
+func TestParseAnalogy(t *testing.T) {
+	tests := []struct {
+		expr     string
+		positive []string
+		negative []string
+	}{
+		{"москва - россия + франция", []string{"москва", "франция"}, []string{"россия"}},
+		{"москва -россия франция", []string{"москва", "франция"}, []string{"россия"}},
+		{"+король −мужчина +женщина", []string{"король", "женщина"}, []string{"мужчина"}},
+		{"слово", []string{"слово"}, nil},
+	}
+	for _, tt := range tests {
+		pos, neg, err := ParseAnalogy(tt.expr)
+		if err != nil {
+			t.Errorf("ParseAnalogy(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(pos, tt.positive) || !reflect.DeepEqual(neg, tt.negative) {
+			t.Errorf("ParseAnalogy(%q) = %v, %v", tt.expr, pos, neg)
+		}
+	}
+	if _, _, err := ParseAnalogy("- россия"); err == nil {
+		t.Error("ожидалась ошибка для выражения без положительных слов")
+	}
+}
+
+func TestParseAnalogyMethod(t *testing.T) {
+	for name, want := range map[string]AnalogyMethod{"": CosAdd, "add": CosAdd, "3CosMul": CosMul, " mul ": CosMul} {
+		if got, err := ParseAnalogyMethod(name); err != nil || got != want {
+			t.Errorf("ParseAnalogyMethod(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseAnalogyMethod("div"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного метода")
+	}
+}
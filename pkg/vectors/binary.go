@@ -0,0 +1,293 @@
+package vectors
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Precision — точность хранения значений векторов в двоичном формате
+type Precision string
+
+const (
+	// Float32 — 4 байта на значение, потери точности несущественны
+	Float32 Precision = "float32"
+	// Float16 — 2 байта на значение (половинная точность IEEE 754)
+	Float16 Precision = "float16"
+	// Int8 — 1 байт на значение и множитель float32 на вектор
+	// (симметричное скалярное квантование по максимуму модуля)
+	Int8 Precision = "int8"
+)
+
+// Заголовок двоичного формата: сигнатура, версия, точность, число слов, размерность
+const (
+	binaryMagic   = "GPVB"
+	binaryVersion = 1
+	maxBinaryDim  = 1 << 16 // Наибольшая размерность, которую принимает loadBinary
+)
+
+var precisionCodes = map[Precision]byte{Float32: 1, Float16: 2, Int8: 3}
+
+// ParsePrecision разбирает название точности (float32, float16 или int8)
+func ParsePrecision(name string) (Precision, error) {
+	switch Precision(strings.ToLower(strings.TrimSpace(name))) {
+	case Float32, "f32", "":
+		return Float32, nil
+	case Float16, "f16", "half":
+		return Float16, nil
+	case Int8, "i8":
+		return Int8, nil
+	}
+	return "", fmt.Errorf("неизвестная точность векторов: %q (float32, float16, int8)", name)
+}
+
+// bytesPerVector возвращает размер закодированного вектора размерности dim
+func (p Precision) bytesPerVector(dim int) int {
+	switch p {
+	case Float16:
+		return 2 * dim
+	case Int8:
+		return 4 + dim
+	}
+	return 4 * dim
+}
+
+// BinarySize возвращает размер файла, который запишет SaveBinary с точностью p
+func (m *Model) BinarySize(p Precision) int64 {
+	size := int64(len(binaryMagic) + 2 + 8)
+	for _, word := range m.Words {
+		size += 2 + int64(len(word))
+	}
+	return size + int64(m.Len())*int64(p.bytesPerVector(m.Dim()))
+}
+
+// SaveBinary сохраняет векторы в компактном двоичном формате. Load распознаёт
+// его по сигнатуре, так что файл можно передавать везде вместо текстового.
+// Формат уменьшает только размер файла: Load раскодирует значения в float64,
+// и в памяти модель занимает столько же, сколько загруженная из текста.
+func (m *Model) SaveBinary(filename string, p Precision) (err error) {
+	code, ok := precisionCodes[p]
+	if !ok {
+		return fmt.Errorf("неизвестная точность векторов: %q", p)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("ошибка при записи в файл: %v", cerr)
+		}
+	}()
+
+	writer := bufio.NewWriter(file)
+	header := append([]byte(binaryMagic), binaryVersion, code)
+	header = binary.LittleEndian.AppendUint32(header, uint32(m.Len()))
+	header = binary.LittleEndian.AppendUint32(header, uint32(m.Dim()))
+	if _, err := writer.Write(header); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+
+	buf := make([]byte, 0, 2+p.bytesPerVector(m.Dim())+256)
+	for i, word := range m.Words {
+		if len(word) > math.MaxUint16 {
+			return fmt.Errorf("слово длиной %d байт не помещается в двоичный формат", len(word))
+		}
+		buf = binary.LittleEndian.AppendUint16(buf[:0], uint16(len(word)))
+		buf = append(buf, word...)
+		buf = appendVector(buf, m.Vectors[i], p)
+		if _, err := writer.Write(buf); err != nil {
+			return fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// Quantized возвращает копию модели, значения которой округлены так же, как при
+// сохранении с точностью p: по ней можно оценить потери до записи файла
+func (m *Model) Quantized(p Precision) *Model {
+	vecs := make([][]float64, len(m.Vectors))
+	buf := make([]byte, 0, p.bytesPerVector(m.Dim()))
+	for i, vec := range m.Vectors {
+		buf = appendVector(buf[:0], vec, p)
+		vecs[i] = make([]float64, len(vec))
+		decodeVector(buf, vecs[i], p)
+	}
+	q := New(m.Words, vecs)
+	q.fallback = m.fallback
+	return q
+}
+
+// isBinary сообщает, начинается ли поток с сигнатуры двоичного формата
+func isBinary(reader *bufio.Reader) bool {
+	head, err := reader.Peek(len(binaryMagic))
+	return err == nil && string(head) == binaryMagic
+}
+
+// loadBinary читает векторы, сохранённые SaveBinary. Число слов и размерность
+// из заголовка сверяются с размером файла size до выделения памяти, поэтому
+// повреждённый заголовок не приводит к попытке выделить гигабайты.
+func loadBinary(reader *bufio.Reader, size int64) (*Model, error) {
+	header := make([]byte, len(binaryMagic)+2+8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении заголовка векторов: %v", err)
+	}
+	if header[4] != binaryVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия двоичного формата векторов: %d", header[4])
+	}
+	var p Precision
+	for name, code := range precisionCodes {
+		if code == header[5] {
+			p = name
+		}
+	}
+	if p == "" {
+		return nil, fmt.Errorf("неизвестный код точности векторов: %d", header[5])
+	}
+	count := int(binary.LittleEndian.Uint32(header[6:]))
+	dim := int(binary.LittleEndian.Uint32(header[10:]))
+	if dim > maxBinaryDim {
+		return nil, fmt.Errorf("файл векторов повреждён: размерность %d", dim)
+	}
+	if need := int64(len(header)) + int64(count)*int64(2+p.bytesPerVector(dim)); need > size {
+		return nil, fmt.Errorf("файл векторов повреждён: для %d векторов размерности %d нужно не меньше %d байт, в файле %d",
+			count, dim, need, size)
+	}
+
+	words := make([]string, count)
+	vecs := make([][]float64, count)
+	values := make([]float64, count*dim) // Один массив на все векторы
+	data := make([]byte, p.bytesPerVector(dim))
+	var length [2]byte
+	for i := 0; i < count; i++ {
+		if _, err := io.ReadFull(reader, length[:]); err != nil {
+			return nil, fmt.Errorf("слово %d: %v", i+1, err)
+		}
+		word := make([]byte, binary.LittleEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(reader, word); err != nil {
+			return nil, fmt.Errorf("слово %d: %v", i+1, err)
+		}
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, fmt.Errorf("вектор слова %q: %v", word, err)
+		}
+		words[i] = string(word)
+		vecs[i] = values[i*dim : (i+1)*dim : (i+1)*dim]
+		decodeVector(data, vecs[i], p)
+	}
+	return New(words, vecs), nil
+}
+
+// appendVector дописывает к buf вектор, закодированный с точностью p
+func appendVector(buf []byte, vec []float64, p Precision) []byte {
+	switch p {
+	case Float16:
+		for _, v := range vec {
+			buf = binary.LittleEndian.AppendUint16(buf, float32ToHalf(float32(v)))
+		}
+	case Int8:
+		var maxAbs float64
+		for _, v := range vec {
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+		scale := float32(maxAbs / 127)
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(scale))
+		for _, v := range vec {
+			q := 0.0
+			if scale > 0 {
+				q = math.Max(-127, math.Min(127, math.Round(v/float64(scale))))
+			}
+			buf = append(buf, byte(int8(q)))
+		}
+	default:
+		for _, v := range vec {
+			buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v)))
+		}
+	}
+	return buf
+}
+
+// decodeVector раскодирует в out вектор, записанный appendVector
+func decodeVector(data []byte, out []float64, p Precision) {
+	switch p {
+	case Float16:
+		for i := range out {
+			out[i] = float64(halfToFloat32(binary.LittleEndian.Uint16(data[2*i:])))
+		}
+	case Int8:
+		scale := float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+		for i := range out {
+			out[i] = float64(int8(data[4+i])) * scale
+		}
+	default:
+		for i := range out {
+			out[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+		}
+	}
+}
+
+// float32ToHalf переводит число в половинную точность с округлением к ближайшему чётному
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	mant := bits & 0x7fffff
+	if bits>>23&0xff == 0xff { // Бесконечность или NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+	exp := int(bits>>23&0xff) - 127 + 15
+	switch {
+	case exp >= 31:
+		return sign | 0x7c00
+	case exp <= 0: // Денормализованное число половинной точности
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		half := mant >> shift
+		if roundUp(mant, shift, half) {
+			half++
+		}
+		return sign | uint16(half)
+	}
+	half := sign | uint16(exp)<<10 | uint16(mant>>13)
+	if roundUp(mant, 13, mant>>13) {
+		half++ // Перенос в порядок при переполнении мантиссы даёт верный результат
+	}
+	return half
+}
+
+// roundUp сообщает, нужно ли округлить вверх мантиссу kept = mant >> shift:
+// к ближайшему, а ровно посередине — к чётному, как принято в IEEE 754
+func roundUp(mant uint32, shift uint, kept uint32) bool {
+	guard := mant >> (shift - 1) & 1
+	rest := mant & (1<<(shift-1) - 1)
+	return guard != 0 && (rest != 0 || kept&1 != 0)
+}
+
+// halfToFloat32 переводит число половинной точности в float32
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp-15+127)<<23 | mant<<13)
+}
@@ -0,0 +1,154 @@
+package vectors
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHalfRoundTrip(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want float32
+	}{
+		{0, 0},
+		{1, 1},
+		{-2.5, -2.5},
+		{65504, 65504},                     // Наибольшее конечное число половинной точности
+		{1e6, float32(math.Inf(1))},        // Переполнение
+		{-1e6, float32(math.Inf(-1))},      // Переполнение
+		{6.103515625e-05, 6.103515625e-05}, // Наименьшее нормализованное
+		{5.960464477539063e-08, 5.960464477539063e-08}, // Наименьшее денормализованное
+		{1e-9, 0},                    // Исчезновение порядка
+		{1.0009765625, 1.0009765625}, // 1 + 2⁻¹⁰ представимо точно
+		{1.00048828125, 1},           // Ровно посередине между 1 и 1 + 2⁻¹⁰ — к чётной мантиссе
+		{1.00146484375, 1.001953125}, // Посередине между 1 + 2⁻¹⁰ и 1 + 2⁻⁹ — к чётной мантиссе
+		{1.0004885, 1.0009765625},    // Чуть больше середины — вверх
+		{float32(math.Inf(1)), float32(math.Inf(1))},
+	}
+	for _, tt := range tests {
+		if got := halfToFloat32(float32ToHalf(tt.in)); got != tt.want {
+			t.Errorf("half(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if got := halfToFloat32(float32ToHalf(float32(math.NaN()))); !math.IsNaN(float64(got)) {
+		t.Errorf("half(NaN) = %v, want NaN", got)
+	}
+	// Относительная ошибка в диапазоне нормализованных чисел не больше 2⁻¹¹
+	for x := float32(1e-4); x < 6e4; x *= 1.37 {
+		got := halfToFloat32(float32ToHalf(x))
+		if rel := math.Abs(float64(got-x)) / float64(x); rel > 1.0/2048 {
+			t.Fatalf("half(%v) = %v, относительная ошибка %v", x, got, rel)
+		}
+	}
+}
+
+func testModel() *Model {
+	return New(
+		[]string{"кот", "пёс", "ноль", "word with space"},
+		[][]float64{{0.5, -1.25, 3}, {1e-3, 2, -0.75}, {0, 0, 0}, {-7, 0.1, 0.2}},
+	)
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	model := testModel()
+	tests := []struct {
+		precision Precision
+		maxError  float64 // Наибольшая допустимая ошибка значения
+	}{
+		{Float32, 1e-6},
+		{Float16, 4e-3},
+		{Int8, 7.0 / 127 / 2}, // Половина шага квантования вектора с наибольшим модулем 7
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.precision), func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "vectors.bin")
+			if err := model.SaveBinary(filename, tt.precision); err != nil {
+				t.Fatal(err)
+			}
+			info, err := os.Stat(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() != model.BinarySize(tt.precision) {
+				t.Errorf("размер файла %d, BinarySize %d", info.Size(), model.BinarySize(tt.precision))
+			}
+			loaded, err := Load(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			quantized := model.Quantized(tt.precision)
+			if loaded.Len() != model.Len() || loaded.Dim() != model.Dim() {
+				t.Fatalf("загружено %d×%d, want %d×%d", loaded.Len(), loaded.Dim(), model.Len(), model.Dim())
+			}
+			for i, word := range model.Words {
+				if loaded.Words[i] != word {
+					t.Fatalf("слово %d: %q, want %q", i, loaded.Words[i], word)
+				}
+				for d, v := range model.Vectors[i] {
+					if e := math.Abs(loaded.Vectors[i][d] - v); e > tt.maxError {
+						t.Errorf("%q[%d] = %v, want %v (ошибка %v)", word, d, loaded.Vectors[i][d], v, e)
+					}
+					if loaded.Vectors[i][d] != quantized.Vectors[i][d] {
+						t.Errorf("%q[%d]: Quantized %v не совпадает с загруженным %v", word, d, quantized.Vectors[i][d], loaded.Vectors[i][d])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoadBinaryCorrupted(t *testing.T) {
+	model := testModel()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "vectors.bin")
+	if err := model.SaveBinary(filename, Float32); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		modify func([]byte) []byte
+	}{
+		{"огромное число слов", func(b []byte) []byte { b[9] = 0x7f; return b }},
+		{"огромная размерность", func(b []byte) []byte { b[13] = 0x7f; return b }},
+		{"неизвестная версия", func(b []byte) []byte { b[4] = 99; return b }},
+		{"неизвестная точность", func(b []byte) []byte { b[5] = 99; return b }},
+		{"обрезанный файл", func(b []byte) []byte { return b[:len(b)-5] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corrupted := filepath.Join(dir, "corrupted.bin")
+			if err := os.WriteFile(corrupted, tt.modify(append([]byte(nil), data...)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(corrupted); err == nil {
+				t.Error("Load повреждённого файла должен вернуть ошибку")
+			}
+		})
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	tests := []struct {
+		name string
+		want Precision
+		ok   bool
+	}{
+		{"", Float32, true},
+		{"F16", Float16, true},
+		{" half ", Float16, true},
+		{"i8", Int8, true},
+		{"int4", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ParsePrecision(tt.name)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParsePrecision(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package vectors
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"glove-pipeline/pkg/linalg"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Максимальная длина строки файла векторов
+const maxLineSize = 16 * 1024 * 1024
+
+// Через сколько слов перебор словаря проверяет отмену контекста
+const cancelCheckInterval = 4096
+
+// Neighbor — слово и его косинусное сходство с запросом
+type Neighbor struct {
+	Word       string  `json:"word"`
+	Similarity float64 `json:"similarity"`
+}
+
+// Model — векторная модель слов, загруженная из файла GloVe
+type Model struct {
+	Words    []string
+	Vectors  [][]float64
+	index    map[string]int
+	norms    []float64
+	fallback Fallback
+}
+
+// Fallback строит вектор для слова, которого нет в модели (например, по n-граммам
+// символов). Должна быть безопасна для конкурентного вызова.
+type Fallback func(word string) ([]float64, bool)
+
+// New создаёт модель из списка слов и соответствующих им векторов
+func New(words []string, vecs [][]float64) *Model {
+	m := &Model{Words: words, Vectors: vecs}
+	m.reindex()
+	return m
+}
+
+// reindex пересчитывает индекс слов и длины векторов
+func (m *Model) reindex() {
+	m.index = make(map[string]int, len(m.Words))
+	m.norms = make([]float64, len(m.Words))
+	for i, word := range m.Words {
+		m.index[word] = i
+		m.norms[i] = linalg.Norm(m.Vectors[i])
+	}
+}
+
+// Load загружает векторы из текстового файла (слово и значения через пробел)
+// или из двоичного файла, сохранённого SaveBinary
+func Load(filename string) (*Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при открытии файла векторов: %v", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if isBinary(reader) {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при открытии файла векторов: %v", err)
+		}
+		return loadBinary(reader, info.Size())
+	}
+
+	var words []string
+	var vecs [][]float64
+	dim := -1
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 {
+			continue
+		}
+		// Заголовок формата word2vec: "<число слов> <размерность>"
+		if line == 1 && len(parts) == 2 {
+			if _, err := strconv.Atoi(parts[0]); err == nil {
+				continue
+			}
+		}
+		if dim < 0 {
+			dim = len(parts) - 1
+		}
+		if len(parts)-1 != dim {
+			return nil, fmt.Errorf("строка %d: ожидалось %d значений, получено %d", line, dim, len(parts)-1)
+		}
+
+		vector := make([]float64, dim)
+		for i := 1; i < len(parts); i++ {
+			val, err := strconv.ParseFloat(parts[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: %v", line, err)
+			}
+			vector[i-1] = val
+		}
+		words = append(words, parts[0])
+		vecs = append(vecs, vector)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении файла векторов: %v", err)
+	}
+
+	return New(words, vecs), nil
+}
+
+// Save сохраняет векторы в текстовом формате GloVe
+func (m *Model) Save(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for i, word := range m.Words {
+		writer.WriteString(word)
+		for _, v := range m.Vectors[i] {
+			writer.WriteByte(' ')
+			writer.WriteString(strconv.FormatFloat(v, 'f', 6, 64))
+		}
+		if err := writer.WriteByte('\n'); err != nil {
+			return fmt.Errorf("ошибка при записи в файл: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// Len возвращает число слов в модели
+func (m *Model) Len() int {
+	return len(m.Words)
+}
+
+// Dim возвращает размерность векторов
+func (m *Model) Dim() int {
+	if len(m.Vectors) == 0 {
+		return 0
+	}
+	return len(m.Vectors[0])
+}
+
+// Index возвращает номер слова в модели
+func (m *Model) Index(word string) (int, bool) {
+	i, ok := m.index[word]
+	return i, ok
+}
+
+// Has сообщает, есть ли слово в модели
+func (m *Model) Has(word string) bool {
+	_, ok := m.index[word]
+	return ok
+}
+
+// SetFallback задаёт построение векторов для слов вне модели: после этого
+// Vector и все поиски по слову работают и для незнакомых слов. Has и Index
+// по-прежнему сообщают только о словах модели.
+func (m *Model) SetFallback(fallback Fallback) {
+	m.fallback = fallback
+}
+
+// AddFallback добавляет способ построения векторов для слов вне модели после
+// уже заданных: он вызывается, только если предыдущие не построили вектор
+func (m *Model) AddFallback(fallback Fallback) {
+	prev := m.fallback
+	if prev == nil {
+		m.fallback = fallback
+		return
+	}
+	m.fallback = func(word string) ([]float64, bool) {
+		if vec, ok := prev(word); ok {
+			return vec, true
+		}
+		return fallback(word)
+	}
+}
+
+// Vector возвращает вектор слова. Для слова вне модели вектор строится
+// функцией Fallback, если она задана. Через Vector слова ищут Similarity,
+// NearestWords, Analogy и MeanVector.
+func (m *Model) Vector(word string) ([]float64, bool) {
+	i, ok := m.index[word]
+	if !ok {
+		if m.fallback != nil {
+			return m.fallback(word)
+		}
+		return nil, false
+	}
+	return m.Vectors[i], true
+}
+
+// Similarity вычисляет косинусное сходство двух слов
+func (m *Model) Similarity(a, b string) (float64, error) {
+	va, ok := m.Vector(a)
+	if !ok {
+		return 0, fmt.Errorf("слово '%s' не найдено в векторах", a)
+	}
+	vb, ok := m.Vector(b)
+	if !ok {
+		return 0, fmt.Errorf("слово '%s' не найдено в векторах", b)
+	}
+	return CosineSimilarity(va, vb), nil
+}
+
+// MeanVector возвращает средний вектор найденных в модели слов и список этих слов.
+// Если ни одного слова нет в модели, возвращается nil.
+func (m *Model) MeanVector(words []string) ([]float64, []string) {
+	var found []string
+	var vecs [][]float64
+	for _, word := range words {
+		if vec, ok := m.Vector(word); ok {
+			found = append(found, word)
+			vecs = append(vecs, vec)
+		}
+	}
+	return Mean(vecs), found
+}
+
+// Nearest находит topN слов, ближайших к вектору по косинусному сходству.
+// Слова из exclude в результат не попадают.
+func (m *Model) Nearest(target []float64, topN int, exclude map[string]bool) []Neighbor {
+	result, _ := m.NearestContext(context.Background(), target, topN, exclude)
+	return result
+}
+
+// NearestContext работает как Nearest, но прекращает перебор словаря при отмене
+// контекста (например, когда истекло время HTTP-запроса) и возвращает его ошибку
+func (m *Model) NearestContext(ctx context.Context, target []float64, topN int, exclude map[string]bool) ([]Neighbor, error) {
+	targetNorm := linalg.Norm(target)
+	if targetNorm == 0 || topN <= 0 {
+		return nil, nil
+	}
+
+	var result []Neighbor
+	for i, vec := range m.Vectors {
+		if i%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if m.norms[i] == 0 || exclude[m.Words[i]] {
+			continue
+		}
+		sim := linalg.Dot(target, vec) / (targetNorm * m.norms[i])
+		if len(result) == topN && sim <= result[topN-1].Similarity {
+			continue
+		}
+		result = insertNeighbor(result, Neighbor{Word: m.Words[i], Similarity: sim}, topN)
+	}
+	return result, nil
+}
+
+// NearestWords находит topN слов, ближайших к заданному слову (без него самого)
+func (m *Model) NearestWords(word string, topN int) ([]Neighbor, error) {
+	vec, ok := m.Vector(word)
+	if !ok {
+		return nil, fmt.Errorf("слово '%s' не найдено в векторах", word)
+	}
+	return m.Nearest(vec, topN, map[string]bool{word: true}), nil
+}
+
+// insertNeighbor вставляет соседа в отсортированный по убыванию сходства список длиной не более limit
+func insertNeighbor(list []Neighbor, n Neighbor, limit int) []Neighbor {
+	pos := sort.Search(len(list), func(i int) bool { return list[i].Similarity < n.Similarity })
+	if len(list) < limit {
+		list = append(list, Neighbor{})
+	} else if pos >= limit {
+		return list
+	}
+	copy(list[pos+1:], list[pos:])
+	list[pos] = n
+	return list
+}
+
+// Normalized возвращает копию модели с векторами единичной длины
+func (m *Model) Normalized() *Model {
+	vecs := make([][]float64, len(m.Vectors))
+	for i, vec := range m.Vectors {
+		vecs[i] = Normalize(vec)
+	}
+	n := New(m.Words, vecs)
+	n.fallback = m.fallback
+	return n
+}
+
+// CosineSimilarity вычисляет косинусное сходство между двумя векторами.
+// Для векторов разной длины или нулевых векторов возвращается 0.
+func CosineSimilarity(vec1, vec2 []float64) float64 {
+	if len(vec1) != len(vec2) {
+		return 0
+	}
+	n1, n2 := linalg.Norm(vec1), linalg.Norm(vec2)
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+	return linalg.Dot(vec1, vec2) / (n1 * n2)
+}
+
+// Normalize возвращает копию вектора единичной длины (нулевой вектор возвращается как есть)
+func Normalize(vec []float64) []float64 {
+	result := make([]float64, len(vec))
+	n := linalg.Norm(vec)
+	if n == 0 {
+		copy(result, vec)
+		return result
+	}
+	for i, v := range vec {
+		result[i] = v / n
+	}
+	return result
+}
+
+// Mean возвращает средний вектор; для пустого набора возвращается nil
+func Mean(vecs [][]float64) []float64 {
+	if len(vecs) == 0 {
+		return nil
+	}
+	sum := make([]float64, len(vecs[0]))
+	for _, vec := range vecs {
+		for i, v := range vec {
+			sum[i] += v
+		}
+	}
+	for i := range sum {
+		sum[i] /= float64(len(vecs))
+	}
+	return sum
+}
@@ -0,0 +1,54 @@
+package vectors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	model := analogyModel()
+	// Слово вне модели получает вектор своей начальной формы: «королевы» → «королева»
+	lemmas := map[string]string{"королевы": "королева"}
+	model.SetFallback(func(word string) ([]float64, bool) {
+		i, ok := model.Index(lemmas[word])
+		if !ok {
+			return nil, false
+		}
+		return model.Vectors[i], true
+	})
+
+	if model.Has("королевы") {
+		t.Error("Has сообщает о слове вне модели")
+	}
+	if _, ok := model.Index("королевы"); ok {
+		t.Error("Index нашёл слово вне модели")
+	}
+	vec, ok := model.Vector("королевы")
+	if !ok || !reflect.DeepEqual(vec, mustVector(t, model, "королева")) {
+		t.Errorf("Vector(королевы) = %v, %v", vec, ok)
+	}
+	if _, ok := model.Vector("груша"); ok {
+		t.Error("Vector построил вектор, которого Fallback не строит")
+	}
+
+	if sim, err := model.Similarity("королевы", "королева"); err != nil || sim < 1-1e-9 {
+		t.Errorf("Similarity = %v, %v", sim, err)
+	}
+	neighbors, err := model.NearestWords("королевы", 1)
+	if err != nil || len(neighbors) != 1 || neighbors[0].Word != "королева" {
+		t.Errorf("NearestWords = %v, %v", neighbors, err)
+	}
+	answers, err := model.Analogy([]string{"королевы", "мужчина"}, []string{"женщина"}, CosAdd, 1)
+	if err != nil || len(answers) != 1 || answers[0].Word != "король" {
+		t.Errorf("королевы − женщина + мужчина = %v, %v", answers, err)
+	}
+	mean, found := model.MeanVector([]string{"королевы", "груша"})
+	if !reflect.DeepEqual(found, []string{"королевы"}) || !reflect.DeepEqual(mean, mustVector(t, model, "королева")) {
+		t.Errorf("MeanVector = %v, найдены %v", mean, found)
+	}
+
+	// Копии модели сохраняют Fallback
+	if _, ok := model.Normalized().Vector("королевы"); !ok {
+		t.Error("Normalized потерял Fallback")
+	}
+}
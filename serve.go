@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/docindex"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/server"
+	"glove-pipeline/pkg/spell"
+	"glove-pipeline/pkg/subword"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runServe загружает модели один раз и отдаёт результаты запросов по HTTP
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "Адрес для прослушивания")
+	models := fs.String("models", "default=data/vectors.txt.txt", "Модели в виде имя=путь через запятую; первая используется по умолчанию")
+	stopwordsFile := fs.String("stopwords", "data/stopwords.txt", "Файл стоп-слов для очистки фраз (необязательный)")
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов для взвешивания SIF в /phrase-vector (необязательный)")
+	ngramFiles := fs.String("ngrams", "", "Файлы n-грамм (например, data/2_grams.txt) через запятую")
+	timeout := fs.Duration("timeout", 30*time.Second, "Максимальное время обработки запроса")
+	indexDir := fs.String("index", "", "Каталог индекса документов для поиска похожих (необязательный)")
+	spellFile := fs.String("spell", "", "Словарь GloVe для исправления опечаток в запросах и маршрута /spell (необязательный)")
+	subwordFile := fs.String("subword", "", "Модель n-грамм для векторов слов вне словаря (необязательная)")
+	maxPhrases := fs.Int("phrases", 5000, "Сколько самых частых n-грамм каждого порядка /expand считает словосочетаниями")
+	fs.Parse(args)
+
+	srv := server.New(server.Config{Addr: *addr, RequestTimeout: *timeout, MaxPhrases: *maxPhrases})
+	loaded, err := loadServerModels(srv, *models)
+	if err != nil {
+		return err
+	}
+
+	if *spellFile != "" {
+		// Слова вне словаря сначала ищутся среди исправлений и только затем строятся по n-граммам
+		checker, err := spell.Load(*spellFile, spell.DefaultOptions())
+		if err != nil {
+			return err
+		}
+		for _, model := range loaded {
+			checker.Attach(model)
+		}
+		srv.SetSpeller(checker)
+		log.Printf("Загружен словарь исправлений %s: %d слов", *spellFile, checker.Len())
+	}
+
+	if *subwordFile != "" {
+		// Модель n-грамм подключается к модели векторов, по которой обучалась
+		sw, err := subword.Load(*subwordFile)
+		if err != nil {
+			return err
+		}
+		model, ok := loaded[modelKey(sw.VectorsFile)]
+		if !ok {
+			return fmt.Errorf("модель n-грамм обучена по %s, но эти векторы не загружены (-models)", sw.VectorsFile)
+		}
+		if err := sw.Attach(model); err != nil {
+			return err
+		}
+		log.Printf("Подключена модель n-грамм %s к векторам %s", *subwordFile, sw.VectorsFile)
+	}
+
+	if *indexDir != "" {
+		// Индекс использует уже загруженную модель, если строился по тому же файлу
+		meta, err := docindex.ReadMeta(*indexDir)
+		if err != nil {
+			return err
+		}
+		model, ok := loaded[modelKey(meta.VectorsFile)]
+		if !ok {
+			log.Printf("Индекс построен по векторам %s, которых нет среди -models; они будут загружены отдельно", meta.VectorsFile)
+		}
+		ix, err := docindex.Load(*indexDir, model)
+		if err != nil {
+			return err
+		}
+		srv.SetIndex(ix)
+		log.Printf("Загружен индекс документов %s: %d документов", *indexDir, ix.Len())
+	}
+
+	if *stopwordsFile != "" {
+		stopWords, err := ngrams.LoadStopwords(*stopwordsFile)
+		if err != nil {
+			log.Printf("Стоп-слова не загружены: %v", err)
+		} else {
+			srv.SetStopWords(stopWords)
+		}
+	}
+
+	if *vocabFile != "" {
+		counts, err := glove.VocabCounts(*vocabFile)
+		if err != nil {
+			log.Printf("Частоты слов не загружены, векторы фраз — простое среднее: %v", err)
+		} else {
+			srv.SetVocabCounts(counts)
+		}
+	}
+
+	for _, file := range parseList(*ngramFiles) {
+		pairs, err := ngrams.LoadNGrams(file)
+		if err != nil {
+			return err
+		}
+		if len(pairs) == 0 {
+			log.Printf("Файл n-грамм %s пуст", file)
+			continue
+		}
+		srv.AddNGrams(len(pairs[0].Words), pairs)
+		log.Printf("Загружено %d n-грамм из %s", len(pairs), file)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return srv.ListenAndServe(ctx)
+}
+
+// loadServerModels загружает модели, перечисленные в виде имя=путь через запятую,
+// и возвращает их по путям к файлам, приведённым функцией modelKey
+func loadServerModels(srv *server.Server, spec string) (map[string]*vectors.Model, error) {
+	entries := parseList(spec)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("не задано ни одной модели (-models)")
+	}
+	loaded := make(map[string]*vectors.Model)
+	for _, entry := range entries {
+		name, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			name, path = "default", entry
+		}
+		key := modelKey(path)
+		if model, ok := loaded[key]; ok {
+			// Один файл под разными именами загружается один раз
+			srv.AddModel(name, model)
+			continue
+		}
+		log.Printf("Загрузка модели %s из %s...", name, path)
+		model, err := vectors.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("модель %s: %v", name, err)
+		}
+		srv.AddModel(name, model)
+		loaded[key] = model
+		log.Printf("Модель %s: %d слов, размерность %d", name, model.Len(), model.Dim())
+	}
+	return loaded, nil
+}
+
+// modelKey приводит путь к файлу векторов к абсолютному виду, чтобы пути
+// "data/vectors.txt" и "./data/vectors.txt" указывали на одну загруженную модель
+func modelKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
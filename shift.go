@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/semshift"
+	"os"
+	"path/filepath"
+)
+
+// runShift разбивает корпус с датами на периоды, обучает модель для каждого периода
+// и ищет слова с наибольшим сдвигом значения
+func runShift(args []string) error {
+	fs := flag.NewFlagSet("shift", flag.ExitOnError)
+	corpusFile := fs.String("corpus", "data/corpus.jsonl", "Корпус с датами в формате JSONL")
+	dir := fs.String("dir", "data/slices", "Каталог для срезов корпуса и моделей (срезы хранятся в подкаталоге длины периода)")
+	periodFlag := fs.String("period", "month", "Длина среза: day, week, month, quarter, year (с множителем, например 2month)")
+	skipSplit := fs.Bool("skip-split", false, "Не разбивать корпус заново, использовать срезы из каталога")
+	skipTrain := fs.Bool("skip-train", false, "Не обучать GloVe, использовать уже обученные модели срезов")
+	minCount := fs.Int("min-count", 20, "Минимальная частота слова в каждом срезе")
+	topN := fs.Int("top", 50, "Число слов с наибольшим сдвигом в отчёте")
+	neighbors := fs.Int("neighbors", 10, "Число соседей слова в каждом периоде")
+	words := fs.String("words", "", "Слова для отчёта через запятую (по умолчанию — топ по сдвигу)")
+	output := fs.String("output", "data/semantic_shift.json", "Файл отчёта в формате JSON")
+	fs.Parse(args)
+
+	period, err := semshift.ParsePeriod(*periodFlag)
+	if err != nil {
+		return err
+	}
+	periodDir := filepath.Join(*dir, period.Name())
+
+	var slices []semshift.Slice
+	if *skipSplit {
+		slices, err = semshift.LoadSlices(periodDir, period)
+	} else {
+		fmt.Printf("Разбиение корпуса по периодам (%s)...\n", *periodFlag)
+		slices, err = semshift.Split(*corpusFile, periodDir, period)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !*skipTrain {
+		fmt.Println("Обучение моделей срезов...")
+		if err := semshift.Train(slices); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Выравнивание срезов и поиск сдвигов...")
+	report, err := semshift.Detect(slices, semshift.Options{
+		MinCount:  *minCount,
+		TopN:      *topN,
+		Neighbors: *neighbors,
+		Words:     parseList(*words),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := report.SaveJSON(*output); err != nil {
+		return err
+	}
+	report.Print(os.Stdout)
+	fmt.Printf("Отчёт сохранен в %s\n", *output)
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/docindex"
+	"glove-pipeline/pkg/spell"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"os"
+	"strings"
+)
+
+// runSpell предлагает исправления опечаток и слов, набранных не в той раскладке
+func runSpell(args []string) error {
+	defaults := spell.DefaultOptions()
+	fs := flag.NewFlagSet("spell", flag.ExitOnError)
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов")
+	query := fs.String("q", "", "Слова через запятую, для которых вывести исправления")
+	context := fs.String("context", "", "Текст вокруг слова для ранжирования исправлений по векторам")
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов (нужен только с -context)")
+	input := fs.String("input", "", "Файл со словами, по одному на строку, для пакетного исправления")
+	output := fs.String("output", "data/spelling.tsv", "Файл исправлений для -input")
+	top := fs.Int("top", 5, "Число исправлений слова для -q")
+	maxDistance := fs.Int("max-distance", defaults.MaxDistance, "Максимальное расстояние редактирования")
+	minCount := fs.Int("min-count", defaults.MinCount, "Минимальная частота предлагаемого слова")
+	fs.Parse(args)
+
+	if *query == "" && *input == "" {
+		return fmt.Errorf("задайте слова (-q) или файл слов (-input)")
+	}
+	opts := defaults
+	opts.MaxDistance = *maxDistance
+	opts.MinCount = *minCount
+	checker, err := spell.Load(*vocabFile, opts)
+	if err != nil {
+		return err
+	}
+	log.Printf("Словарь исправлений: %d слов", checker.Len())
+
+	var model *vectors.Model
+	var contextWords []string
+	if *context != "" {
+		if model, err = vectors.Load(*vectorsFile); err != nil {
+			return err
+		}
+		contextWords = strings.Fields(docindex.Clean(*context))
+	}
+
+	for _, word := range parseList(*query) {
+		suggestions := checker.SuggestInContext(word, contextWords, model, *top)
+		fmt.Printf("\n%s:\n", word)
+		if len(suggestions) == 0 {
+			fmt.Println("  исправлений не найдено")
+		}
+		for i, s := range suggestions {
+			note := ""
+			if s.Layout {
+				note = ", другая раскладка"
+			}
+			if model != nil {
+				note += fmt.Sprintf(", сходство с контекстом %.3f", s.Similarity)
+			}
+			fmt.Printf("%2d. %s (расстояние %d, частота %d%s)\n", i+1, s.Word, s.Distance, s.Count, note)
+		}
+	}
+
+	if *input != "" {
+		return correctWords(checker, *input, *output)
+	}
+	return nil
+}
+
+// correctWords исправляет слова файла (по одному на строку) и сохраняет TSV:
+// слово, исправление, расстояние и признак другой раскладки
+func correctWords(checker *spell.Checker, input, output string) error {
+	in, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("ошибка при открытии файла: %v", err)
+	}
+	defer in.Close()
+	out, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer out.Close()
+	writer := bufio.NewWriter(out)
+	fmt.Fprintln(writer, "word\tcorrection\tdistance\tlayout")
+
+	total, corrected := 0, 0
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" {
+			continue
+		}
+		total++
+		suggestions := checker.Suggest(word, 1)
+		if len(suggestions) == 0 {
+			fmt.Fprintf(writer, "%s\t\t\t\n", word)
+			continue
+		}
+		s := suggestions[0]
+		if s.Distance > 0 || s.Layout {
+			corrected++
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%t\n", word, s.Word, s.Distance, s.Layout)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ошибка при чтении файла: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	fmt.Printf("Слов: %d, исправлено: %d. Результат сохранён в %s\n", total, corrected, output)
+	return nil
+}
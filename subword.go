@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/subword"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"strings"
+)
+
+// runSubword обучает векторы n-грамм символов по готовым векторам слов, чтобы
+// строить векторы для слов вне словаря
+func runSubword(args []string) error {
+	defaults := subword.DefaultOptions()
+	fs := flag.NewFlagSet("subword", flag.ExitOnError)
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов слов, которые восстанавливает модель")
+	output := fs.String("output", "data/subword.bin", "Файл модели n-грамм")
+	minN := fs.Int("min-n", defaults.MinN, "Минимальная длина n-граммы в символах")
+	maxN := fs.Int("max-n", defaults.MaxN, "Максимальная длина n-граммы в символах")
+	buckets := fs.Int("buckets", defaults.Buckets, "Число корзин хеширования n-грамм")
+	epochs := fs.Int("epochs", defaults.Epochs, "Число эпох обучения")
+	lr := fs.Float64("lr", defaults.LearningRate, "Начальный шаг обучения")
+	limit := fs.Int("limit", defaults.Limit, "Сколько самых частых слов использовать для обучения (0 — все)")
+	heldOut := fs.Float64("held-out", defaults.HeldOut, "Доля слов, отложенных для оценки качества")
+	seed := fs.Int64("seed", defaults.Seed, "Зерно генератора случайных чисел")
+	query := fs.String("q", "", "Слова через запятую, для которых после обучения вывести ближайших соседей")
+	fs.Parse(args)
+
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+	log.Printf("Загружено %d слов, размерность %d", model.Len(), model.Dim())
+
+	opts := subword.Options{MinN: *minN, MaxN: *maxN, Buckets: *buckets, Epochs: *epochs,
+		LearningRate: *lr, Limit: *limit, HeldOut: *heldOut, Seed: *seed}
+	sw, report, err := subword.Train(model, opts, func(epoch int, loss float64) {
+		log.Printf("Эпоха %d: ошибка %.4f", epoch, loss)
+	})
+	if err != nil {
+		return err
+	}
+	sw.VectorsFile = *vectorsFile
+	fmt.Printf("Косинусное сходство восстановленных векторов: обучающие слова %.3f (%d)", report.Train, report.Words)
+	if report.Held > 0 {
+		fmt.Printf(", отложенные слова %.3f (%d)", report.HeldOut, report.Held)
+	}
+	fmt.Println()
+
+	if err := sw.Save(*output); err != nil {
+		return err
+	}
+	fmt.Printf("Модель n-грамм сохранена в %s\n", *output)
+
+	if err := sw.Attach(model); err != nil {
+		return err
+	}
+	for _, word := range parseList(*query) {
+		word = strings.ToLower(word)
+		neighbors, err := model.NearestWords(word, 10)
+		if err != nil {
+			log.Printf("%v", err)
+			continue
+		}
+		source := "словарь"
+		if !model.Has(word) {
+			source = "n-граммы"
+		}
+		fmt.Printf("\n%s (%s):\n", word, source)
+		for i, n := range neighbors {
+			fmt.Printf("%2d. %s (%.4f)\n", i+1, n.Word, n.Similarity)
+		}
+	}
+	return nil
+}
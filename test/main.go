@@ -1,8 +1,9 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"glove-pipeline/pkg/corpus"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -25,22 +26,30 @@ func main() {
 	n := 5            // Размер n-граммы
 	minFrequency := 5 // Минимальная частота для сохранения
 
-	// Загрузка файла
-	file, err := os.Open("../data/cleaned_corpus.txt")
+	// Загрузка файла (текстовый корпус или JSONL с метаданными)
+	reader, err := corpus.Open("../data/cleaned_corpus.txt")
 	if err != nil {
 		fmt.Println("Ошибка при открытии файла:", err)
 		return
 	}
-	defer file.Close()
+	defer reader.Close()
 
 	// Чтение файла и разбиение на слова
-	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanWords)
 	var words []string
-	for scanner.Scan() {
-		word := strings.ToLower(scanner.Text()) // Приводим к нижнему регистру
-		if !stopWords[word] {                   // Игнорируем стоп-слова
-			words = append(words, word)
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println("Ошибка при чтении файла:", err)
+			return
+		}
+		for _, word := range strings.Fields(doc.Text) {
+			word = strings.ToLower(word) // Приводим к нижнему регистру
+			if !stopWords[word] {        // Игнорируем стоп-слова
+				words = append(words, word)
+			}
 		}
 	}
 
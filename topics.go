@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/corpus"
+	"glove-pipeline/pkg/glove"
+	"glove-pipeline/pkg/lda"
+	"glove-pipeline/pkg/ngrams"
+	"glove-pipeline/pkg/vectors"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// topicSummary — тема в отчёте: самые вероятные слова и оценки согласованности
+type topicSummary struct {
+	ID        int            `json:"id"`
+	Size      float64        `json:"size"` // Доля слов корпуса
+	Words     []lda.WordProb `json:"words"`
+	UMass     float64        `json:"umass"`
+	NPMI      float64        `json:"npmi"`
+	Embedding *float64       `json:"embedding,omitempty"` // Среднее косинусное сходство векторов слов темы
+}
+
+// topicsReport — параметры модели, её качество и темы
+type topicsReport struct {
+	K                  int            `json:"k"`
+	Alpha              float64        `json:"alpha"`
+	Beta               float64        `json:"beta"`
+	Iterations         int            `json:"iterations"`
+	Vocab              int            `json:"vocab"`
+	Documents          int            `json:"documents"`
+	Tokens             int            `json:"tokens"`
+	Perplexity         float64        `json:"perplexity"`                    // На обучающем корпусе
+	HeldOutPerplexity  float64        `json:"held_out_perplexity,omitempty"` // На отложенных документах
+	UMass              float64        `json:"umass"`
+	NPMI               float64        `json:"npmi"`
+	EmbeddingCoherence *float64       `json:"embedding_coherence,omitempty"`
+	Topics             []topicSummary `json:"topics"`
+}
+
+// runTopics обучает тематическую модель LDA на очищенном корпусе
+func runTopics(args []string) error {
+	defaults := lda.DefaultOptions()
+	fs := flag.NewFlagSet("topics", flag.ExitOnError)
+	input := fs.String("input", "data/cleaned_corpus.txt", "Очищенный корпус (текст или JSONL)")
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов")
+	stopwordsFile := fs.String("stopwords", "data/stopwords.txt", "Файл стоп-слов, исключаемых из словаря тем")
+	minCount := fs.Int("min-count", 5, "Минимальная частота слова в словаре")
+	maxVocab := fs.Int("max-vocab", 50000, "Максимальный размер словаря (самые частые слова, 0 — без ограничения)")
+	skipTop := fs.Int("skip-top", 0, "Сколько самых частых слов словаря исключить")
+	k := fs.Int("k", defaults.K, "Число тем")
+	alpha := fs.Float64("alpha", 0.1, "Параметр α распределения тем документа (0 — 50/K)")
+	beta := fs.Float64("beta", defaults.Beta, "Параметр β распределения слов темы")
+	iterations := fs.Int("iterations", defaults.Iterations, "Число итераций сэмплирования")
+	threads := fs.Int("threads", defaults.Threads, "Число потоков сэмплирования")
+	seed := fs.Int64("seed", defaults.Seed, "Зерно генератора случайных чисел")
+	heldOut := fs.Float64("held-out", 0.1, "Доля документов, отложенных для оценки перплексии (0 — без отложенных)")
+	topWords := fs.Int("top-words", 15, "Число слов темы в отчёте и в оценке согласованности")
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов для согласованности тем по векторам (пусто — не считать)")
+	output := fs.String("output", "data/topics", "Префикс выходных файлов")
+	fs.Parse(args)
+
+	vocab, err := topicVocab(*vocabFile, *stopwordsFile, *minCount, *maxVocab, *skipTop)
+	if err != nil {
+		return err
+	}
+	reader, err := corpus.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	all := lda.NewCorpus(vocab)
+	var ids []string
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		ids = append(ids, doc.ID)
+		all.Add(strings.Fields(doc.Text))
+	}
+	train, test := all.Split(*heldOut, *seed)
+	fmt.Printf("Словарь: %d слов; документов: %d (отложено %d), слов в обучении: %d\n",
+		len(vocab), len(train.Docs), len(test.Docs), train.Tokens())
+
+	opts := lda.Options{K: *k, Alpha: *alpha, Beta: *beta, Iterations: *iterations, Threads: *threads, Seed: *seed, Every: defaults.Every}
+	var perplexity float64
+	model, err := lda.Train(train, opts, func(it int, p float64) {
+		perplexity = p
+		log.Printf("Итерация %d: перплексия %.1f", it, p)
+	})
+	if err != nil {
+		return err
+	}
+
+	report := topicsReport{K: model.K, Alpha: model.Alpha, Beta: model.Beta, Iterations: *iterations, Vocab: len(vocab),
+		Documents: len(train.Docs), Tokens: train.Tokens(), Perplexity: perplexity}
+	if len(test.Docs) > 0 {
+		report.HeldOutPerplexity = model.Perplexity(test, 50, *seed)
+	}
+
+	var vecModel *vectors.Model
+	if *vectorsFile != "" {
+		if vecModel, err = vectors.Load(*vectorsFile); err != nil {
+			log.Printf("Векторы не загружены, согласованность по векторам не считается: %v", err)
+		}
+	}
+
+	// Согласованность считается по совместной встречаемости в обучающих документах
+	var topIDs []int32
+	for t := 0; t < model.K; t++ {
+		summary := topicSummary{ID: t, Size: model.Size(t), Words: model.TopWords(t, *topWords)}
+		for _, w := range summary.Words {
+			topIDs = append(topIDs, int32(w.ID))
+		}
+		report.Topics = append(report.Topics, summary)
+	}
+	co := lda.NewCooccurrence(train, topIDs)
+	var embSum float64
+	embCount := 0
+	for i := range report.Topics {
+		topic := &report.Topics[i]
+		ids := make([]int32, len(topic.Words))
+		words := make([]string, len(topic.Words))
+		for j, w := range topic.Words {
+			ids[j], words[j] = int32(w.ID), w.Word
+		}
+		topic.UMass = co.UMass(ids)
+		topic.NPMI = co.NPMI(ids)
+		report.UMass += topic.UMass / float64(model.K)
+		report.NPMI += topic.NPMI / float64(model.K)
+		if vecModel != nil {
+			if c, ok := lda.EmbeddingCoherence(vecModel, words); ok {
+				topic.Embedding = &c
+				embSum += c
+				embCount++
+			}
+		}
+	}
+	if embCount > 0 {
+		mean := embSum / float64(embCount)
+		report.EmbeddingCoherence = &mean
+	}
+
+	printTopics(report)
+	if err := saveTopics(*output, report); err != nil {
+		return err
+	}
+	if err := saveDocTopics(*output+"_docs.tsv", model, train, test, ids, *seed); err != nil {
+		return err
+	}
+	fmt.Printf("\nТемы сохранены в %s.json и %s.txt, распределения тем документов — в %s_docs.tsv\n", *output, *output, *output)
+	return nil
+}
+
+// topicVocab отбирает слова словаря GloVe для тем: без стоп-слов и слишком редких слов
+func topicVocab(vocabFile, stopwordsFile string, minCount, maxVocab, skipTop int) ([]string, error) {
+	entries, err := glove.LoadVocab(vocabFile)
+	if err != nil {
+		return nil, err
+	}
+	stopwords, err := ngrams.LoadStopwords(stopwordsFile)
+	if err != nil {
+		log.Printf("Стоп-слова не загружены: %v", err)
+	}
+	var vocab []string
+	for i, e := range entries {
+		if i < skipTop || e.Count < minCount {
+			continue
+		}
+		if _, stop := stopwords[e.Word]; stop {
+			continue
+		}
+		vocab = append(vocab, e.Word)
+		if maxVocab > 0 && len(vocab) >= maxVocab {
+			break
+		}
+	}
+	if len(vocab) == 0 {
+		return nil, fmt.Errorf("словарь тем пуст: проверьте -min-count и -skip-top")
+	}
+	return vocab, nil
+}
+
+// printTopics выводит оценки модели и слова каждой темы
+func printTopics(r topicsReport) {
+	fmt.Printf("\nПерплексия: %.1f", r.Perplexity)
+	if r.HeldOutPerplexity > 0 {
+		fmt.Printf(", на отложенных документах: %.1f", r.HeldOutPerplexity)
+	}
+	fmt.Printf("\nСогласованность: UMass %.3f, NPMI %.3f", r.UMass, r.NPMI)
+	if r.EmbeddingCoherence != nil {
+		fmt.Printf(", по векторам %.3f", *r.EmbeddingCoherence)
+	}
+	fmt.Println()
+	for _, topic := range r.Topics {
+		words := make([]string, len(topic.Words))
+		for i, w := range topic.Words {
+			words[i] = w.Word
+		}
+		fmt.Printf("\nТема %d (%.1f%%, NPMI %.3f): %s", topic.ID, 100*topic.Size, topic.NPMI, strings.Join(words, ", "))
+	}
+	fmt.Println()
+}
+
+// saveTopics сохраняет отчёт в JSON и слова тем в текстовый файл (тема на строку)
+func saveTopics(prefix string, r topicsReport) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(prefix+".json", append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка при записи отчёта: %v", err)
+	}
+
+	file, err := os.Create(prefix + ".txt")
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+	for _, topic := range r.Topics {
+		words := make([]string, len(topic.Words))
+		for i, w := range topic.Words {
+			words[i] = w.Word
+		}
+		fmt.Fprintf(writer, "%d\t%s\n", topic.ID, strings.Join(words, " "))
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
+
+// saveDocTopics сохраняет распределение тем каждого документа в порядке корпуса:
+// идентификатор, основная тема и вероятности всех тем. Темы отложенных
+// документов выводятся сэмплированием по обученной модели.
+func saveDocTopics(filename string, model *lda.Model, train, test *lda.Corpus, ids []string, seed int64) error {
+	theta := make(map[int][]float64, len(train.Docs)+len(test.Docs))
+	for d, source := range train.Source {
+		theta[source] = model.Theta(d)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for d, source := range test.Source {
+		theta[source] = model.Infer(test.Docs[d], 50, rng)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании файла: %v", err)
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	fmt.Fprint(writer, "id\ttopic")
+	for t := 0; t < model.K; t++ {
+		fmt.Fprintf(writer, "\tp%d", t)
+	}
+	fmt.Fprintln(writer)
+	for source, id := range ids {
+		probs, ok := theta[source]
+		if !ok {
+			continue // В документе меньше двух слов словаря тем
+		}
+		best := 0
+		for t := range probs {
+			if probs[t] > probs[best] {
+				best = t
+			}
+		}
+		fmt.Fprintf(writer, "%s\t%d", id, best)
+		for _, p := range probs {
+			fmt.Fprintf(writer, "\t%.4f", p)
+		}
+		fmt.Fprintln(writer)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка при записи в файл: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glove-pipeline/pkg/classify"
+	"glove-pipeline/pkg/docvec"
+	"glove-pipeline/pkg/textprocessor"
+	"glove-pipeline/pkg/vectors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// runTrain обучает классификатор текстов на размеченном CSV или JSONL
+func runTrain(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	input := fs.String("input", "data/labelled.csv", "Размеченные документы: CSV (текст очищается) или JSONL с полем label")
+	output := fs.String("output", "data/classifier.json", "Файл классификатора")
+	vectorsFile := fs.String("vectors", "data/vectors.txt.txt", "Файл векторов")
+	vocabFile := fs.String("vocab", "data/vocab.txt", "Словарь GloVe с частотами слов для взвешивания")
+	weightingName := fs.String("weighting", "sif", "Взвешивание слов в векторах документов: mean, tfidf или sif")
+	removePC := fs.Bool("remove-pc", true, "Вычитать из векторов документов первую главную компоненту")
+	textColumn := fs.String("text-column", "", "Столбец CSV с текстом (по умолчанию определяется по заголовку)")
+	labelColumn := fs.String("label-column", "", "Столбец CSV с меткой класса (по умолчанию label, class, category)")
+	epochs := fs.Int("epochs", 200, "Максимальное число эпох")
+	batch := fs.Int("batch", 32, "Размер мини-пакета")
+	lr := fs.Float64("lr", 0.01, "Шаг обучения (Adam)")
+	l2 := fs.Float64("l2", 1e-4, "Коэффициент L2-регуляризации")
+	balanced := fs.Bool("balanced", false, "Уравновесить классы весами, обратно пропорциональными их частоте")
+	classWeights := fs.String("class-weights", "", "Явные веса классов, например toxic=3,neutral=1")
+	validation := fs.Float64("validation", 0.1, "Доля примеров для ранней остановки (0 — без валидации)")
+	patience := fs.Int("patience", 10, "Эпох без улучшения на валидации до остановки")
+	seed := fs.Int64("seed", 1, "Зерно генератора случайных чисел")
+	folds := fs.Int("folds", 0, "Число частей стратифицированной перекрёстной проверки (0 — без проверки)")
+	report := fs.String("report", "data/classifier_eval.json", "Файл отчёта перекрёстной проверки в формате JSON")
+	bins := fs.Int("bins", 10, "Число интервалов уверенности для кривой калибровки")
+	fs.Parse(args)
+
+	weighting, err := docvec.ParseWeighting(*weightingName)
+	if err != nil {
+		return err
+	}
+	examples, err := classify.LoadExamples(*input, textprocessor.Columns{Text: *textColumn, Label: *labelColumn})
+	if err != nil {
+		return err
+	}
+	model, err := vectors.Load(*vectorsFile)
+	if err != nil {
+		return err
+	}
+
+	embedding := classify.Embedding{VectorsFile: *vectorsFile, Weighting: weighting, A: docvec.DefaultOptions().A}
+	if weighting != docvec.Mean {
+		embedding.VocabFile = *vocabFile
+	}
+	opts := classify.Options{
+		Epochs:       *epochs,
+		BatchSize:    *batch,
+		LearningRate: *lr,
+		L2:           *l2,
+		Balanced:     *balanced,
+		Validation:   *validation,
+		Patience:     *patience,
+		Seed:         *seed,
+	}
+	weights, err := parseClassWeights(*classWeights)
+	if err != nil {
+		return err
+	}
+	if weights != nil {
+		labels := classify.Labels(examples)
+		opts.ClassWeights = make([]float64, len(labels))
+		for i, label := range labels {
+			opts.ClassWeights[i] = 1
+			if w, ok := weights[label]; ok {
+				opts.ClassWeights[i] = w
+			}
+		}
+	}
+
+	clf, ds, err := classify.TrainText(model, examples, embedding, *removePC, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Примеров: %s\n", ds.Coverage.String())
+	counts := make([]int, len(ds.Labels))
+	for _, y := range ds.Y {
+		counts[y]++
+	}
+	for i, label := range ds.Labels {
+		fmt.Printf("  %s: %d\n", label, counts[i])
+	}
+
+	history := clf.History
+	last := history.Epochs[len(history.Epochs)-1]
+	best := history.Epochs[history.Best-1]
+	if *validation > 0 {
+		if history.Stopped {
+			log.Printf("Ранняя остановка на эпохе %d", last.Epoch)
+		}
+		fmt.Printf("Лучшая эпоха %d: потери %.4f, на валидации %.4f, точность на валидации %.4f\n",
+			best.Epoch, best.TrainLoss, best.ValLoss, best.ValAccuracy)
+	} else {
+		fmt.Printf("Эпох: %d, потери %.4f\n", last.Epoch, last.TrainLoss)
+	}
+
+	if err := clf.Save(*output); err != nil {
+		return err
+	}
+	fmt.Printf("Классификатор сохранён в %s\n", *output)
+
+	if *folds > 0 {
+		// Векторы документов (и главная компонента) общие для всех частей: разметка при их построении не используется
+		r, err := classify.CrossValidate(ds.X, ds.Y, ds.Labels, *folds, opts, *bins)
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		r.Print(os.Stdout)
+		if err := r.SaveJSON(*report); err != nil {
+			return err
+		}
+		fmt.Printf("\nОтчёт сохранён в %s\n", *report)
+	}
+	return nil
+}
+
+// parseClassWeights разбирает веса классов вида "метка=вес,метка=вес"
+func parseClassWeights(value string) (map[string]float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	weights := make(map[string]float64)
+	for _, entry := range parseList(value) {
+		label, w, ok := strings.Cut(entry, "=")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(w), 64)
+		if !ok || err != nil || weight < 0 {
+			return nil, fmt.Errorf("некорректный вес класса %q (ожидается метка=вес)", entry)
+		}
+		weights[strings.TrimSpace(label)] = weight
+	}
+	return weights, nil
+}